@@ -0,0 +1,237 @@
+//go:build android || ios || mobile_skel
+
+// Package conntrack — таблица активных потоков (SOCKS / TUN) с per-flow
+// статистикой и возможностью принудительно закрыть поток.
+//
+// Инбаунды оборачивают исходящий net.Conn через Track(...) (UDP-сессии —
+// net.PacketConn через TrackPacket): обёртка считает байты и удаляет себя
+// из таблицы при Close(). CloseConnection/CloseAll закрывают исходящий
+// сокет — инбаунд видит ошибку и рвёт свою сторону.
+package conntrack
+
+import (
+	"encoding/json"
+	"io"
+	"net"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Meta — неизменяемые сведения о потоке, известные на момент dial.
+type Meta struct {
+	Inbound     string // "socks" | "tun" | ...
+	Network     string // "tcp" | "udp"
+	Source      string // host:port
+	Destination string // host:port
+	Domain      string // домен, если известен
+	Owner       string // приложение-владелец (split tunneling)
+	Rule        string // сработавшее правило роутера
+	Outbound    string // proxy | direct | block
+}
+
+// Info — снимок потока для JSON (debug-экран приложения).
+type Info struct {
+	ID          string `json:"id"`
+	Inbound     string `json:"inbound"`
+	Network     string `json:"network"`
+	Source      string `json:"source"`
+	Destination string `json:"destination"`
+	Domain      string `json:"domain,omitempty"`
+	Owner       string `json:"owner,omitempty"`
+	Rule        string `json:"rule,omitempty"`
+	Outbound    string `json:"outbound"`
+	StartUnix   int64  `json:"start"`
+	Upload      uint64 `json:"upload"`
+	Download    uint64 `json:"download"`
+}
+
+// flow — запись таблицы: общая часть Conn и PacketConn. Upload —
+// записанное в удалённую сторону, Download — прочитанное из неё.
+type flow struct {
+	id    string
+	meta  Meta
+	start time.Time
+	up    atomic.Uint64
+	down  atomic.Uint64
+	once  sync.Once
+	sock  io.Closer // исходящий сокет
+}
+
+// Conn — отслеживаемый TCP-поток.
+type Conn struct {
+	net.Conn
+	*flow
+}
+
+// PacketConn — отслеживаемая UDP-сессия: WriteTo — upload, ReadFrom — download.
+type PacketConn struct {
+	net.PacketConn
+	*flow
+}
+
+var (
+	mu      sync.Mutex
+	conns   = map[string]*flow{}
+	nextID  atomic.Uint64
+	closedN atomic.Uint64
+)
+
+func register(m Meta, sock io.Closer) *flow {
+	f := &flow{
+		id:    strconv.FormatUint(nextID.Add(1), 10),
+		meta:  m,
+		start: time.Now(),
+		sock:  sock,
+	}
+	mu.Lock()
+	conns[f.id] = f
+	mu.Unlock()
+	return f
+}
+
+// Track регистрирует c в таблице и возвращает обёртку, которую инбаунд
+// должен использовать вместо c.
+func Track(m Meta, c net.Conn) *Conn {
+	return &Conn{Conn: c, flow: register(m, c)}
+}
+
+// TrackPacket — Track для UDP-сессии.
+func TrackPacket(m Meta, pc net.PacketConn) *PacketConn {
+	return &PacketConn{PacketConn: pc, flow: register(m, pc)}
+}
+
+// ID возвращает идентификатор потока в таблице.
+func (f *flow) ID() string { return f.id }
+
+func (c *Conn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	if n > 0 {
+		c.down.Add(uint64(n))
+	}
+	return n, err
+}
+
+func (c *Conn) Write(p []byte) (int, error) {
+	n, err := c.Conn.Write(p)
+	if n > 0 {
+		c.up.Add(uint64(n))
+	}
+	return n, err
+}
+
+func (c *PacketConn) ReadFrom(p []byte) (int, net.Addr, error) {
+	n, addr, err := c.PacketConn.ReadFrom(p)
+	if n > 0 {
+		c.down.Add(uint64(n))
+	}
+	return n, addr, err
+}
+
+func (c *PacketConn) WriteTo(p []byte, addr net.Addr) (int, error) {
+	n, err := c.PacketConn.WriteTo(p, addr)
+	if n > 0 {
+		c.up.Add(uint64(n))
+	}
+	return n, err
+}
+
+// Close закрывает поток и убирает его из таблицы (идемпотентно).
+func (c *Conn) Close() error { return c.close() }
+
+// Close закрывает сессию и убирает её из таблицы (идемпотентно).
+func (c *PacketConn) Close() error { return c.close() }
+
+func (f *flow) close() error {
+	var err error
+	f.once.Do(func() {
+		mu.Lock()
+		delete(conns, f.id)
+		mu.Unlock()
+		closedN.Add(1)
+		err = f.sock.Close()
+	})
+	return err
+}
+
+func (f *flow) info() Info {
+	return Info{
+		ID:          f.id,
+		Inbound:     f.meta.Inbound,
+		Network:     f.meta.Network,
+		Source:      f.meta.Source,
+		Destination: f.meta.Destination,
+		Domain:      f.meta.Domain,
+		Owner:       f.meta.Owner,
+		Rule:        f.meta.Rule,
+		Outbound:    f.meta.Outbound,
+		StartUnix:   f.start.Unix(),
+		Upload:      f.up.Load(),
+		Download:    f.down.Load(),
+	}
+}
+
+// Snapshot возвращает активные потоки в порядке открытия.
+func Snapshot() []Info {
+	mu.Lock()
+	list := make([]*flow, 0, len(conns))
+	for _, c := range conns {
+		list = append(list, c)
+	}
+	mu.Unlock()
+
+	out := make([]Info, 0, len(list))
+	for _, c := range list {
+		out = append(out, c.info())
+	}
+	sort.Slice(out, func(i, j int) bool {
+		a, _ := strconv.ParseUint(out[i].ID, 10, 64)
+		b, _ := strconv.ParseUint(out[j].ID, 10, 64)
+		return a < b
+	})
+	return out
+}
+
+// SnapshotJSON — Snapshot в виде JSON-массива.
+func SnapshotJSON() string {
+	b, _ := json.Marshal(Snapshot())
+	return string(b)
+}
+
+// Count — число активных потоков.
+func Count() int {
+	mu.Lock()
+	defer mu.Unlock()
+	return len(conns)
+}
+
+// ClosedTotal — сколько потоков было закрыто с момента запуска процесса.
+func ClosedTotal() uint64 { return closedN.Load() }
+
+// CloseConnection закрывает поток id. false — такого потока нет.
+func CloseConnection(id string) bool {
+	mu.Lock()
+	c := conns[id]
+	mu.Unlock()
+	if c == nil {
+		return false
+	}
+	_ = c.close()
+	return true
+}
+
+// CloseAll закрывает все потоки и возвращает их количество.
+func CloseAll() int {
+	mu.Lock()
+	list := make([]*flow, 0, len(conns))
+	for _, c := range conns {
+		list = append(list, c)
+	}
+	mu.Unlock()
+	for _, c := range list {
+		_ = c.close()
+	}
+	return len(list)
+}
@@ -0,0 +1,70 @@
+//go:build mobile_skel
+
+package conntrack
+
+import (
+	"encoding/json"
+	"net"
+	"testing"
+)
+
+func TestTrack_StatsAndClose(t *testing.T) {
+	CloseAll()
+
+	local, remote := net.Pipe()
+	defer remote.Close()
+
+	tc := Track(Meta{Inbound: "socks", Network: "tcp", Destination: "1.1.1.1:443", Outbound: "proxy"}, local)
+	go func() {
+		buf := make([]byte, 4)
+		_, _ = remote.Read(buf)
+		_, _ = remote.Write([]byte("pong!"))
+	}()
+	if _, err := tc.Write([]byte("ping")); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	buf := make([]byte, 5)
+	if _, err := tc.Read(buf); err != nil {
+		t.Fatalf("read: %v", err)
+	}
+
+	var list []Info
+	if err := json.Unmarshal([]byte(SnapshotJSON()), &list); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(list) != 1 || list[0].ID != tc.ID() {
+		t.Fatalf("unexpected snapshot: %#v", list)
+	}
+	if list[0].Upload != 4 || list[0].Download != 5 || list[0].Destination != "1.1.1.1:443" {
+		t.Fatalf("unexpected stats: %+v", list[0])
+	}
+
+	if !CloseConnection(tc.ID()) {
+		t.Fatal("CloseConnection returned false for active flow")
+	}
+	if CloseConnection(tc.ID()) {
+		t.Fatal("CloseConnection must return false for closed flow")
+	}
+	if Count() != 0 {
+		t.Fatalf("expected empty table, got %d", Count())
+	}
+	// исходящий сокет действительно закрыт
+	if _, err := local.Write([]byte("x")); err == nil {
+		t.Fatal("expected write error on closed conn")
+	}
+}
+
+func TestCloseAll(t *testing.T) {
+	CloseAll()
+	for i := 0; i < 3; i++ {
+		a, b := net.Pipe()
+		defer b.Close()
+		Track(Meta{Network: "tcp"}, a)
+	}
+	if n := CloseAll(); n != 3 {
+		t.Fatalf("CloseAll() = %d, want 3", n)
+	}
+	if Count() != 0 {
+		t.Fatalf("expected empty table after CloseAll, got %d", Count())
+	}
+}
@@ -0,0 +1,207 @@
+//go:build android || ios || mobile_skel
+
+// Package forward — локальный проброс портов через активный транспорт
+// (аналог tcpForwarding/udpForwarding клиента Hysteria2): каждое правило
+// слушает локальный адрес и гонит трафик до удалённого адреса через туннель.
+//
+// Пакет не знает о транспорте: рантайм передаёт в Start функции dial/listen
+// (см. runtime.ApplyForwarding) и управляет жизненным циклом.
+package forward
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/telemetry"
+	logpkg "github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/pkg/logging"
+)
+
+var log = logpkg.With(logpkg.CompForward)
+
+// DefaultUDPTimeout — время жизни простаивающей UDP-сессии.
+const DefaultUDPTimeout = 60 * time.Second
+
+// ErrNoTunnel — активный транспорт не умеет открывать потоки нужного типа.
+var ErrNoTunnel = errors.New("active transport cannot carry this network")
+
+// Rule — одно правило проброса.
+type Rule struct {
+	Network    string        // tcp | udp
+	Listen     string        // локальный host:port
+	Remote     string        // host:port назначения (резолвится на стороне сервера)
+	UDPTimeout time.Duration // 0 — DefaultUDPTimeout
+}
+
+// Tunnel — как открывать потоки через транспорт. nil-функция — сеть не поддерживается.
+type Tunnel struct {
+	Dial         func(ctx context.Context, network, addr string) (net.Conn, error)
+	ListenPacket func(ctx context.Context) (net.PacketConn, error)
+}
+
+// Stats — счётчики одного правила.
+type Stats struct {
+	Network  string `json:"network"`
+	Listen   string `json:"listen"`
+	Remote   string `json:"remote"`
+	BytesUp  uint64 `json:"bytes_up"`   // локальный клиент → remote
+	BytesDn  uint64 `json:"bytes_down"` // remote → локальный клиент
+	Active   int64  `json:"active"`     // открытые соединения / UDP-сессии
+	Total    uint64 `json:"total"`
+	Failures uint64 `json:"failures"`
+	LastErr  string `json:"last_err,omitempty"`
+}
+
+type forwarder struct {
+	rule   Rule
+	tunnel Tunnel
+	ln     net.Listener
+	pc     net.PacketConn
+
+	up, dn, total, failures atomic.Uint64
+	active                  atomic.Int64
+	lastErr                 atomic.Value // string
+}
+
+var (
+	mu     sync.Mutex
+	fwds   []*forwarder
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+)
+
+// Start останавливает текущие пробросы и поднимает rules. Если хоть один
+// адрес не удалось занять или туннель не несёт сеть правила (nil-функция
+// в t, ErrNoTunnel) — всё уже поднятое останавливается и возвращается ошибка.
+func Start(rules []Rule, t Tunnel) error {
+	Stop()
+	for _, r := range rules {
+		if (r.Network == "tcp" && t.Dial == nil) || (r.Network == "udp" && t.ListenPacket == nil) {
+			return fmt.Errorf("forward %s %s: %w", r.Network, r.Listen, ErrNoTunnel)
+		}
+	}
+	mu.Lock()
+	defer mu.Unlock()
+
+	ctx, cf := context.WithCancel(context.Background())
+	started := make([]*forwarder, 0, len(rules))
+	for _, r := range rules {
+		f := &forwarder{rule: r, tunnel: t}
+		if err := f.listen(); err != nil {
+			cf()
+			for _, s := range started {
+				s.close()
+			}
+			return fmt.Errorf("forward %s %s: %w", r.Network, r.Listen, err)
+		}
+		started = append(started, f)
+	}
+	for _, f := range started {
+		wg.Add(1)
+		if f.ln != nil {
+			go f.serveTCP(ctx)
+		} else {
+			go f.serveUDP(ctx)
+		}
+		log.Info("forward started", logpkg.F("network", f.rule.Network),
+			logpkg.F("listen", f.addr()), logpkg.F("remote", f.rule.Remote))
+	}
+	fwds, cancel = started, cf
+	return nil
+}
+
+// Stop закрывает все пробросы и ждёт завершения их соединений.
+func Stop() {
+	mu.Lock()
+	if cancel != nil {
+		cancel()
+	}
+	for _, f := range fwds {
+		f.close()
+	}
+	fwds, cancel = nil, nil
+	mu.Unlock()
+	wg.Wait()
+}
+
+// Snapshot — счётчики всех активных правил (в порядке конфига).
+func Snapshot() []Stats {
+	mu.Lock()
+	defer mu.Unlock()
+	out := make([]Stats, 0, len(fwds))
+	for _, f := range fwds {
+		s := Stats{
+			Network:  f.rule.Network,
+			Listen:   f.addr(),
+			Remote:   f.rule.Remote,
+			BytesUp:  f.up.Load(),
+			BytesDn:  f.dn.Load(),
+			Active:   f.active.Load(),
+			Total:    f.total.Load(),
+			Failures: f.failures.Load(),
+		}
+		s.LastErr, _ = f.lastErr.Load().(string)
+		out = append(out, s)
+	}
+	return out
+}
+
+// StatsJSON — Snapshot в JSON (массив).
+func StatsJSON() string {
+	b, _ := json.Marshal(Snapshot())
+	return string(b)
+}
+
+func (f *forwarder) listen() (err error) {
+	switch f.rule.Network {
+	case "tcp":
+		f.ln, err = net.Listen("tcp", f.rule.Listen)
+	case "udp":
+		f.pc, err = net.ListenPacket("udp", f.rule.Listen)
+	default:
+		err = fmt.Errorf("unknown network %q", f.rule.Network)
+	}
+	return err
+}
+
+// addr — фактический локальный адрес (важно для порта 0).
+func (f *forwarder) addr() string {
+	if f.ln != nil {
+		return f.ln.Addr().String()
+	}
+	if f.pc != nil {
+		return f.pc.LocalAddr().String()
+	}
+	return f.rule.Listen
+}
+
+func (f *forwarder) close() {
+	if f.ln != nil {
+		_ = f.ln.Close()
+	}
+	if f.pc != nil {
+		_ = f.pc.Close()
+	}
+}
+
+func (f *forwarder) fail(err error) {
+	f.failures.Add(1)
+	f.lastErr.Store(err.Error())
+	log.Warn("forward dial failed", logpkg.F("remote", f.rule.Remote), logpkg.F("err", err))
+}
+
+// countUp/countDn — per-forward и общие счётчики трафика ядра.
+func (f *forwarder) countUp(n int) {
+	f.up.Add(uint64(n))
+	telemetry.BytesOut.Add(uint64(n))
+}
+
+func (f *forwarder) countDn(n int) {
+	f.dn.Add(uint64(n))
+	telemetry.BytesIn.Add(uint64(n))
+}
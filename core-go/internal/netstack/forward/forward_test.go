@@ -0,0 +1,201 @@
+//go:build mobile_skel
+
+package forward
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+// directTunnel — «туннель» напрямую, для локальных тестов.
+var directTunnel = Tunnel{
+	Dial: func(ctx context.Context, network, addr string) (net.Conn, error) {
+		var d net.Dialer
+		return d.DialContext(ctx, network, addr)
+	},
+	ListenPacket: func(ctx context.Context) (net.PacketConn, error) {
+		return net.ListenPacket("udp", "127.0.0.1:0")
+	},
+}
+
+func TestForward_TCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() { defer c.Close(); io.Copy(c, c) }()
+		}
+	}()
+
+	if err := Start([]Rule{{Network: "tcp", Listen: "127.0.0.1:0", Remote: ln.Addr().String()}}, directTunnel); err != nil {
+		t.Fatal(err)
+	}
+	defer Stop()
+
+	c, err := net.Dial("tcp", Snapshot()[0].Listen)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.SetDeadline(time.Now().Add(2 * time.Second))
+	c.Write([]byte("hello"))
+	buf := make([]byte, 5)
+	if _, err := io.ReadFull(c, buf); err != nil || string(buf) != "hello" {
+		t.Fatalf("echo: %q %v", buf, err)
+	}
+	c.Close()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for Snapshot()[0].Active != 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	s := Snapshot()[0]
+	if s.BytesUp != 5 || s.BytesDn != 5 || s.Total != 1 || s.Active != 0 {
+		t.Fatalf("unexpected stats: %+v", s)
+	}
+}
+
+func TestForward_UDP(t *testing.T) {
+	echo, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer echo.Close()
+	go func() {
+		b := make([]byte, 2048)
+		for {
+			n, from, err := echo.ReadFrom(b)
+			if err != nil {
+				return
+			}
+			echo.WriteTo(b[:n], from)
+		}
+	}()
+
+	if err := Start([]Rule{{Network: "udp", Listen: "127.0.0.1:0", Remote: echo.LocalAddr().String()}}, directTunnel); err != nil {
+		t.Fatal(err)
+	}
+	defer Stop()
+
+	c, err := net.Dial("udp", Snapshot()[0].Listen)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+	c.SetDeadline(time.Now().Add(2 * time.Second))
+	c.Write([]byte("dns?"))
+	buf := make([]byte, 16)
+	n, err := c.Read(buf)
+	if err != nil || string(buf[:n]) != "dns?" {
+		t.Fatalf("udp echo: %q %v", buf[:n], err)
+	}
+	if s := Snapshot()[0]; s.BytesUp != 4 || s.BytesDn != 4 || s.Active != 1 {
+		t.Fatalf("unexpected stats: %+v", s)
+	}
+}
+
+func TestForward_NoTunnelAndBindError(t *testing.T) {
+	// Транспорт без нужной сети — ошибка сразу в Start, а не на каждом соединении.
+	err := Start([]Rule{{Network: "udp", Listen: "127.0.0.1:0", Remote: "10.0.0.1:53"}}, Tunnel{Dial: directTunnel.Dial})
+	if !errors.Is(err, ErrNoTunnel) || len(Snapshot()) != 0 {
+		t.Fatalf("Start with a tunnel that cannot carry udp: %v, %d forwards", err, len(Snapshot()))
+	}
+
+	failing := Tunnel{Dial: func(context.Context, string, string) (net.Conn, error) {
+		return nil, errors.New("tunnel down")
+	}}
+	if err := Start([]Rule{{Network: "tcp", Listen: "127.0.0.1:0", Remote: "10.0.0.1:22"}}, failing); err != nil {
+		t.Fatal(err)
+	}
+	c, err := net.Dial("tcp", Snapshot()[0].Listen)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if _, err := c.Read(make([]byte, 1)); err == nil {
+		t.Fatal("connection must be closed when the tunnel dial fails")
+	}
+	c.Close()
+	if s := Snapshot()[0]; s.Failures != 1 || s.LastErr == "" {
+		t.Fatalf("failure not counted: %+v", s)
+	}
+	busy := Snapshot()[0].Listen
+
+	// Второй Start останавливает первый; занятый порт валит весь набор правил.
+	hold, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer hold.Close()
+	err = Start([]Rule{
+		{Network: "tcp", Listen: busy, Remote: "10.0.0.1:22"},
+		{Network: "tcp", Listen: hold.Addr().String(), Remote: "10.0.0.1:22"},
+	}, directTunnel)
+	if err == nil {
+		t.Fatal("Start must fail when a listen address is busy")
+	}
+	if len(Snapshot()) != 0 {
+		t.Fatal("partial start must be rolled back")
+	}
+	if l, err := net.Listen("tcp", busy); err != nil {
+		t.Fatalf("rolled back listener still holds %s: %v", busy, err)
+	} else {
+		l.Close()
+	}
+}
+
+// Сессия, в которой пишет только клиент, не должна истекать по UDPTimeout.
+func TestForward_UDPClientTrafficKeepsSession(t *testing.T) {
+	sink, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Close()
+	sources := make(chan string, 64)
+	go func() {
+		b := make([]byte, 2048)
+		for {
+			_, from, err := sink.ReadFrom(b)
+			if err != nil {
+				return
+			}
+			sources <- from.String()
+		}
+	}()
+
+	timeout := 200 * time.Millisecond
+	if err := Start([]Rule{{Network: "udp", Listen: "127.0.0.1:0", Remote: sink.LocalAddr().String(), UDPTimeout: timeout}}, directTunnel); err != nil {
+		t.Fatal(err)
+	}
+	defer Stop()
+	c, err := net.Dial("udp", Snapshot()[0].Listen)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	seen := map[string]bool{}
+	for i := 0; i < 8; i++ { // 8 × 80 мс — заметно дольше timeout
+		c.Write([]byte("up"))
+		select {
+		case src := <-sources:
+			seen[src] = true
+		case <-time.After(time.Second):
+			t.Fatal("datagram not forwarded")
+		}
+		time.Sleep(timeout * 2 / 5)
+	}
+	if s := Snapshot()[0]; len(seen) != 1 || s.Total != 1 || s.Active != 1 {
+		t.Fatalf("session was recreated while the client was active: sources=%v stats=%+v", seen, s)
+	}
+}
@@ -0,0 +1,84 @@
+//go:build android || ios || mobile_skel
+
+package forward
+
+import (
+	"context"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+const dialTimeout = 15 * time.Second
+
+func (f *forwarder) serveTCP(ctx context.Context) {
+	defer wg.Done()
+	var conns sync.WaitGroup
+	defer conns.Wait()
+	for {
+		c, err := f.ln.Accept()
+		if err != nil {
+			return
+		}
+		conns.Add(1)
+		go func() {
+			defer conns.Done()
+			f.handleTCP(ctx, c)
+		}()
+	}
+}
+
+func (f *forwarder) handleTCP(ctx context.Context, c net.Conn) {
+	defer c.Close()
+	f.total.Add(1)
+	dctx, cancel := context.WithTimeout(ctx, dialTimeout)
+	rc, err := f.tunnel.Dial(dctx, "tcp", f.rule.Remote)
+	cancel()
+	if err != nil {
+		f.fail(err)
+		return
+	}
+	defer rc.Close()
+	f.active.Add(1)
+	defer f.active.Add(-1)
+
+	// Stop() закрывает листенер; активные соединения рвём через ctx.
+	stop := context.AfterFunc(ctx, func() { c.Close(); rc.Close() })
+	defer stop()
+
+	done := make(chan struct{})
+	go func() {
+		f.pipe(rc, c, f.countUp)
+		closeWrite(rc)
+		close(done)
+	}()
+	f.pipe(c, rc, f.countDn)
+	closeWrite(c)
+	<-done
+}
+
+// pipe копирует src → dst, учитывая байты через count.
+func (f *forwarder) pipe(dst io.Writer, src io.Reader, count func(int)) {
+	buf := make([]byte, 32*1024)
+	for {
+		n, err := src.Read(buf)
+		if n > 0 {
+			if _, werr := dst.Write(buf[:n]); werr != nil {
+				return
+			}
+			count(n)
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+func closeWrite(c net.Conn) {
+	if cw, ok := c.(interface{ CloseWrite() error }); ok {
+		_ = cw.CloseWrite()
+		return
+	}
+	_ = c.Close()
+}
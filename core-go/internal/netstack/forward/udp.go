@@ -0,0 +1,127 @@
+//go:build android || ios || mobile_skel
+
+package forward
+
+import (
+	"context"
+	"net"
+	"net/netip"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// udpSession — UDP-«соединение» одного локального клиента через туннель.
+// seen — время последнего пакета в любую сторону (UnixNano): сессия
+// простаивает, только если молчат и клиент, и remote.
+type udpSession struct {
+	pc   net.PacketConn
+	seen atomic.Int64
+}
+
+func (s *udpSession) touch() { s.seen.Store(time.Now().UnixNano()) }
+
+// remoteAddr — адрес назначения как есть (домен резолвит сервер).
+type remoteAddr string
+
+func (a remoteAddr) Network() string { return "udp" }
+func (a remoteAddr) String() string  { return string(a) }
+
+func (f *forwarder) serveUDP(ctx context.Context) {
+	defer wg.Done()
+	timeout := f.rule.UDPTimeout
+	if timeout <= 0 {
+		timeout = DefaultUDPTimeout
+	}
+	var (
+		smu      sync.Mutex
+		sessions = map[string]*udpSession{}
+		readers  sync.WaitGroup
+	)
+	defer func() {
+		smu.Lock()
+		for _, s := range sessions {
+			_ = s.pc.Close()
+		}
+		smu.Unlock()
+		readers.Wait()
+	}()
+
+	var dst net.Addr = remoteAddr(f.rule.Remote)
+	if ap, err := netip.ParseAddrPort(f.rule.Remote); err == nil {
+		dst = net.UDPAddrFromAddrPort(ap)
+	}
+
+	buf := make([]byte, 64*1024)
+	for {
+		n, client, err := f.pc.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		key := client.String()
+		smu.Lock()
+		s := sessions[key]
+		smu.Unlock()
+		if s == nil {
+			s, err = f.openUDP(ctx)
+			if err != nil {
+				f.fail(err)
+				continue
+			}
+			smu.Lock()
+			sessions[key] = s
+			smu.Unlock()
+			readers.Add(1)
+			go func() {
+				defer readers.Done()
+				f.relayBack(s, client, timeout)
+				smu.Lock()
+				delete(sessions, key)
+				smu.Unlock()
+			}()
+		}
+		if _, err := s.pc.WriteTo(buf[:n], dst); err == nil {
+			s.touch()
+			f.countUp(n)
+		}
+	}
+}
+
+func (f *forwarder) openUDP(ctx context.Context) (*udpSession, error) {
+	f.total.Add(1)
+	pc, err := f.tunnel.ListenPacket(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s := &udpSession{pc: pc}
+	s.touch()
+	return s, nil
+}
+
+// relayBack гонит ответы remote → локальный клиент, пока сессия не простоит
+// timeout в обе стороны.
+func (f *forwarder) relayBack(s *udpSession, client net.Addr, timeout time.Duration) {
+	f.active.Add(1)
+	defer f.active.Add(-1)
+	defer s.pc.Close()
+	buf := make([]byte, 64*1024)
+	for {
+		idle := time.Until(time.Unix(0, s.seen.Load()).Add(timeout))
+		if idle <= 0 {
+			return
+		}
+		_ = s.pc.SetReadDeadline(time.Now().Add(idle))
+		n, _, err := s.pc.ReadFrom(buf)
+		if err != nil {
+			if ne, ok := err.(net.Error); ok && ne.Timeout() {
+				continue // клиент мог писать, пока remote молчал
+			}
+			return
+		}
+		s.touch()
+		if _, err := f.pc.WriteTo(buf[:n], client); err != nil {
+			return
+		}
+		f.countDn(n)
+	}
+}
@@ -8,6 +8,8 @@ import (
 	logpkg "github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/pkg/logging"
 )
 
+var log = logpkg.With(logpkg.CompProtect)
+
 var netHooks struct {
 	mu      sync.RWMutex
 	protect func(fd int) bool
@@ -19,7 +21,7 @@ func SetProtectHook(fn func(fd int) bool) {
 	netHooks.mu.Lock()
 	defer netHooks.mu.Unlock()
 	netHooks.protect = fn
-	logpkg.Info("Protect hook registered")
+	log.Info("protect hook registered")
 }
 
 // 🔄 Backward-compat shim для старых тестов / API
@@ -34,7 +36,7 @@ func SetNetHooks(h interface{}) {
 	default:
 		netHooks.protect = nil
 	}
-	logpkg.Info("Legacy SetNetHooks() adapter called")
+	log.Info("legacy SetNetHooks() adapter called")
 }
 
 // protectFD — универсальная обёртка, вызываемая из ядра.
@@ -47,7 +49,7 @@ func ProtectFD(fd int) bool {
 	}
 	ok := netHooks.protect(fd)
 	if !ok {
-		logpkg.Warn("protectFD failed")
+		log.Warn("protectFD failed", logpkg.F("fd", fd))
 	}
 	return ok
 }
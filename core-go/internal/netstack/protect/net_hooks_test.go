@@ -18,6 +18,16 @@ func (f *fakeHooks) Protect(fd int) bool {
 	f.last = fd
 	return f.ok
 }
+
+// сброс protect/owner хуков между тестами
+func resetState() {
+	SetProtectHook(nil)
+	SetOwnerLookupHook(nil)
+}
+
+// старое имя из пакета mobile
+func protectFD(fd int) bool { return ProtectFD(fd) }
+
 func TestNetHooks_SetAndProtect(t *testing.T) {
 	resetState()
 
@@ -0,0 +1,47 @@
+//go:build android || ios || mobile_skel
+
+package protect
+
+import "sync"
+
+// OwnerResolver — платформенный резолвер владельца потока.
+// Android: ConnectivityManager.getConnectionOwnerUid(...) → packageName.
+// iOS: NEAppRule / NEFlowMetaData → bundle id.
+// Пустая строка означает «владелец неизвестен».
+type OwnerResolver interface {
+	FindOwner(network, srcIP string, srcPort int, dstIP string, dstPort int) string
+}
+
+var ownerHooks struct {
+	mu     sync.RWMutex
+	lookup func(network, srcIP string, srcPort int, dstIP string, dstPort int) string
+}
+
+// SetOwnerLookupHook регистрирует функцию поиска владельца потока по 5-tuple.
+// nil снимает хук (правила по package_name/bundle_id перестают совпадать).
+func SetOwnerLookupHook(fn func(network, srcIP string, srcPort int, dstIP string, dstPort int) string) {
+	ownerHooks.mu.Lock()
+	defer ownerHooks.mu.Unlock()
+	ownerHooks.lookup = fn
+	log.Info("owner lookup hook registered")
+}
+
+// SetOwnerResolver — вариант для gomobile: принимает объект, а не функцию.
+func SetOwnerResolver(r OwnerResolver) {
+	if r == nil {
+		SetOwnerLookupHook(nil)
+		return
+	}
+	SetOwnerLookupHook(r.FindOwner)
+}
+
+// LookupOwner возвращает идентификатор приложения для потока
+// или "" если хук не установлен.
+func LookupOwner(network, srcIP string, srcPort int, dstIP string, dstPort int) string {
+	ownerHooks.mu.RLock()
+	defer ownerHooks.mu.RUnlock()
+	if ownerHooks.lookup == nil {
+		return ""
+	}
+	return ownerHooks.lookup(network, srcIP, srcPort, dstIP, dstPort)
+}
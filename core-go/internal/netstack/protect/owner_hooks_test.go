@@ -0,0 +1,34 @@
+//go:build mobile_skel
+
+package protect
+
+import "testing"
+
+type fakeResolver struct{ owner string }
+
+func (f *fakeResolver) FindOwner(network, srcIP string, srcPort int, dstIP string, dstPort int) string {
+	if network == "tcp" && dstPort == 443 {
+		return f.owner
+	}
+	return ""
+}
+
+func TestOwnerLookup_NotSet(t *testing.T) {
+	resetState()
+	if got := LookupOwner("tcp", "10.0.0.2", 40000, "1.1.1.1", 443); got != "" {
+		t.Fatalf("expected empty owner without hook, got %q", got)
+	}
+}
+
+func TestOwnerLookup_Resolver(t *testing.T) {
+	resetState()
+	defer resetState()
+	SetOwnerResolver(&fakeResolver{owner: "org.example.app"})
+
+	if got := LookupOwner("tcp", "10.0.0.2", 40000, "1.1.1.1", 443); got != "org.example.app" {
+		t.Fatalf("LookupOwner = %q, want org.example.app", got)
+	}
+	if got := LookupOwner("udp", "10.0.0.2", 40000, "1.1.1.1", 53); got != "" {
+		t.Fatalf("LookupOwner(udp) = %q, want empty", got)
+	}
+}
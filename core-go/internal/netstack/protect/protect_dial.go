@@ -1,21 +1,31 @@
-//go:build android || ios
+//go:build android || ios || mobile_skel
 
 package protect
 
 import (
 	"context"
-	"errors"
 	"net"
 	"syscall"
+
+	ers "github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/pkg/errors"
 )
 
+// errProtect — VpnService.protect(fd) отказал (или хук не установлен):
+// сокет пошёл бы в собственный туннель, поэтому dial/listen отменяется.
+var errProtect = ers.New(ers.ErrProtectFailed, ers.StageProtect, "VpnService.protect returned false")
+
 func ProtectedPacketConn(ctx context.Context) (net.PacketConn, error) {
+	return ProtectedListenPacket(ctx, "0.0.0.0:0")
+}
+
+// ProtectedListenPacket — защищённый UDP-сокет на заданном адресе (host:port).
+func ProtectedListenPacket(ctx context.Context, addr string) (net.PacketConn, error) {
 	lc := net.ListenConfig{
 		Control: func(network, address string, c syscall.RawConn) error {
 			var ctrlErr error
 			if err := c.Control(func(fd uintptr) {
 				if !ProtectFD(int(fd)) {
-					ctrlErr = errors.New("protect failed")
+					ctrlErr = errProtect
 				}
 			}); err != nil {
 				return err
@@ -23,7 +33,7 @@ func ProtectedPacketConn(ctx context.Context) (net.PacketConn, error) {
 			return ctrlErr
 		},
 	}
-	return lc.ListenPacket(ctx, "udp", "0.0.0.0:0")
+	return lc.ListenPacket(ctx, "udp", addr)
 }
 
 func ProtectedTCPDialer() *net.Dialer {
@@ -32,7 +42,7 @@ func ProtectedTCPDialer() *net.Dialer {
 			var ctrlErr error
 			if err := c.Control(func(fd uintptr) {
 				if !ProtectFD(int(fd)) {
-					ctrlErr = errors.New("protect failed")
+					ctrlErr = errProtect
 				}
 			}); err != nil {
 				return err
@@ -0,0 +1,255 @@
+//go:build android || ios || mobile_skel
+
+package socks
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/routing"
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/runtime"
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/telemetry"
+	logpkg "github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/pkg/logging"
+)
+
+// Локальный HTTP-прокси: 127.0.0.1:PORT (по умолчанию 8080).
+// Поддерживает CONNECT (HTTPS и любые TCP-туннели) и обычный
+// HTTP-форвардинг по absolute-URI. Dial-путь общий с SOCKS (dialFlow),
+// поэтому роутинг, protect(fd), conntrack и счётчики работают одинаково.
+
+var httpLog = logpkg.With(logpkg.CompHTTP)
+
+var (
+	httpMu      sync.Mutex
+	httpLn      net.Listener
+	httpAddr    = "127.0.0.1:8080"
+	httpRunning bool
+)
+
+// hop-by-hop заголовки, которые прокси не должен пересылать (RFC 7230 §6.1).
+var hopHeaders = []string{
+	"Connection", "Proxy-Connection", "Keep-Alive", "Proxy-Authenticate",
+	"Proxy-Authorization", "Te", "Trailer", "Transfer-Encoding", "Upgrade",
+}
+
+// StartLocalHTTP запускает локальный HTTP-прокси на host:port.
+// Пустая строка = ок, иначе текст ошибки.
+func StartLocalHTTP(host string, port int) string {
+	httpMu.Lock()
+	defer httpMu.Unlock()
+
+	if httpRunning {
+		httpLog.Info("already running", logpkg.F("addr", httpAddr))
+		return ""
+	}
+	if host == "" {
+		host = "127.0.0.1"
+	}
+	if port <= 0 {
+		port = 8080
+	}
+	ln, err := net.Listen("tcp", net.JoinHostPort(host, strconv.Itoa(port)))
+	if err != nil {
+		httpLog.Error("listen failed", logpkg.F("err", err))
+		return "http listen failed: " + err.Error()
+	}
+	httpLn = ln
+	httpAddr = ln.Addr().String()
+	httpRunning = true
+
+	httpLog.Info("listening", logpkg.F("addr", httpAddr))
+	telemetry.Emit("http_started", fmt.Sprintf(`{"addr":%q}`, httpAddr))
+
+	runtime.SafeGoRestart("http.serve", func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				httpLog.Info("serve stopped", logpkg.F("err", err))
+				return
+			}
+			runtime.SafeGoNamed("http.conn", func() { serveHTTPConn(c, bufio.NewReader(c)) })
+		}
+	}, func() bool {
+		httpMu.Lock()
+		defer httpMu.Unlock()
+		return httpLn == ln
+	})
+	return ""
+}
+
+// StopLocalHTTP останавливает локальный HTTP-прокси.
+func StopLocalHTTP() {
+	httpMu.Lock()
+	defer httpMu.Unlock()
+
+	if !httpRunning {
+		return
+	}
+	_ = httpLn.Close()
+	httpLn = nil
+	httpRunning = false
+
+	telemetry.Emit("http_stopped", "{}")
+	httpLog.Info("stopped")
+}
+
+func LocalHTTPAddr() string {
+	httpMu.Lock()
+	defer httpMu.Unlock()
+	return httpAddr
+}
+
+// serveHTTPConn обслуживает одно клиентское соединение. br — reader поверх c
+// (в mixed-режиме в нём уже лежит «подсмотренный» первый байт).
+func serveHTTPConn(c net.Conn, br *bufio.Reader) {
+	defer c.Close()
+
+	var (
+		upstream     net.Conn
+		upstreamHost string
+		upstreamBr   *bufio.Reader
+	)
+	defer func() {
+		if upstream != nil {
+			_ = upstream.Close()
+		}
+	}()
+
+	for {
+		req, err := http.ReadRequest(br)
+		if err != nil {
+			return
+		}
+
+		if !checkProxyAuth(req) {
+			writeProxyAuthRequired(c)
+			return
+		}
+		if req.Method == http.MethodConnect {
+			handleHTTPConnect(c, br, req)
+			return
+		}
+
+		if !req.URL.IsAbs() || req.URL.Host == "" {
+			writeHTTPError(c, http.StatusBadRequest, "absolute URI required")
+			return
+		}
+		target := hostWithPort(req.URL.Host, "80")
+
+		if upstream == nil || upstreamHost != target {
+			if upstream != nil {
+				_ = upstream.Close()
+			}
+			upstream, err = dialHTTPFlow(c, target)
+			if err != nil {
+				writeDialError(c, err)
+				return
+			}
+			upstreamHost = target
+			upstreamBr = bufio.NewReader(upstream)
+		}
+
+		for _, h := range hopHeaders {
+			req.Header.Del(h)
+		}
+		req.RequestURI = ""
+		if err := req.Write(upstream); err != nil {
+			writeHTTPError(c, http.StatusBadGateway, err.Error())
+			return
+		}
+		resp, err := http.ReadResponse(upstreamBr, req)
+		if err != nil {
+			writeHTTPError(c, http.StatusBadGateway, err.Error())
+			return
+		}
+		for _, h := range hopHeaders {
+			resp.Header.Del(h)
+		}
+		err = resp.Write(c)
+		_ = resp.Body.Close()
+		if err != nil || req.Close || resp.Close {
+			return
+		}
+	}
+}
+
+func handleHTTPConnect(c net.Conn, br *bufio.Reader, req *http.Request) {
+	target := hostWithPort(req.Host, "443")
+	upstream, err := dialHTTPFlow(c, target)
+	if err != nil {
+		writeDialError(c, err)
+		return
+	}
+	defer upstream.Close()
+
+	if _, err := io.WriteString(c, "HTTP/1.1 200 Connection established\r\n\r\n"); err != nil {
+		return
+	}
+
+	errCh := make(chan error, 2)
+	go func() {
+		// br может содержать данные, пришедшие вместе с CONNECT
+		_, err := io.Copy(upstream, br)
+		if cw, ok := upstream.(interface{ CloseWrite() error }); ok {
+			_ = cw.CloseWrite()
+		}
+		errCh <- err
+	}()
+	go func() {
+		_, err := io.Copy(c, upstream)
+		errCh <- err
+	}()
+	<-errCh
+}
+
+// errBlocked — поток отклонён правилами маршрутизации.
+var errBlocked = errors.New("blocked by route rules")
+
+// dialHTTPFlow маршрутизирует поток и открывает исходящее соединение
+// через общий dial-путь.
+func dialHTTPFlow(c net.Conn, target string) (net.Conn, error) {
+	m := routing.Metadata{Network: "tcp", Inbound: "http", Source: c.RemoteAddr().String(), Destination: target}
+	if h, _, err := net.SplitHostPort(target); err == nil && net.ParseIP(h) == nil {
+		m.Domain = h
+	}
+	f := newFlow(m)
+	if f.decision.Outbound == routing.OutboundBlock {
+		httpLog.Debug("flow blocked", logpkg.F("owner", f.meta.Owner), logpkg.F("dst", target), logpkg.F("rule", f.decision.Rule))
+		return nil, errBlocked
+	}
+	return dialFlow(context.WithValue(context.Background(), flowKey{}, f), "tcp", target)
+}
+
+func hostWithPort(host, defPort string) string {
+	if _, _, err := net.SplitHostPort(host); err == nil {
+		return host
+	}
+	return net.JoinHostPort(strings.Trim(host, "[]"), defPort)
+}
+
+func writeDialError(c net.Conn, err error) {
+	if err == errBlocked {
+		writeHTTPError(c, http.StatusForbidden, err.Error())
+		return
+	}
+	writeHTTPError(c, http.StatusBadGateway, err.Error())
+}
+
+func writeProxyAuthRequired(w io.Writer) {
+	const msg = "proxy authentication required"
+	fmt.Fprintf(w, "HTTP/1.1 407 Proxy Authentication Required\r\nProxy-Authenticate: Basic realm=\"hy2\"\r\nContent-Length: %d\r\nConnection: close\r\n\r\n%s",
+		len(msg), msg)
+}
+
+func writeHTTPError(w io.Writer, code int, msg string) {
+	fmt.Fprintf(w, "HTTP/1.1 %d %s\r\nContent-Type: text/plain\r\nContent-Length: %d\r\nConnection: close\r\n\r\n%s",
+		code, http.StatusText(code), len(msg), msg)
+}
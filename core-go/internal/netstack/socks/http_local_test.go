@@ -0,0 +1,139 @@
+//go:build mobile_skel
+
+package socks
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/proxy"
+)
+
+// freePort возвращает свободный локальный порт (0 у Start* означает дефолт).
+func freePort(t *testing.T) int {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer ln.Close()
+	return ln.Addr().(*net.TCPAddr).Port
+}
+
+func TestLocalHTTP_Connect(t *testing.T) {
+	echoLn, echoAddr := startEchoServer(t)
+	defer echoLn.Close()
+
+	if err := StartLocalHTTP("127.0.0.1", freePort(t)); err != "" {
+		t.Fatalf("StartLocalHTTP: %v", err)
+	}
+	defer StopLocalHTTP()
+
+	conn, err := net.Dial("tcp", LocalHTTPAddr())
+	if err != nil {
+		t.Fatalf("dial proxy: %v", err)
+	}
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(2 * time.Second))
+
+	fmt.Fprintf(conn, "CONNECT %s HTTP/1.1\r\nHost: %s\r\n\r\n", echoAddr, echoAddr)
+	br := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(br, nil)
+	if err != nil {
+		t.Fatalf("read CONNECT response: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("CONNECT status = %d", resp.StatusCode)
+	}
+
+	msg := "ping-connect\n"
+	if _, err := conn.Write([]byte(msg)); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	line, err := br.ReadString('\n')
+	if err != nil || line != msg {
+		t.Fatalf("echo = %q, %v; want %q", line, err, msg)
+	}
+}
+
+func TestLocalHTTP_PlainForward(t *testing.T) {
+	origin := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Proxy-Connection") != "" {
+			t.Errorf("hop-by-hop header leaked to origin")
+		}
+		io.WriteString(w, "hello "+r.URL.Path)
+	})}
+	oln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("origin listen: %v", err)
+	}
+	go origin.Serve(oln)
+	defer origin.Close()
+
+	if err := StartLocalHTTP("127.0.0.1", freePort(t)); err != "" {
+		t.Fatalf("StartLocalHTTP: %v", err)
+	}
+	defer StopLocalHTTP()
+
+	pu, _ := url.Parse("http://" + LocalHTTPAddr())
+	client := &http.Client{Transport: &http.Transport{Proxy: http.ProxyURL(pu)}, Timeout: 2 * time.Second}
+	for i := 0; i < 2; i++ { // второй запрос идёт по keep-alive
+		req, _ := http.NewRequest(http.MethodGet, "http://"+oln.Addr().String()+"/x", nil)
+		req.Header.Set("Proxy-Connection", "keep-alive")
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatalf("GET via proxy: %v", err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if string(body) != "hello /x" {
+			t.Fatalf("body = %q", body)
+		}
+	}
+}
+
+func TestLocalMixed_SniffsProtocol(t *testing.T) {
+	echoLn, echoAddr := startEchoServer(t)
+	defer echoLn.Close()
+
+	if err := StartLocalMixed("127.0.0.1", freePort(t)); err != "" {
+		t.Fatalf("StartLocalMixed: %v", err)
+	}
+	defer StopLocalMixed()
+
+	// SOCKS5
+	dialer, err := proxy.SOCKS5("tcp", LocalMixedAddr(), nil, proxy.Direct)
+	if err != nil {
+		t.Fatalf("SOCKS5 dialer: %v", err)
+	}
+	sc, err := dialer.Dial("tcp", echoAddr)
+	if err != nil {
+		t.Fatalf("dial via mixed/socks: %v", err)
+	}
+	defer sc.Close()
+	_ = sc.SetDeadline(time.Now().Add(2 * time.Second))
+	sc.Write([]byte("socks\n"))
+	if line, _ := bufio.NewReader(sc).ReadString('\n'); line != "socks\n" {
+		t.Fatalf("socks echo = %q", line)
+	}
+
+	// HTTP CONNECT
+	hc, err := net.Dial("tcp", LocalMixedAddr())
+	if err != nil {
+		t.Fatalf("dial mixed: %v", err)
+	}
+	defer hc.Close()
+	_ = hc.SetDeadline(time.Now().Add(2 * time.Second))
+	fmt.Fprintf(hc, "CONNECT %s HTTP/1.1\r\nHost: %s\r\n\r\n", echoAddr, echoAddr)
+	br := bufio.NewReader(hc)
+	status, _ := br.ReadString('\n')
+	if !strings.Contains(status, "200") {
+		t.Fatalf("CONNECT via mixed: %q", status)
+	}
+}
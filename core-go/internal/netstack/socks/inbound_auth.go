@@ -0,0 +1,100 @@
+//go:build android || ios || mobile_skel
+
+package socks
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"sync"
+
+	logpkg "github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/pkg/logging"
+
+	socks5 "github.com/armon/go-socks5"
+)
+
+// Аутентификация локальных инбаундов.
+// Без неё любой процесс на устройстве может ходить в наш туннель через
+// 127.0.0.1 — на Android это реальный вектор злоупотребления.
+//
+// SOCKS5 — RFC 1929 (username/password), HTTP — Basic в Proxy-Authorization.
+// Креды сверяются с InboundAuth() на каждом подключении, поэтому
+// SetInboundAuth/GenerateInboundAuth действуют и на уже запущенные инбаунды.
+
+var inboundAuth struct {
+	mu   sync.RWMutex
+	user string
+	pass string
+}
+
+// SetInboundAuth задаёт логин/пароль инбаундов. Пустой user отключает auth.
+func SetInboundAuth(user, pass string) {
+	inboundAuth.mu.Lock()
+	defer inboundAuth.mu.Unlock()
+	inboundAuth.user = user
+	inboundAuth.pass = pass
+	logpkg.SetSecrets("inbound", pass)
+}
+
+// GenerateInboundAuth включает auth со случайными per-session кредами
+// и возвращает их (хост-приложение передаёт их в tun-мост).
+func GenerateInboundAuth() (user, pass string) {
+	user = "hy2-" + randomHex(4)
+	pass = randomHex(16)
+	SetInboundAuth(user, pass)
+	return user, pass
+}
+
+// InboundAuth возвращает текущие креды ("" если auth выключен).
+func InboundAuth() (user, pass string) {
+	inboundAuth.mu.RLock()
+	defer inboundAuth.mu.RUnlock()
+	return inboundAuth.user, inboundAuth.pass
+}
+
+// inboundCredentials — CredentialStore go-socks5, читающий текущие креды
+// на каждый запрос (StaticCredentials застыл бы на момент старта сервера).
+type inboundCredentials struct{}
+
+var _ socks5.CredentialStore = inboundCredentials{}
+
+func (inboundCredentials) Valid(user, pass string) bool { return credentialsMatch(user, pass) }
+
+// credentialsMatch сравнивает креды с текущими за постоянное время;
+// при выключенном auth — false.
+func credentialsMatch(u, p string) bool {
+	user, pass := InboundAuth()
+	if user == "" {
+		return false
+	}
+	okUser := subtle.ConstantTimeCompare([]byte(u), []byte(user)) == 1
+	okPass := subtle.ConstantTimeCompare([]byte(p), []byte(pass)) == 1
+	return okUser && okPass
+}
+
+// checkProxyAuth проверяет Proxy-Authorization: Basic ... у HTTP-запроса.
+func checkProxyAuth(req *http.Request) bool {
+	if user, _ := InboundAuth(); user == "" {
+		return true
+	}
+	h := req.Header.Get("Proxy-Authorization")
+	const prefix = "Basic "
+	if len(h) < len(prefix) || !strings.EqualFold(h[:len(prefix)], prefix) {
+		return false
+	}
+	raw, err := base64.StdEncoding.DecodeString(h[len(prefix):])
+	if err != nil {
+		return false
+	}
+	u, p, ok := strings.Cut(string(raw), ":")
+	return ok && credentialsMatch(u, p)
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,130 @@
+//go:build mobile_skel
+
+package socks
+
+import (
+	"bufio"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/proxy"
+)
+
+func TestInboundAuth_Socks(t *testing.T) {
+	echoLn, echoAddr := startEchoServer(t)
+	defer echoLn.Close()
+
+	user, pass := GenerateInboundAuth()
+	defer SetInboundAuth("", "")
+	if !strings.HasPrefix(user, "hy2-") || len(pass) != 32 {
+		t.Fatalf("unexpected generated creds: %q/%q", user, pass)
+	}
+
+	if err := StartLocalMixed("127.0.0.1", freePort(t)); err != "" {
+		t.Fatalf("StartLocalMixed: %v", err)
+	}
+	defer StopLocalMixed()
+
+	noAuth, _ := proxy.SOCKS5("tcp", LocalMixedAddr(), nil, proxy.Direct)
+	if c, err := noAuth.Dial("tcp", echoAddr); err == nil {
+		c.Close()
+		t.Fatal("expected SOCKS dial without auth to fail")
+	}
+
+	bad, _ := proxy.SOCKS5("tcp", LocalMixedAddr(), &proxy.Auth{User: user, Password: "wrong"}, proxy.Direct)
+	if c, err := bad.Dial("tcp", echoAddr); err == nil {
+		c.Close()
+		t.Fatal("expected SOCKS dial with wrong password to fail")
+	}
+
+	good, _ := proxy.SOCKS5("tcp", LocalMixedAddr(), &proxy.Auth{User: user, Password: pass}, proxy.Direct)
+	c, err := good.Dial("tcp", echoAddr)
+	if err != nil {
+		t.Fatalf("SOCKS dial with auth: %v", err)
+	}
+	c.Close()
+}
+
+func TestInboundAuth_HTTP(t *testing.T) {
+	echoLn, echoAddr := startEchoServer(t)
+	defer echoLn.Close()
+
+	SetInboundAuth("alice", "s3cret")
+	defer SetInboundAuth("", "")
+
+	if err := StartLocalHTTP("127.0.0.1", freePort(t)); err != "" {
+		t.Fatalf("StartLocalHTTP: %v", err)
+	}
+	defer StopLocalHTTP()
+
+	connect := func(authHeader string) int {
+		c, err := net.Dial("tcp", LocalHTTPAddr())
+		if err != nil {
+			t.Fatalf("dial proxy: %v", err)
+		}
+		defer c.Close()
+		_ = c.SetDeadline(time.Now().Add(2 * time.Second))
+		fmt.Fprintf(c, "CONNECT %s HTTP/1.1\r\nHost: %s\r\n%s\r\n", echoAddr, echoAddr, authHeader)
+		resp, err := http.ReadResponse(bufio.NewReader(c), nil)
+		if err != nil {
+			t.Fatalf("read response: %v", err)
+		}
+		return resp.StatusCode
+	}
+
+	if code := connect(""); code != http.StatusProxyAuthRequired {
+		t.Fatalf("no auth: status %d, want 407", code)
+	}
+	wrong := base64.StdEncoding.EncodeToString([]byte("alice:nope"))
+	if code := connect("Proxy-Authorization: Basic " + wrong + "\r\n"); code != http.StatusProxyAuthRequired {
+		t.Fatalf("wrong auth: status %d, want 407", code)
+	}
+	ok := base64.StdEncoding.EncodeToString([]byte("alice:s3cret"))
+	if code := connect("Proxy-Authorization: Basic " + ok + "\r\n"); code != http.StatusOK {
+		t.Fatalf("valid auth: status %d, want 200", code)
+	}
+}
+
+// Креды, заданные после старта (Start/Reload ядра), действуют на уже запущенный SOCKS.
+func TestInboundAuth_SocksPicksUpChanges(t *testing.T) {
+	echoLn, echoAddr := startEchoServer(t)
+	defer echoLn.Close()
+
+	SetInboundAuth("", "")
+	if err := StartLocalSocks("127.0.0.1", freePort(t)); err != "" {
+		t.Fatalf("StartLocalSocks: %v", err)
+	}
+	defer StopLocalSocks()
+	dial := func(auth *proxy.Auth) error {
+		d, _ := proxy.SOCKS5("tcp", LocalSocksAddr(), auth, proxy.Direct)
+		c, err := d.Dial("tcp", echoAddr)
+		if err == nil {
+			c.Close()
+		}
+		return err
+	}
+
+	if err := dial(nil); err != nil {
+		t.Fatalf("dial without auth while auth is off: %v", err)
+	}
+	user, pass := GenerateInboundAuth()
+	defer SetInboundAuth("", "")
+	if dial(nil) == nil {
+		t.Fatal("expected dial without auth to fail after GenerateInboundAuth")
+	}
+	if err := dial(&proxy.Auth{User: user, Password: pass}); err != nil {
+		t.Fatalf("dial with generated creds: %v", err)
+	}
+	user2, pass2 := GenerateInboundAuth()
+	if dial(&proxy.Auth{User: user, Password: pass}) == nil {
+		t.Fatal("stale creds must be rejected after regeneration")
+	}
+	if err := dial(&proxy.Auth{User: user2, Password: pass2}); err != nil {
+		t.Fatalf("dial with regenerated creds: %v", err)
+	}
+}
@@ -0,0 +1,118 @@
+//go:build android || ios || mobile_skel
+
+package socks
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/runtime"
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/telemetry"
+	logpkg "github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/pkg/logging"
+)
+
+// Mixed-порт: один listener для SOCKS5 и HTTP-прокси.
+// Протокол определяется по первому байту: 0x05 — SOCKS5, иначе — HTTP.
+
+var (
+	mixedMu      sync.Mutex
+	mixedLn      net.Listener
+	mixedAddr    = "127.0.0.1:2080"
+	mixedRunning bool
+)
+
+// peekedConn отдаёт сначала буферизованные bufio.Reader'ом байты, затем — сокет.
+type peekedConn struct {
+	net.Conn
+	r *bufio.Reader
+}
+
+func (c *peekedConn) Read(p []byte) (int, error) { return c.r.Read(p) }
+
+// StartLocalMixed запускает mixed-инбаунд (SOCKS5 + HTTP) на host:port.
+// Пустая строка = ок, иначе текст ошибки.
+func StartLocalMixed(host string, port int) string {
+	mixedMu.Lock()
+	defer mixedMu.Unlock()
+
+	if mixedRunning {
+		log.Info("mixed proxy already running", logpkg.F("addr", mixedAddr))
+		return ""
+	}
+	if host == "" {
+		host = "127.0.0.1"
+	}
+	if port <= 0 {
+		port = 2080
+	}
+
+	srv, err := newSocksServer()
+	if err != nil {
+		log.Error("mixed init failed", logpkg.F("err", err))
+		return "socks init failed: " + err.Error()
+	}
+	ln, err := net.Listen("tcp", net.JoinHostPort(host, strconv.Itoa(port)))
+	if err != nil {
+		log.Error("mixed listen failed", logpkg.F("err", err))
+		return "mixed listen failed: " + err.Error()
+	}
+	mixedLn = ln
+	mixedAddr = ln.Addr().String()
+	mixedRunning = true
+
+	log.Info("mixed proxy listening", logpkg.F("addr", mixedAddr))
+	telemetry.Emit("mixed_started", fmt.Sprintf(`{"addr":%q}`, mixedAddr))
+
+	runtime.SafeGoRestart("mixed.serve", func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				log.Info("mixed serve stopped", logpkg.F("err", err))
+				return
+			}
+			runtime.SafeGoNamed("mixed.conn", func() {
+				br := bufio.NewReader(c)
+				first, err := br.Peek(1)
+				if err != nil {
+					_ = c.Close()
+					return
+				}
+				if first[0] == 0x05 {
+					_ = srv.ServeConn(&peekedConn{Conn: c, r: br})
+					return
+				}
+				serveHTTPConn(c, br)
+			})
+		}
+	}, func() bool {
+		mixedMu.Lock()
+		defer mixedMu.Unlock()
+		return mixedLn == ln
+	})
+	return ""
+}
+
+// StopLocalMixed останавливает mixed-инбаунд.
+func StopLocalMixed() {
+	mixedMu.Lock()
+	defer mixedMu.Unlock()
+
+	if !mixedRunning {
+		return
+	}
+	_ = mixedLn.Close()
+	mixedLn = nil
+	mixedRunning = false
+
+	telemetry.Emit("mixed_stopped", "{}")
+	log.Info("mixed proxy stopped")
+}
+
+func LocalMixedAddr() string {
+	mixedMu.Lock()
+	defer mixedMu.Unlock()
+	return mixedAddr
+}
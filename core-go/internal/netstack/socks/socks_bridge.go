@@ -11,12 +11,13 @@ import "C"
 import (
 	"fmt"
 	"io"
+	"net"
 	"os"
 	"sync/atomic"
-	"time"
 
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/telemetry"
+	ers "github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/pkg/errors"
 	core "github.com/eycorsican/go-tun2socks/core"
-	"github.com/eycorsican/go-tun2socks/proxy/socks"
 )
 
 var (
@@ -26,6 +27,14 @@ var (
 
 // StartTun2Socks — реальный мост TUN→SOCKS.
 func StartTun2Socks(tunFd int, socksHost string, socksPort int) string {
+	return StartTun2SocksAuth(tunFd, socksHost, socksPort, "", "")
+}
+
+// StartTun2SocksAuth — мост TUN→ядро для инбаунда с RFC 1929 аутентификацией.
+// Потоки TUN (TCP и UDP) обрабатываются в процессе (tun_inbound.go), без
+// хопа через SOCKS, поэтому auth инбаунда на них не влияет; адрес и креды
+// оставлены для совместимости API.
+func StartTun2SocksAuth(tunFd int, socksHost string, socksPort int, user, pass string) string {
 	if t2sRunning.Load() {
 		logI("tun2socks already running")
 		return ""
@@ -35,17 +44,19 @@ func StartTun2Socks(tunFd int, socksHost string, socksPort int) string {
 	logI("starting tun2socks → " + addr)
 
 	// Открываем TUN fd
-	f := os.NewFile(uintptr(tunFd), "tun")
-	if f == nil {
-		return "invalid tun fd"
+	if tunFd < 0 {
+		e := ers.New(ers.ErrTunFdInvalid, ers.StageTun, fmt.Sprintf("fd %d", tunFd))
+		telemetry.EmitErr(e, ers.StageTun)
+		return e.Error()
 	}
+	f := os.NewFile(uintptr(tunFd), "tun")
 	tunFile = f
 
-	// TCP/UDP обработчики (новый API)
-	tcpHandler := socks.NewTCPHandler(socksHost, uint16(socksPort))
-	udpHandler := socks.NewUDPHandler(socksHost, uint16(socksPort), 60*time.Second)
-	core.RegisterTCPConnHandler(tcpHandler)
-	core.RegisterUDPConnHandler(udpHandler)
+	// Потоки обрабатываются в процессе: роутеру нужен исходный адрес
+	// приложения, а через SOCKS-хоп он теряется (и UDP ASSOCIATE инбаунд
+	// не умеет).
+	core.RegisterTCPConnHandler(tunTCPHandler{})
+	core.RegisterUDPConnHandler(tunUDPBridge{newTunUDPHandler(tunUDPTimeout)})
 
 	// Регистрируем выход (из TUN наружу)
 	core.RegisterOutputFn(func(data []byte) (int, error) {
@@ -66,7 +77,10 @@ func StartTun2Socks(tunFd int, socksHost string, socksPort int) string {
 				if err == io.EOF || err.Error() == "file already closed" {
 					break
 				}
-				logE("TUN read error: " + err.Error())
+				// EBADF здесь — fd закрыт/подменён хостом (tun_fd_invalid).
+				e := ers.Classify(err, ers.StageTun)
+				telemetry.EmitErr(e, ers.StageTun)
+				logE("TUN read error: " + e.Error())
 				break
 			}
 			if n > 0 {
@@ -93,3 +107,14 @@ func StopTun2Socks() {
 	emit("tun2socks_stopped", "{}")
 	logI("tun2socks stopped")
 }
+
+// tunUDPBridge приводит tunUDPHandler к core.UDPConnHandler.
+type tunUDPBridge struct{ h *tunUDPHandler }
+
+func (b tunUDPBridge) Connect(conn core.UDPConn, target *net.UDPAddr) error {
+	return b.h.Connect(conn, target)
+}
+
+func (b tunUDPBridge) ReceiveTo(conn core.UDPConn, data []byte, addr *net.UDPAddr) error {
+	return b.h.ReceiveTo(conn, data, addr)
+}
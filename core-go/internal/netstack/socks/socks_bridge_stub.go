@@ -16,6 +16,14 @@ var (
 
 // StartTun2Socks — тестовый stub-раннер: только логи/события/флаг.
 func StartTun2Socks(tunFd int, socksHost string, socksPort int) string {
+	return StartTun2SocksAuth(tunFd, socksHost, socksPort, "", "")
+}
+
+// StartTun2SocksAuth — stub: креды только логируются (без пароля).
+func StartTun2SocksAuth(tunFd int, socksHost string, socksPort int, user, pass string) string {
+	if user != "" {
+		logpkg.LogD("tun2socks SOCKS auth user=" + user)
+	}
 	logpkg.LogI("🧩 STUB version StartTun2Socks() called")
 	if t2sRunning.Load() {
 		logpkg.LogI("tun2socks already running")
@@ -4,13 +4,16 @@ package socks
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net"
 	"strconv"
 	"sync"
 	"time"
 
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/netstack/conntrack"
 	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/netstack/protect"
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/routing"
 	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/runtime"
 	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/telemetry"
 	logpkg "github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/pkg/logging"
@@ -20,23 +23,28 @@ import (
 
 // Локальный SOCKS5-сервер: 127.0.0.1:PORT (по умолчанию 1080).
 // Служит целью для варианта A (TUN -> SOCKS).
-//
-// В проде сюда добавим кастомный Dial с protectFD(fd) для Android VpnService.
+
+var log = logpkg.With(logpkg.CompSocks)
 
 var (
 	socksMu      sync.Mutex
 	socksLn      net.Listener
-	socksSrv     *socks5.Server
+	socksSrv     *socksInbound
 	socksAddr    = "127.0.0.1:1080"
 	socksRunning bool
 )
 
-type countingConn struct{ net.Conn }
+// countingConn считает трафик глобально и (если владелец известен) по приложению.
+type countingConn struct {
+	net.Conn
+	app string
+}
 
 func (c *countingConn) Read(p []byte) (int, error) {
 	n, err := c.Conn.Read(p)
 	if n > 0 {
 		telemetry.BytesIn.Add(uint64(n))
+		telemetry.AddAppBytes(c.app, uint64(n), 0)
 	}
 	return n, err
 }
@@ -44,10 +52,152 @@ func (c *countingConn) Write(p []byte) (int, error) {
 	n, err := c.Conn.Write(p)
 	if n > 0 {
 		telemetry.BytesOut.Add(uint64(n))
+		telemetry.AddAppBytes(c.app, 0, uint64(n))
 	}
 	return n, err
 }
 
+// flowKey — ключ контекста, в котором routeRules передаёт в Dial
+// метаданные потока и решение роутера.
+type flowKey struct{}
+
+type socksFlow struct {
+	meta     routing.Metadata
+	decision routing.Decision
+}
+
+// routeRules — socks5.RuleSet: определяет владельца потока через
+// protect.LookupOwner и применяет правила маршрутизации (block → отказ).
+type routeRules struct{}
+
+func (routeRules) Allow(ctx context.Context, req *socks5.Request) (context.Context, bool) {
+	m := routing.Metadata{Network: "tcp", Inbound: "socks"}
+	if req.RemoteAddr != nil && req.DestAddr != nil {
+		m.Source = req.RemoteAddr.Address()
+		m.Destination = req.DestAddr.Address()
+		m.Domain = req.DestAddr.FQDN
+	}
+	f := newFlow(m)
+	if f.decision.Outbound == routing.OutboundBlock {
+		log.Debug("flow blocked", logpkg.F("owner", f.meta.Owner), logpkg.F("dst", f.meta.Destination), logpkg.F("rule", f.decision.Rule))
+		return ctx, false
+	}
+	return context.WithValue(ctx, flowKey{}, f), true
+}
+
+// newFlow определяет владельца потока (protect.LookupOwner) и спрашивает роутер.
+func newFlow(m routing.Metadata) *socksFlow {
+	if m.Owner == "" {
+		m.Owner = lookupOwner(m.Network, m.Source, m.Destination)
+	}
+	return &socksFlow{meta: m, decision: routing.Match(m)}
+}
+
+func lookupOwner(network, src, dst string) string {
+	sh, sp, err := net.SplitHostPort(src)
+	if err != nil {
+		return ""
+	}
+	dh, dp, err := net.SplitHostPort(dst)
+	if err != nil {
+		return ""
+	}
+	srcPort, _ := strconv.Atoi(sp)
+	dstPort, _ := strconv.Atoi(dp)
+	return protect.LookupOwner(network, sh, srcPort, dh, dstPort)
+}
+
+// dialFlow — общий dial-путь локальных инбаундов (SOCKS, HTTP, mixed, TUN):
+// меряем "rtt", считаем reconnects, открываем поток по решению роутера,
+// заворачиваем в countingConn и регистрируем поток в conntrack. Метаданные
+// потока инбаунд кладёт в ctx под flowKey{}.
+func dialFlow(ctx context.Context, network, addr string) (net.Conn, error) {
+	meta := conntrack.Meta{Inbound: "socks", Network: network, Destination: addr, Outbound: routing.OutboundProxy}
+	if f, ok := ctx.Value(flowKey{}).(*socksFlow); ok {
+		if f.meta.Inbound != "" {
+			meta.Inbound = f.meta.Inbound
+		}
+		meta.Source = f.meta.Source
+		meta.Domain = f.meta.Domain
+		meta.Owner = f.meta.Owner
+		meta.Rule = f.decision.Rule
+		meta.Outbound = f.decision.Outbound
+	}
+
+	start := time.Now()
+	c, err := dialOutbound(ctx, meta.Outbound, network, addr)
+	elapsed := time.Since(start).Milliseconds()
+
+	if err != nil {
+		log.Warn("dial failed", logpkg.F("network", network), logpkg.F("addr", addr), logpkg.F("outbound", meta.Outbound), logpkg.F("err", err))
+		return nil, err
+	}
+
+	telemetry.QuicRttMs.Store(elapsed)
+	telemetry.Reconnects.Add(1)
+	telemetry.DialLatency.Observe(telemetry.MsSince(start))
+	telemetry.AppFlowOpened(meta.Owner)
+	return conntrack.Track(meta, &countingConn{Conn: c, app: meta.Owner}), nil
+}
+
+// dialOutbound открывает поток: direct — защищённым сокетом мимо VPN,
+// proxy — через активный транспорт (runtime.TunnelDial). Пока транспорт
+// TCP не несёт (ядро не запущено, у движка нет data plane), proxy-поток
+// идёт напрямую.
+//
+// Сокет защищается в Control до connect: File()/Fd() переводят общий fd в
+// блокирующий режим, и Close потока (conntrack.CloseConnection) повисает
+// на читающей горутине.
+func dialOutbound(ctx context.Context, outbound, network, addr string) (net.Conn, error) {
+	if outbound != routing.OutboundDirect {
+		c, err := runtime.TunnelDial(ctx, network, addr)
+		if !errors.Is(err, runtime.ErrNoTunnel) {
+			return c, err
+		}
+		log.Debug("transport carries no flows, dialing direct", logpkg.F("addr", addr))
+	}
+	return protect.ProtectedTCPDialer().DialContext(ctx, network, addr)
+}
+
+// socksInbound — SOCKS5-инбаунд. go-socks5 фиксирует методы аутентификации
+// при создании сервера, поэтому серверов два (без auth и с RFC 1929), а
+// нужный выбирается на каждое подключение по текущим InboundAuth().
+type socksInbound struct {
+	open, auth *socks5.Server
+}
+
+// newSocksServer собирает SOCKS5-инбаунд с общим dial-путём и роутером.
+func newSocksServer() (*socksInbound, error) {
+	open, err := socks5.New(&socks5.Config{Dial: dialFlow, Rules: routeRules{}})
+	if err != nil {
+		return nil, err
+	}
+	auth, err := socks5.New(&socks5.Config{Dial: dialFlow, Rules: routeRules{}, Credentials: inboundCredentials{}})
+	if err != nil {
+		return nil, err
+	}
+	return &socksInbound{open: open, auth: auth}, nil
+}
+
+// ServeConn обслуживает одно подключение: с RFC 1929, если auth включён.
+func (s *socksInbound) ServeConn(c net.Conn) error {
+	if user, _ := InboundAuth(); user != "" {
+		return s.auth.ServeConn(c)
+	}
+	return s.open.ServeConn(c)
+}
+
+// Serve принимает подключения до закрытия ln.
+func (s *socksInbound) Serve(ln net.Listener) error {
+	for {
+		c, err := ln.Accept()
+		if err != nil {
+			return err
+		}
+		runtime.SafeGoNamed("socks.conn", func() { _ = s.ServeConn(c) })
+	}
+}
+
 // StartLocalSocks запускает локальный SOCKS5-сервер на host:port.
 // Пустая строка = ок, иначе текст ошибки.
 func StartLocalSocks(host string, port int) string {
@@ -55,7 +205,7 @@ func StartLocalSocks(host string, port int) string {
 	defer socksMu.Unlock()
 
 	if socksRunning {
-		logpkg.LogI("SOCKS already running at " + socksAddr)
+		log.Info("already running", logpkg.F("addr", socksAddr))
 		return ""
 	}
 	if host == "" {
@@ -66,55 +216,32 @@ func StartLocalSocks(host string, port int) string {
 	}
 	socksAddr = net.JoinHostPort(host, strconv.Itoa(port))
 
-	// кастомный Dial: меряем "rtt", считаем reconnects, заворачиваем в countingConn
-	dial := func(ctx context.Context, network, addr string) (net.Conn, error) {
-		start := time.Now()
-		d := &net.Dialer{}
-		c, err := d.DialContext(ctx, network, addr)
-		elapsed := time.Since(start).Milliseconds()
-
-		if err != nil {
-			logpkg.LogW("SOCKS dial fail: " + err.Error())
-			return nil, err
-		}
-
-		// 🔒 добавляем защиту сокета
-		if tcpConn, ok := c.(*net.TCPConn); ok {
-			raw, _ := tcpConn.File()
-			if raw != nil {
-				protect.ProtectFD(int(raw.Fd()))
-				_ = raw.Close() // закрываем временную дубликат-дескриптор
-			}
-		}
-
-		telemetry.QuicRttMs.Store(elapsed)
-		telemetry.Reconnects.Add(1)
-		return &countingConn{Conn: c}, nil
-	}
-
-	conf := &socks5.Config{Dial: dial}
-	srv, err := socks5.New(conf)
+	srv, err := newSocksServer()
 	if err != nil {
-		logpkg.LogE("SOCKS init failed: " + err.Error())
+		log.Error("init failed", logpkg.F("err", err))
 		return "socks init failed: " + err.Error()
 	}
 
 	ln, err := net.Listen("tcp", socksAddr)
 	if err != nil {
-		logpkg.LogE("SOCKS listen failed: " + err.Error())
+		log.Error("listen failed", logpkg.F("err", err))
 		return "socks listen failed: " + err.Error()
 	}
 	socksSrv = srv
 	socksLn = ln
 	socksRunning = true
 
-	logpkg.LogI(fmt.Sprintf("SOCKS listening at %s", socksAddr))
+	log.Info("listening", logpkg.F("addr", socksAddr))
 	telemetry.Emit("socks_started", fmt.Sprintf(`{"addr":%q}`, socksAddr))
 
-	runtime.SafeGo(func() {
+	runtime.SafeGoRestart("socks.serve", func() {
 		if err := srv.Serve(ln); err != nil {
-			logpkg.LogI("SOCKS serve stopped: " + err.Error())
+			log.Info("serve stopped", logpkg.F("err", err))
 		}
+	}, func() bool {
+		socksMu.Lock()
+		defer socksMu.Unlock()
+		return socksLn == ln
 	})
 	return ""
 }
@@ -133,7 +260,7 @@ func StopLocalSocks() {
 	socksRunning = false
 
 	telemetry.Emit("socks_stopped", "{}")
-	logpkg.LogI("SOCKS stopped")
+	log.Info("stopped")
 }
 
 func LocalSocksAddr() string {
@@ -143,14 +270,3 @@ func LocalSocksAddr() string {
 }
 
 func StartLocalSocks1080() string { return StartLocalSocks("127.0.0.1", 1080) }
-
-// (Опционально) заготовка под кастомный Dial с protectFD:
-// func protectDial(ctx context.Context, network, addr string) (net.Conn, error) {
-// 	d := &net.Dialer{}
-// 	c, err := d.DialContext(ctx, network, addr)
-// 	if err != nil {
-// 		return nil, err
-// 	}
-// 	// TODO: попытаться извлечь fd и вызвать protectFD(fd) на Android.
-// 	return c, nil
-// }
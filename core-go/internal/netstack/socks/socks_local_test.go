@@ -10,9 +10,14 @@ import (
 	"testing"
 	"time"
 
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/netstack/conntrack"
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/netstack/protect"
 	"golang.org/x/net/proxy"
 )
 
+// Исходящие сокеты инбаундов защищаются через protect; в тестах хук всегда успешен.
+func init() { protect.SetProtectHook(func(int) bool { return true }) }
+
 func startEchoServer(t *testing.T) (net.Listener, string) {
 	ln, err := net.Listen("tcp", "127.0.0.1:0")
 	if err != nil {
@@ -93,3 +98,48 @@ func TestLocalSocks_EchoRoundTrip_Metrics(t *testing.T) {
 	// RTT может быть 0 на очень быстром локале — не заваливаем, просто читаем
 	_ = h.QuicRttMs
 }
+
+func TestLocalSocks_ConnTrack_Close(t *testing.T) {
+	resetState()
+
+	echoLn, echoAddr := startEchoServer(t)
+	defer echoLn.Close()
+
+	if err := StartLocalSocks("127.0.0.1", 0); err != "" {
+		t.Fatalf("StartLocalSocks: %v", err)
+	}
+	defer StopLocalSocks()
+
+	dialer, err := proxy.SOCKS5("tcp", LocalSocksAddr(), nil, proxy.Direct)
+	if err != nil {
+		t.Fatalf("SOCKS5 dialer: %v", err)
+	}
+	conn, err := dialer.Dial("tcp", echoAddr)
+	if err != nil {
+		t.Fatalf("dial via socks: %v", err)
+	}
+	defer conn.Close()
+
+	var flow *conntrack.Info
+	for _, c := range conntrack.Snapshot() {
+		if c.Inbound == "socks" && c.Destination == echoAddr {
+			c := c
+			flow = &c
+		}
+	}
+	if flow == nil {
+		t.Fatalf("flow to %s not tracked: %s", echoAddr, conntrack.SnapshotJSON())
+	}
+	if flow.Outbound != "proxy" || flow.Rule != "final" {
+		t.Fatalf("unexpected routing info: %+v", flow)
+	}
+
+	if !conntrack.CloseConnection(flow.ID) {
+		t.Fatal("CloseConnection returned false")
+	}
+	// клиентская сторона должна получить EOF/ошибку
+	_ = conn.SetReadDeadline(time.Now().Add(time.Second))
+	if _, err := conn.Read(make([]byte, 1)); err == nil {
+		t.Fatal("expected read error after CloseConnection")
+	}
+}
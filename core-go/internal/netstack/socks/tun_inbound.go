@@ -0,0 +1,205 @@
+//go:build android || ios || mobile_skel
+
+package socks
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/netstack/conntrack"
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/netstack/protect"
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/routing"
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/runtime"
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/telemetry"
+	logpkg "github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/pkg/logging"
+)
+
+// TUN-инбаунд: потоки из tun2socks обрабатываются в процессе, без хопа
+// через локальный SOCKS. Так роутер и protect.LookupOwner видят исходный
+// 5-tuple приложения (адрес в TUN), а не loopback-адрес SOCKS-клиента, а
+// UDP не зависит от UDP ASSOCIATE и аутентификации SOCKS-инбаунда.
+
+var errFlowBlocked = errors.New("flow blocked by routing rules")
+
+// tunTCPHandler — обработчик TCP-потоков tun2socks (core.TCPConnHandler):
+// conn.LocalAddr() — адрес приложения, target — исходное назначение.
+type tunTCPHandler struct{}
+
+func (tunTCPHandler) Handle(conn net.Conn, target *net.TCPAddr) error {
+	m := routing.Metadata{Network: "tcp", Inbound: "tun", Destination: target.String()}
+	if a := conn.LocalAddr(); a != nil {
+		m.Source = a.String()
+	}
+	f := newFlow(m)
+	if f.decision.Outbound == routing.OutboundBlock {
+		log.Debug("flow blocked", logpkg.F("owner", f.meta.Owner), logpkg.F("dst", f.meta.Destination), logpkg.F("rule", f.decision.Rule))
+		conn.Close()
+		return errFlowBlocked
+	}
+	rc, err := dialFlow(context.WithValue(context.Background(), flowKey{}, f), "tcp", m.Destination)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	runtime.SafeGoNamed("tun.tcp", func() { relay(conn, rc) })
+	return nil
+}
+
+// relay копирует данные в обе стороны и закрывает оба конца, когда
+// любая сторона завершилась.
+func relay(a, b net.Conn) {
+	defer a.Close()
+	defer b.Close()
+	done := make(chan struct{}, 2)
+	go func() { _, _ = io.Copy(b, a); done <- struct{}{} }()
+	go func() { _, _ = io.Copy(a, b); done <- struct{}{} }()
+	<-done
+}
+
+// tunUDPTimeout — UDP-сессия закрывается, если в обе стороны нет пакетов.
+const tunUDPTimeout = 60 * time.Second
+
+// tunUDPConn — UDP-«соединение» tun2socks (core.UDPConn): LocalAddr —
+// адрес приложения, WriteFrom пишет ответ в TUN от имени addr.
+type tunUDPConn interface {
+	LocalAddr() *net.UDPAddr
+	WriteFrom(data []byte, addr *net.UDPAddr) (int, error)
+	Close() error
+}
+
+// tunUDPSession — исходящий сокет одного tunUDPConn. Outbound выбирается
+// один раз в Connect по первому назначению. seen — время последнего пакета
+// в любую сторону (UnixNano).
+type tunUDPSession struct {
+	pc   *conntrack.PacketConn
+	app  string
+	seen atomic.Int64
+}
+
+func (s *tunUDPSession) touch() { s.seen.Store(time.Now().UnixNano()) }
+
+// tunUDPHandler — обработчик UDP tun2socks (core.UDPConnHandler, через
+// адаптер в socks_bridge.go): direct — защищённый сокет, proxy — через
+// активный транспорт (runtime.TunnelListenPacket), пока он несёт UDP.
+type tunUDPHandler struct {
+	timeout  time.Duration
+	mu       sync.Mutex
+	sessions map[tunUDPConn]*tunUDPSession
+}
+
+func newTunUDPHandler(timeout time.Duration) *tunUDPHandler {
+	return &tunUDPHandler{timeout: timeout, sessions: map[tunUDPConn]*tunUDPSession{}}
+}
+
+func (h *tunUDPHandler) Connect(conn tunUDPConn, target *net.UDPAddr) error {
+	m := routing.Metadata{Network: "udp", Inbound: "tun"}
+	if a := conn.LocalAddr(); a != nil {
+		m.Source = a.String()
+	}
+	if target != nil {
+		m.Destination = target.String()
+	}
+	f := newFlow(m)
+	if f.decision.Outbound == routing.OutboundBlock {
+		log.Debug("flow blocked", logpkg.F("owner", f.meta.Owner), logpkg.F("dst", f.meta.Destination), logpkg.F("rule", f.decision.Rule))
+		return errFlowBlocked
+	}
+	pc, err := listenOutbound(context.Background(), f.decision.Outbound)
+	if err != nil {
+		log.Warn("udp listen failed", logpkg.F("outbound", f.decision.Outbound), logpkg.F("err", err))
+		return err
+	}
+	// Сессия — поток conntrack: CloseConnection закрывает исходящий сокет,
+	// relayBack видит ошибку и закрывает сессию (h.close).
+	tc := conntrack.TrackPacket(conntrack.Meta{
+		Inbound: m.Inbound, Network: m.Network, Source: m.Source, Destination: m.Destination,
+		Owner: f.meta.Owner, Rule: f.decision.Rule, Outbound: f.decision.Outbound,
+	}, pc)
+	s := &tunUDPSession{pc: tc, app: f.meta.Owner}
+	s.touch()
+	h.mu.Lock()
+	h.sessions[conn] = s
+	h.mu.Unlock()
+	telemetry.AppFlowOpened(s.app)
+	runtime.SafeGoNamed("tun.udp", func() { h.relayBack(conn, s) })
+	return nil
+}
+
+func (h *tunUDPHandler) ReceiveTo(conn tunUDPConn, data []byte, addr *net.UDPAddr) error {
+	h.mu.Lock()
+	s := h.sessions[conn]
+	h.mu.Unlock()
+	if s == nil {
+		conn.Close()
+		return errors.New("udp session not found")
+	}
+	n, err := s.pc.WriteTo(data, addr)
+	if err != nil {
+		h.close(conn)
+		return err
+	}
+	s.touch()
+	telemetry.BytesOut.Add(uint64(n))
+	telemetry.AddAppBytes(s.app, 0, uint64(n))
+	return nil
+}
+
+// relayBack гонит ответы в TUN, пока сессия не простоит timeout в обе стороны.
+func (h *tunUDPHandler) relayBack(conn tunUDPConn, s *tunUDPSession) {
+	defer h.close(conn)
+	buf := make([]byte, 64*1024)
+	for {
+		idle := time.Until(time.Unix(0, s.seen.Load()).Add(h.timeout))
+		if idle <= 0 {
+			return
+		}
+		_ = s.pc.SetReadDeadline(time.Now().Add(idle))
+		n, from, err := s.pc.ReadFrom(buf)
+		if err != nil {
+			if ne, ok := err.(net.Error); ok && ne.Timeout() {
+				continue
+			}
+			return
+		}
+		src, ok := from.(*net.UDPAddr)
+		if !ok {
+			if src, err = net.ResolveUDPAddr("udp", from.String()); err != nil {
+				continue
+			}
+		}
+		s.touch()
+		if _, err := conn.WriteFrom(buf[:n], src); err != nil {
+			return
+		}
+		telemetry.BytesIn.Add(uint64(n))
+		telemetry.AddAppBytes(s.app, uint64(n), 0)
+	}
+}
+
+func (h *tunUDPHandler) close(conn tunUDPConn) {
+	h.mu.Lock()
+	s := h.sessions[conn]
+	delete(h.sessions, conn)
+	h.mu.Unlock()
+	conn.Close()
+	if s != nil {
+		s.pc.Close() // убирает сессию из conntrack
+	}
+}
+
+// listenOutbound — UDP-аналог dialOutbound.
+func listenOutbound(ctx context.Context, outbound string) (net.PacketConn, error) {
+	if outbound != routing.OutboundDirect {
+		pc, err := runtime.TunnelListenPacket(ctx)
+		if !errors.Is(err, runtime.ErrNoTunnel) {
+			return pc, err
+		}
+		log.Debug("transport carries no udp, sending direct")
+	}
+	return protect.ProtectedListenPacket(ctx, ":0")
+}
@@ -0,0 +1,184 @@
+//go:build mobile_skel
+
+package socks
+
+import (
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/netstack/conntrack"
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/netstack/protect"
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/routing"
+)
+
+// tunConn — сторона приложения так, как её отдаёт tun2socks: LocalAddr —
+// адрес приложения внутри TUN.
+type tunConn struct {
+	net.Conn
+	local net.Addr
+}
+
+func (c tunConn) LocalAddr() net.Addr { return c.local }
+
+func TestTunTCP_OwnerFromOriginalTuple(t *testing.T) {
+	echoLn, echoAddr := startEchoServer(t)
+	defer echoLn.Close()
+	target, _ := net.ResolveTCPAddr("tcp", echoAddr)
+
+	var gotSrc string
+	var gotSrcPort int
+	protect.SetOwnerLookupHook(func(_, srcIP string, srcPort int, _ string, _ int) string {
+		gotSrc, gotSrcPort = srcIP, srcPort
+		if srcIP == "10.0.0.2" {
+			return "com.example.app"
+		}
+		return "com.blocked"
+	})
+	defer protect.SetOwnerLookupHook(nil)
+	routing.SetRules([]routing.Rule{{PackageName: []string{"com.blocked"}, Outbound: routing.OutboundBlock}}, "")
+	defer routing.SetRules(nil, "")
+
+	app, tun := net.Pipe()
+	defer app.Close()
+	src := &net.TCPAddr{IP: net.IPv4(10, 0, 0, 2), Port: 40000}
+	if err := (tunTCPHandler{}).Handle(tunConn{Conn: tun, local: src}, target); err != nil {
+		t.Fatalf("Handle: %v", err)
+	}
+	if gotSrc != "10.0.0.2" || gotSrcPort != 40000 {
+		t.Fatalf("owner looked up for %s:%d, want the app address in TUN", gotSrc, gotSrcPort)
+	}
+
+	var flow *conntrack.Info
+	for _, c := range conntrack.Snapshot() {
+		if c.Inbound == "tun" && c.Source == src.String() {
+			c := c
+			flow = &c
+		}
+	}
+	if flow == nil || flow.Owner != "com.example.app" || flow.Destination != echoAddr {
+		t.Fatalf("tun flow not tracked: %s", conntrack.SnapshotJSON())
+	}
+
+	_ = app.SetDeadline(time.Now().Add(2 * time.Second))
+	if _, err := app.Write([]byte("via-tun\n")); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 8)
+	if _, err := app.Read(buf); err != nil || string(buf) != "via-tun\n" {
+		t.Fatalf("echo via tun handler: %q %v", buf, err)
+	}
+
+	// Тот же handler с другим источником попадает под block.
+	app2, tun2 := net.Pipe()
+	defer app2.Close()
+	other := &net.TCPAddr{IP: net.IPv4(10, 0, 0, 3), Port: 40001}
+	if err := (tunTCPHandler{}).Handle(tunConn{Conn: tun2, local: other}, target); err != errFlowBlocked {
+		t.Fatalf("blocked flow: %v, want errFlowBlocked", err)
+	}
+}
+
+// fakeUDPConn — core.UDPConn: ответы, записанные в TUN, уходят в канал.
+type fakeUDPConn struct {
+	local  *net.UDPAddr
+	out    chan string
+	closed chan struct{}
+	once   sync.Once
+}
+
+func (c *fakeUDPConn) LocalAddr() *net.UDPAddr { return c.local }
+func (c *fakeUDPConn) WriteFrom(b []byte, from *net.UDPAddr) (int, error) {
+	c.out <- from.String() + " " + string(b)
+	return len(b), nil
+}
+func (c *fakeUDPConn) Close() error {
+	c.once.Do(func() { close(c.closed) })
+	return nil
+}
+
+func TestTunUDP_RelayAndIdleClose(t *testing.T) {
+	echo, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer echo.Close()
+	go func() {
+		b := make([]byte, 2048)
+		for {
+			n, from, err := echo.ReadFrom(b)
+			if err != nil {
+				return
+			}
+			echo.WriteTo(b[:n], from)
+		}
+	}()
+	target := echo.LocalAddr().(*net.UDPAddr)
+
+	h := newTunUDPHandler(200 * time.Millisecond)
+	conn := &fakeUDPConn{local: &net.UDPAddr{IP: net.IPv4(10, 0, 0, 2), Port: 5353}, out: make(chan string, 4), closed: make(chan struct{})}
+	if err := h.Connect(conn, target); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	if err := h.ReceiveTo(conn, []byte("q"), target); err != nil {
+		t.Fatalf("ReceiveTo: %v", err)
+	}
+	select {
+	case got := <-conn.out:
+		if got != target.String()+" q" {
+			t.Fatalf("reply written to TUN: %q", got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("no reply relayed back to TUN")
+	}
+
+	select {
+	case <-conn.closed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("idle session was not closed")
+	}
+	if err := h.ReceiveTo(conn, []byte("late"), target); err == nil {
+		t.Fatal("ReceiveTo after close must fail")
+	}
+}
+
+func TestTunUDP_ConntrackEntryAndClose(t *testing.T) {
+	protect.SetOwnerLookupHook(func(string, string, int, string, int) string { return "com.example.app" })
+	defer protect.SetOwnerLookupHook(nil)
+
+	h := newTunUDPHandler(time.Minute)
+	src := &net.UDPAddr{IP: net.IPv4(10, 0, 0, 2), Port: 5353}
+	conn := &fakeUDPConn{local: src, out: make(chan string, 1), closed: make(chan struct{})}
+	target := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 53}
+	if err := h.Connect(conn, target); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+
+	var flow *conntrack.Info
+	for _, c := range conntrack.Snapshot() {
+		if c.Inbound == "tun" && c.Source == src.String() {
+			c := c
+			flow = &c
+		}
+	}
+	if flow == nil || flow.Network != "udp" || flow.Owner != "com.example.app" || flow.Destination != target.String() || flow.Outbound == "" {
+		t.Fatalf("udp session not tracked: %s", conntrack.SnapshotJSON())
+	}
+
+	if !conntrack.CloseConnection(flow.ID) {
+		t.Fatal("CloseConnection returned false for the udp session")
+	}
+	select {
+	case <-conn.closed:
+	case <-time.After(2 * time.Second):
+		t.Fatal("CloseConnection did not tear down the udp session")
+	}
+	if err := h.ReceiveTo(conn, []byte("late"), target); err == nil {
+		t.Fatal("ReceiveTo after close must fail")
+	}
+	for _, c := range conntrack.Snapshot() {
+		if c.ID == flow.ID {
+			t.Fatal("closed session left in conntrack")
+		}
+	}
+}
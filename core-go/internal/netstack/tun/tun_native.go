@@ -14,7 +14,6 @@ import (
 	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/netstack/protect"
 	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/runtime"
 	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/telemetry"
-	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/transport"
 	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/mobile"
 	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/pkg/config"
 	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/pkg/errors"
@@ -35,7 +34,7 @@ func StartWithTun(configJSON string) string {
 	mobile.Mu.Lock()
 	defer mobile.Mu.Unlock()
 
-	if err := mobile.CfgSet(configJSON); err != nil {
+	if _, err := mobile.ApplyConfig(configJSON); err != nil {
 		return "invalid config: " + err.Error()
 	}
 	hc, err := config.ParseHY2Config()
@@ -44,12 +43,18 @@ func StartWithTun(configJSON string) string {
 	}
 
 	// 1) Поднимаем транспорт по Engine
-	tr := transport.SelectTransport(hc)
+	if err := runtime.ApplyDetour(hc.Detour); err != nil {
+		return "invalid config: " + err.Error()
+	}
+	tr, err := runtime.SelectTransport(hc)
+	if err != nil {
+		return "invalid config: " + err.Error()
+	}
 	ctx, cancel := context.WithCancel(context.Background())
 	if tr != nil {
 		if err := tr.Start(ctx); err != nil {
 			cancel()
-			telemetry.EmitError(int(errors.ErrEngineInitFailed), "hc/sing start: "+err.Error())
+			telemetry.EmitErr(err, errors.StageHandshake)
 			return "engine init failed: " + err.Error()
 		}
 	}
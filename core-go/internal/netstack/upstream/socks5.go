@@ -0,0 +1,280 @@
+//go:build android || ios || mobile_skel
+
+package upstream
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/netip"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/netstack/protect"
+	ers "github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/pkg/errors"
+)
+
+// RFC 1928 / RFC 1929.
+const (
+	socksVer      = 0x05
+	authNone      = 0x00
+	authPassword  = 0x02
+	authNoAccept  = 0xff
+	cmdConnect    = 0x01
+	cmdAssociate  = 0x03
+	atypIPv4      = 0x01
+	atypDomain    = 0x03
+	atypIPv6      = 0x04
+	repRefused    = 0x05 // «connection refused»: хост за прокси ответил RST
+	maxUDPPayload = 65507
+)
+
+// dialSOCKS5 — TCP через SOCKS5 CONNECT.
+func dialSOCKS5(ctx context.Context, c Config, addr string) (net.Conn, error) {
+	conn, err := firstHop(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := socksRequest(conn, c, cmdConnect, addr); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	_ = conn.SetDeadline(time.Time{})
+	return conn, nil
+}
+
+// associateSOCKS5 — UDP через SOCKS5 UDP ASSOCIATE. TCP-соединение управления
+// живёт, пока открыт PacketConn: прокси закрывает ассоциацию вместе с ним.
+func associateSOCKS5(ctx context.Context, c Config) (net.PacketConn, error) {
+	ctrl, err := firstHop(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+	relay, err := socksRequest(ctrl, c, cmdAssociate, "0.0.0.0:0")
+	if err != nil {
+		ctrl.Close()
+		return nil, err
+	}
+	_ = ctrl.SetDeadline(time.Time{})
+
+	relayAddr, err := net.ResolveUDPAddr("udp", relay)
+	if err != nil {
+		ctrl.Close()
+		return nil, fmt.Errorf("upstream socks5: bad relay address %q: %w", relay, err)
+	}
+	if relayAddr.IP.IsUnspecified() {
+		// Прокси вернул 0.0.0.0 — relay на том же хосте, что и TCP.
+		relayAddr.IP = ctrl.RemoteAddr().(*net.TCPAddr).IP
+	}
+	pc, err := protect.ProtectedPacketConn(ctx)
+	if err != nil {
+		ctrl.Close()
+		return nil, err
+	}
+	u := &udpAssoc{PacketConn: pc, ctrl: ctrl, relay: relayAddr, buf: make([]byte, udpAssocBuf)}
+	go u.watchCtrl()
+	return u, nil
+}
+
+// socksRequest выполняет приветствие, аутентификацию и запрос cmd;
+// возвращает BND.ADDR:BND.PORT из ответа.
+func socksRequest(conn net.Conn, c Config, cmd byte, addr string) (string, error) {
+	methods := []byte{authNone}
+	if c.Username != "" || c.Password != "" {
+		methods = []byte{authPassword}
+	}
+	if _, err := conn.Write(append([]byte{socksVer, byte(len(methods))}, methods...)); err != nil {
+		return "", err
+	}
+	var sel [2]byte
+	if _, err := io.ReadFull(conn, sel[:]); err != nil {
+		return "", fmt.Errorf("upstream socks5: greeting: %w", err)
+	}
+	if sel[0] != socksVer || sel[1] == authNoAccept {
+		return "", errors.New("upstream socks5: no acceptable auth method")
+	}
+	if sel[1] == authPassword {
+		b := []byte{0x01, byte(len(c.Username))}
+		b = append(b, c.Username...)
+		b = append(b, byte(len(c.Password)))
+		b = append(b, c.Password...)
+		if _, err := conn.Write(b); err != nil {
+			return "", err
+		}
+		var st [2]byte
+		if _, err := io.ReadFull(conn, st[:]); err != nil {
+			return "", fmt.Errorf("upstream socks5: auth: %w", err)
+		}
+		if st[1] != 0x00 {
+			return "", ers.New(ers.ErrAuthRejected, ers.StageAuth, "upstream socks5: authentication rejected")
+		}
+	}
+
+	req, err := appendAddr([]byte{socksVer, cmd, 0x00}, addr)
+	if err != nil {
+		return "", err
+	}
+	if _, err := conn.Write(req); err != nil {
+		return "", err
+	}
+	var hdr [3]byte
+	if _, err := io.ReadFull(conn, hdr[:]); err != nil {
+		return "", fmt.Errorf("upstream socks5: reply: %w", err)
+	}
+	switch hdr[1] {
+	case 0x00:
+	case repRefused:
+		return "", fmt.Errorf("upstream socks5: request %s: %w", addr, syscall.ECONNREFUSED)
+	default:
+		return "", fmt.Errorf("upstream socks5: request %s rejected (rep=%d)", addr, hdr[1])
+	}
+	return readAddr(conn)
+}
+
+// appendAddr дописывает ATYP ADDR PORT.
+func appendAddr(b []byte, addr string) ([]byte, error) {
+	host, ps, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	port, err := strconv.ParseUint(ps, 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("upstream socks5: bad port %q", ps)
+	}
+	switch ip := net.ParseIP(host); {
+	case ip != nil && ip.To4() != nil:
+		b = append(append(b, atypIPv4), ip.To4()...)
+	case ip != nil:
+		b = append(append(b, atypIPv6), ip.To16()...)
+	default:
+		if len(host) == 0 || len(host) > 255 {
+			return nil, errors.New("upstream socks5: bad domain length")
+		}
+		b = append(append(b, atypDomain, byte(len(host))), host...)
+	}
+	return binary.BigEndian.AppendUint16(b, uint16(port)), nil
+}
+
+// readAddr читает ATYP ADDR PORT из r.
+func readAddr(r io.Reader) (string, error) {
+	var atyp [1]byte
+	if _, err := io.ReadFull(r, atyp[:]); err != nil {
+		return "", err
+	}
+	var host string
+	switch atyp[0] {
+	case atypIPv4, atypIPv6:
+		n := net.IPv4len
+		if atyp[0] == atypIPv6 {
+			n = net.IPv6len
+		}
+		ip := make(net.IP, n)
+		if _, err := io.ReadFull(r, ip); err != nil {
+			return "", err
+		}
+		host = ip.String()
+	case atypDomain:
+		var l [1]byte
+		if _, err := io.ReadFull(r, l[:]); err != nil {
+			return "", err
+		}
+		b := make([]byte, l[0])
+		if _, err := io.ReadFull(r, b); err != nil {
+			return "", err
+		}
+		host = string(b)
+	default:
+		return "", fmt.Errorf("upstream socks5: unknown atyp %d", atyp[0])
+	}
+	var p [2]byte
+	if _, err := io.ReadFull(r, p[:]); err != nil {
+		return "", err
+	}
+	return net.JoinHostPort(host, strconv.Itoa(int(binary.BigEndian.Uint16(p[:])))), nil
+}
+
+// udpAssoc — net.PacketConn поверх UDP ASSOCIATE: каждая датаграмма
+// уходит на relay с заголовком RSV(2) FRAG(1) ATYP ADDR PORT.
+type udpAssoc struct {
+	net.PacketConn
+	ctrl  net.Conn
+	relay *net.UDPAddr
+
+	rmu       sync.Mutex // buf — один на соединение, ReadFrom сериализуется
+	buf       []byte
+	closeOnce sync.Once
+}
+
+// udpAssocBuf — максимальная датаграмма плюс заголовок: до 3+1+1+255+2 байт.
+const udpAssocBuf = 64*1024 + 262
+
+func (u *udpAssoc) WriteTo(b []byte, addr net.Addr) (int, error) {
+	if len(b) > maxUDPPayload {
+		return 0, errors.New("upstream socks5: datagram too large")
+	}
+	pkt, err := appendAddr([]byte{0, 0, 0}, addr.String())
+	if err != nil {
+		return 0, err
+	}
+	if _, err := u.PacketConn.WriteTo(append(pkt, b...), u.relay); err != nil {
+		return 0, err
+	}
+	return len(b), nil
+}
+
+func (u *udpAssoc) ReadFrom(b []byte) (int, net.Addr, error) {
+	u.rmu.Lock()
+	defer u.rmu.Unlock()
+	buf := u.buf
+	for {
+		n, src, err := u.PacketConn.ReadFrom(buf)
+		if err != nil {
+			return 0, nil, err
+		}
+		// Сокет не подключён: всё, что пришло не от relay, — чужие
+		// датаграммы (в том числе с поддельным SOCKS-заголовком).
+		if ua, ok := src.(*net.UDPAddr); !ok || !ua.IP.Equal(u.relay.IP) || ua.Port != u.relay.Port {
+			continue
+		}
+		if n < 4 || buf[2] != 0 { // фрагменты не поддерживаем — отбрасываем
+			continue
+		}
+		r := bytes.NewReader(buf[3:n])
+		from, err := readAddr(r)
+		if err != nil {
+			continue
+		}
+		var addr net.Addr = domainAddr(from)
+		if ap, err := netip.ParseAddrPort(from); err == nil {
+			addr = net.UDPAddrFromAddrPort(ap)
+		}
+		return copy(b, buf[n-r.Len():n]), addr, nil
+	}
+}
+
+func (u *udpAssoc) Close() error {
+	var err error
+	u.closeOnce.Do(func() {
+		err = u.PacketConn.Close()
+		_ = u.ctrl.Close()
+	})
+	return err
+}
+
+// watchCtrl: прокси закрыл TCP управления — ассоциация мертва, закрываем UDP.
+func (u *udpAssoc) watchCtrl() {
+	_, _ = io.Copy(io.Discard, u.ctrl)
+	_ = u.Close()
+}
+
+// domainAddr — адрес источника, который прокси прислал доменом.
+type domainAddr string
+
+func (a domainAddr) Network() string { return "udp" }
+func (a domainAddr) String() string  { return string(a) }
@@ -0,0 +1,126 @@
+//go:build android || ios || mobile_skel
+
+// Package upstream — цепочка через вышестоящий прокси (detour) для сетей,
+// где весь egress обязан идти через корпоративный SOCKS5/HTTP-прокси.
+//
+// Транспорты открывают сокеты до сервера только через DialContext и
+// ListenPacket этого пакета: без настроенного upstream это обычные
+// защищённые сокеты (protect), с upstream — TCP идёт через SOCKS5 CONNECT
+// или HTTP CONNECT, а UDP (QUIC Hysteria2) — через SOCKS5 UDP ASSOCIATE.
+// Первый хоп до самого прокси всегда защищён (protect.ProtectedTCPDialer).
+package upstream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/netstack/protect"
+)
+
+// Типы вышестоящего прокси.
+const (
+	TypeSOCKS5 = "socks5"
+	TypeHTTP   = "http"
+)
+
+// ErrUDPUnsupported — upstream не умеет UDP (HTTP CONNECT).
+var ErrUDPUnsupported = errors.New("upstream: http proxy cannot carry UDP")
+
+// Config — вышестоящий прокси.
+type Config struct {
+	Type     string // socks5 | http
+	Server   string // host:port
+	Username string
+	Password string
+}
+
+// Validate проверяет тип и адрес прокси.
+func (c Config) Validate() error {
+	if c.Type != TypeSOCKS5 && c.Type != TypeHTTP {
+		return fmt.Errorf("upstream: unknown type %q", c.Type)
+	}
+	if _, _, err := net.SplitHostPort(c.Server); err != nil {
+		return fmt.Errorf("upstream: server must be host:port: %w", err)
+	}
+	if len(c.Username) > 255 || len(c.Password) > 255 {
+		return errors.New("upstream: username/password longer than 255 bytes")
+	}
+	return nil
+}
+
+var (
+	mu     sync.RWMutex
+	cur    Config
+	active bool
+)
+
+// Set включает upstream для всех последующих dial/listen.
+func Set(c Config) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	mu.Lock()
+	cur, active = c, true
+	mu.Unlock()
+	return nil
+}
+
+// Reset выключает upstream: сокеты снова идут напрямую.
+func Reset() {
+	mu.Lock()
+	cur, active = Config{}, false
+	mu.Unlock()
+}
+
+// Active возвращает текущий upstream (false — не настроен).
+func Active() (Config, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+	return cur, active
+}
+
+// DialContext открывает TCP-соединение до addr — через upstream, если он настроен.
+func DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	c, ok := Active()
+	if !ok {
+		return protect.ProtectedTCPDialer().DialContext(ctx, network, addr)
+	}
+	switch network {
+	case "tcp", "tcp4", "tcp6":
+	default:
+		return nil, fmt.Errorf("upstream: network %q not supported", network)
+	}
+	if c.Type == TypeHTTP {
+		return dialHTTP(ctx, c, addr)
+	}
+	return dialSOCKS5(ctx, c, addr)
+}
+
+// ListenPacket открывает UDP-сокет для транспорта — через SOCKS5 UDP ASSOCIATE,
+// если upstream настроен. Адреса в WriteTo/ReadFrom — адреса назначения, как у
+// обычного net.PacketConn.
+func ListenPacket(ctx context.Context) (net.PacketConn, error) {
+	c, ok := Active()
+	if !ok {
+		return protect.ProtectedPacketConn(ctx)
+	}
+	if c.Type != TypeSOCKS5 {
+		return nil, ErrUDPUnsupported
+	}
+	return associateSOCKS5(ctx, c)
+}
+
+// firstHop — защищённое TCP-соединение до самого прокси.
+func firstHop(ctx context.Context, c Config) (net.Conn, error) {
+	conn, err := protect.ProtectedTCPDialer().DialContext(ctx, "tcp", c.Server)
+	if err != nil {
+		return nil, fmt.Errorf("upstream %s %s: %w", c.Type, c.Server, err)
+	}
+	if dl, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(dl)
+	}
+	return conn, nil
+}
@@ -0,0 +1,299 @@
+//go:build mobile_skel
+
+package upstream
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/netstack/protect"
+	ers "github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/pkg/errors"
+	socks5 "github.com/armon/go-socks5"
+)
+
+func init() {
+	// На десктопе VpnService нет: «защищаем» любые сокеты.
+	protect.SetProtectHook(func(int) bool { return true })
+}
+
+func echoServer(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() { defer c.Close(); io.Copy(c, c) }()
+		}
+	}()
+	return ln.Addr().String()
+}
+
+func roundTrip(t *testing.T, c net.Conn) {
+	t.Helper()
+	defer c.Close()
+	c.SetDeadline(time.Now().Add(2 * time.Second))
+	if _, err := c.Write([]byte("ping")); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 4)
+	if _, err := io.ReadFull(c, buf); err != nil || string(buf) != "ping" {
+		t.Fatalf("echo: %q %v", buf, err)
+	}
+}
+
+func TestDial_DirectWithoutUpstream(t *testing.T) {
+	Reset()
+	c, err := DialContext(context.Background(), "tcp", echoServer(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	roundTrip(t, c)
+
+	// Отказ VpnService.protect — типизированная ошибка, а не голый текст.
+	protect.SetProtectHook(func(int) bool { return false })
+	defer protect.SetProtectHook(func(int) bool { return true })
+	if _, err := DialContext(context.Background(), "tcp", echoServer(t)); !errors.Is(err, ers.ErrProtectFailed) {
+		t.Fatalf("dial with protect refused = %v, want protect_failed", err)
+	}
+}
+
+func TestDial_SOCKS5Connect(t *testing.T) {
+	srv, err := socks5.New(&socks5.Config{
+		Credentials: socks5.StaticCredentials{"corp": "pw"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go srv.Serve(ln)
+
+	target := echoServer(t)
+	if err := Set(Config{Type: TypeSOCKS5, Server: ln.Addr().String(), Username: "corp", Password: "pw"}); err != nil {
+		t.Fatal(err)
+	}
+	defer Reset()
+	c, err := DialContext(context.Background(), "tcp", target)
+	if err != nil {
+		t.Fatalf("DialContext via socks5: %v", err)
+	}
+	roundTrip(t, c)
+
+	_ = Set(Config{Type: TypeSOCKS5, Server: ln.Addr().String(), Username: "corp", Password: "bad"})
+	if _, err := DialContext(context.Background(), "tcp", target); !errors.Is(err, ers.ErrAuthRejected) {
+		t.Fatalf("wrong password = %v, want auth_rejected", err)
+	}
+}
+
+func TestDial_HTTPConnect(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	wantAuth := "Basic " + base64.StdEncoding.EncodeToString([]byte("corp:pw"))
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func(c net.Conn) {
+				defer c.Close()
+				req, err := http.ReadRequest(bufio.NewReader(c))
+				if err != nil || req.Method != http.MethodConnect {
+					return
+				}
+				if req.Header.Get("Proxy-Authorization") != wantAuth {
+					io.WriteString(c, "HTTP/1.1 407 Proxy Authentication Required\r\n\r\n")
+					return
+				}
+				up, err := net.Dial("tcp", req.Host)
+				if err != nil {
+					io.WriteString(c, "HTTP/1.1 502 Bad Gateway\r\n\r\n")
+					return
+				}
+				defer up.Close()
+				io.WriteString(c, "HTTP/1.1 200 Connection established\r\n\r\n")
+				go io.Copy(up, c)
+				io.Copy(c, up)
+			}(c)
+		}
+	}()
+
+	target := echoServer(t)
+	_ = Set(Config{Type: TypeHTTP, Server: ln.Addr().String(), Username: "corp", Password: "pw"})
+	defer Reset()
+	c, err := DialContext(context.Background(), "tcp", target)
+	if err != nil {
+		t.Fatalf("DialContext via http: %v", err)
+	}
+	roundTrip(t, c)
+
+	if _, err := ListenPacket(context.Background()); !errors.Is(err, ErrUDPUnsupported) {
+		t.Fatalf("ListenPacket via http = %v, want ErrUDPUnsupported", err)
+	}
+
+	_ = Set(Config{Type: TypeHTTP, Server: ln.Addr().String()})
+	if _, err := DialContext(context.Background(), "tcp", target); err == nil {
+		t.Fatal("CONNECT without credentials must fail with 407")
+	}
+}
+
+// udpAssociateServer — минимальный SOCKS5 (без auth) с поддержкой UDP ASSOCIATE.
+func udpAssociateServer(t *testing.T) string {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func(c net.Conn) {
+				defer c.Close()
+				buf := make([]byte, 262)
+				io.ReadFull(c, buf[:2])
+				io.ReadFull(c, buf[:buf[1]])
+				c.Write([]byte{socksVer, authNone})
+				io.ReadFull(c, buf[:3])
+				if _, err := readAddr(c); err != nil || buf[1] != cmdAssociate {
+					return
+				}
+				relay, err := net.ListenPacket("udp", "127.0.0.1:0")
+				if err != nil {
+					return
+				}
+				defer relay.Close()
+				// BND.ADDR = 0.0.0.0: клиент должен подставить адрес прокси.
+				rep, _ := appendAddr([]byte{socksVer, 0, 0}, net.JoinHostPort("0.0.0.0", portOf(relay.LocalAddr())))
+				c.Write(rep)
+				go func() {
+					pkt := make([]byte, 2048)
+					var client net.Addr
+					for {
+						n, from, err := relay.ReadFrom(pkt)
+						if err != nil {
+							return
+						}
+						if client == nil || from.String() == client.String() {
+							client = from
+							r := &bytesReader{b: pkt[3:n]}
+							dst, err := readAddr(r)
+							if err != nil {
+								continue
+							}
+							ua, _ := net.ResolveUDPAddr("udp", dst)
+							relay.WriteTo(r.b, ua)
+							continue
+						}
+						hdr, _ := appendAddr([]byte{0, 0, 0}, from.String())
+						relay.WriteTo(append(hdr, pkt[:n]...), client)
+					}
+				}()
+				io.Copy(io.Discard, c) // ассоциация живёт, пока открыт TCP
+			}(c)
+		}
+	}()
+	return ln.Addr().String()
+}
+
+func TestListenPacket_SOCKS5Associate(t *testing.T) {
+	echo, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer echo.Close()
+	go func() {
+		b := make([]byte, 2048)
+		for {
+			n, from, err := echo.ReadFrom(b)
+			if err != nil {
+				return
+			}
+			echo.WriteTo(b[:n], from)
+		}
+	}()
+
+	_ = Set(Config{Type: TypeSOCKS5, Server: udpAssociateServer(t)})
+	defer Reset()
+	pc, err := ListenPacket(context.Background())
+	if err != nil {
+		t.Fatalf("ListenPacket via socks5: %v", err)
+	}
+	defer pc.Close()
+
+	// Чужой отправитель подделывает SOCKS-заголовок «от echo» — не relay, отбрасывается.
+	stray, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stray.Close()
+	spoof, _ := appendAddr([]byte{0, 0, 0}, echo.LocalAddr().String())
+	stray.WriteTo(append(spoof, "spoof"...), &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: pc.LocalAddr().(*net.UDPAddr).Port})
+	time.Sleep(50 * time.Millisecond)
+
+	if _, err := pc.WriteTo([]byte("quic"), echo.LocalAddr()); err != nil {
+		t.Fatal(err)
+	}
+	pc.SetReadDeadline(time.Now().Add(2 * time.Second))
+	buf := make([]byte, 64)
+	n, from, err := pc.ReadFrom(buf)
+	if err != nil {
+		t.Fatalf("ReadFrom: %v", err)
+	}
+	if string(buf[:n]) != "quic" || from.String() != echo.LocalAddr().String() {
+		t.Fatalf("got %q from %v", buf[:n], from)
+	}
+}
+
+func TestConfig_Validate(t *testing.T) {
+	for _, c := range []Config{
+		{Type: "ftp", Server: "p:1"},
+		{Type: TypeSOCKS5, Server: "noport"},
+	} {
+		if err := Set(c); err == nil {
+			t.Fatalf("Set(%+v) must fail", c)
+		}
+	}
+	if _, ok := Active(); ok {
+		t.Fatal("invalid config must not activate upstream")
+	}
+}
+
+func portOf(a net.Addr) string {
+	_, p, _ := net.SplitHostPort(a.String())
+	return p
+}
+
+type bytesReader struct{ b []byte }
+
+func (r *bytesReader) Read(p []byte) (int, error) {
+	if len(r.b) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(p, r.b)
+	r.b = r.b[n:]
+	return n, nil
+}
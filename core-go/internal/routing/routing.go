@@ -0,0 +1,146 @@
+//go:build android || ios || mobile_skel
+
+// Package routing — выбор outbound'а для каждого потока (SOCKS / TUN).
+//
+// Правила проверяются сверху вниз, первое совпадение побеждает;
+// если ни одно правило не подошло — используется Final (по умолчанию "proxy").
+// Сейчас правила умеют матчить владельца потока (package_name на Android,
+// bundle_id на iOS), который определяет платформенный хук
+// protect.SetOwnerLookupHook.
+package routing
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// Имена outbound'ов, понятные ядру.
+const (
+	OutboundProxy  = "proxy"  // через активный транспорт (HY2)
+	OutboundDirect = "direct" // мимо туннеля, защищённым сокетом
+	OutboundBlock  = "block"  // поток отклоняется
+)
+
+// Rule — одно правило маршрутизации.
+// Пустые списки не участвуют в матчинге; правило без условий не совпадает ни с чем.
+type Rule struct {
+	PackageName []string // Android: имя пакета владельца потока
+	BundleID    []string // iOS: bundle id владельца потока
+	Outbound    string   // proxy | direct | block
+}
+
+// Metadata — описание потока, по которому принимается решение.
+type Metadata struct {
+	Network     string // "tcp" | "udp"
+	Inbound     string // "socks" | "tun" | ...
+	Source      string // host:port источника
+	Destination string // host:port назначения
+	Domain      string // домен назначения, если известен
+	Owner       string // идентификатор приложения-владельца (может быть пустым)
+}
+
+// Decision — результат маршрутизации.
+type Decision struct {
+	Rule     string // "rules[N]" или "final"
+	Outbound string
+}
+
+var (
+	mu    sync.RWMutex
+	rules []Rule
+	final = OutboundProxy
+
+	// hits[i] — срабатывания rules[i], hits[len(rules)] — final.
+	hits = make([]atomic.Uint64, 1)
+)
+
+// SetRules атомарно заменяет таблицу правил. Пустой final = "proxy".
+func SetRules(rs []Rule, fin string) {
+	cp := make([]Rule, len(rs))
+	copy(cp, rs)
+	if fin == "" {
+		fin = OutboundProxy
+	}
+	mu.Lock()
+	rules = cp
+	final = fin
+	hits = make([]atomic.Uint64, len(cp)+1)
+	mu.Unlock()
+}
+
+// Reset возвращает роутер в состояние «всё через proxy».
+func Reset() { SetRules(nil, "") }
+
+// Match подбирает outbound для потока m.
+func Match(m Metadata) Decision {
+	mu.RLock()
+	defer mu.RUnlock()
+	for i := range rules {
+		if rules[i].match(m) {
+			hit(i)
+			return Decision{Rule: fmt.Sprintf("rules[%d]", i), Outbound: rules[i].Outbound}
+		}
+	}
+	hit(len(rules))
+	return Decision{Rule: "final", Outbound: final}
+}
+
+func hit(i int) {
+	if i < len(hits) {
+		hits[i].Add(1)
+	}
+}
+
+// RuleStat — счётчик срабатываний одного правила (для метрик).
+type RuleStat struct {
+	Rule     string `json:"rule"`
+	Outbound string `json:"outbound"`
+	Hits     uint64 `json:"hits"`
+}
+
+// Stats возвращает счётчики срабатываний правил с момента последнего SetRules.
+func Stats() []RuleStat {
+	mu.RLock()
+	defer mu.RUnlock()
+	out := make([]RuleStat, 0, len(rules)+1)
+	for i := range rules {
+		out = append(out, RuleStat{Rule: fmt.Sprintf("rules[%d]", i), Outbound: rules[i].Outbound, Hits: load(i)})
+	}
+	return append(out, RuleStat{Rule: "final", Outbound: final, Hits: load(len(rules))})
+}
+
+func load(i int) uint64 {
+	if i < len(hits) {
+		return hits[i].Load()
+	}
+	return 0
+}
+
+// ValidOutbound сообщает, знает ли ядро такой outbound.
+func ValidOutbound(name string) bool {
+	switch name {
+	case OutboundProxy, OutboundDirect, OutboundBlock:
+		return true
+	}
+	return false
+}
+
+func (r *Rule) match(m Metadata) bool {
+	if len(r.PackageName) == 0 && len(r.BundleID) == 0 {
+		return false
+	}
+	if m.Owner == "" {
+		return false
+	}
+	return contains(r.PackageName, m.Owner) || contains(r.BundleID, m.Owner)
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,70 @@
+//go:build mobile_skel
+
+package routing
+
+import "testing"
+
+func TestMatch_OwnerRules(t *testing.T) {
+	SetRules([]Rule{
+		{PackageName: []string{"org.telegram.messenger"}, Outbound: OutboundDirect},
+		{BundleID: []string{"com.example.bank"}, Outbound: OutboundBlock},
+	}, "")
+	defer Reset()
+
+	cases := []struct {
+		owner    string
+		rule     string
+		outbound string
+	}{
+		{"org.telegram.messenger", "rules[0]", OutboundDirect},
+		{"com.example.bank", "rules[1]", OutboundBlock},
+		{"com.other.app", "final", OutboundProxy},
+		{"", "final", OutboundProxy},
+	}
+	for _, c := range cases {
+		d := Match(Metadata{Network: "tcp", Owner: c.owner})
+		if d.Rule != c.rule || d.Outbound != c.outbound {
+			t.Fatalf("Match(owner=%q) = %+v, want %s/%s", c.owner, d, c.rule, c.outbound)
+		}
+	}
+}
+
+func TestMatch_FinalOverride(t *testing.T) {
+	SetRules(nil, OutboundDirect)
+	defer Reset()
+
+	if d := Match(Metadata{}); d.Outbound != OutboundDirect || d.Rule != "final" {
+		t.Fatalf("unexpected decision: %+v", d)
+	}
+}
+
+func TestValidOutbound(t *testing.T) {
+	for _, ok := range []string{OutboundProxy, OutboundDirect, OutboundBlock} {
+		if !ValidOutbound(ok) {
+			t.Fatalf("ValidOutbound(%q) = false", ok)
+		}
+	}
+	if ValidOutbound("hy2") {
+		t.Fatal("ValidOutbound(hy2) must be false")
+	}
+}
+
+func TestStats_CountsHits(t *testing.T) {
+	SetRules([]Rule{{PackageName: []string{"a"}, Outbound: OutboundDirect}}, "")
+	defer Reset()
+
+	Match(Metadata{Owner: "a"})
+	Match(Metadata{Owner: "a"})
+	Match(Metadata{Owner: "b"})
+
+	st := Stats()
+	if len(st) != 2 {
+		t.Fatalf("expected 2 stats entries, got %#v", st)
+	}
+	if st[0].Rule != "rules[0]" || st[0].Hits != 2 || st[0].Outbound != OutboundDirect {
+		t.Fatalf("unexpected rule stat: %+v", st[0])
+	}
+	if st[1].Rule != "final" || st[1].Hits != 1 || st[1].Outbound != OutboundProxy {
+		t.Fatalf("unexpected final stat: %+v", st[1])
+	}
+}
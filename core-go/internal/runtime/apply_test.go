@@ -0,0 +1,25 @@
+//go:build mobile_skel
+
+package runtime
+
+import (
+	"testing"
+
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/pkg/config"
+	logpkg "github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/pkg/logging"
+)
+
+func TestApplyLog_EmptyFieldsRestoreDefaults(t *testing.T) {
+	var hc config.HY2Config
+	hc.Log.Level, hc.Log.Format = "debug", logpkg.FormatJSON
+	ApplyLog(hc)
+	if logpkg.LogLevel != "debug" || logpkg.Format != logpkg.FormatJSON {
+		t.Fatalf("log = %s/%s, want debug/json", logpkg.LogLevel, logpkg.Format)
+	}
+
+	// Reload без секции log — уровень и формат по умолчанию.
+	ApplyLog(config.HY2Config{})
+	if logpkg.LogLevel != "info" || logpkg.Format != logpkg.FormatText {
+		t.Fatalf("log = %s/%s after removing the section, want info/text", logpkg.LogLevel, logpkg.Format)
+	}
+}
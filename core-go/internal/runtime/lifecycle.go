@@ -9,47 +9,134 @@
 //
 // Концептуально safeGo = goroutine sandbox: любая panic() внутри функции
 // будет перехвачена, залогирована и отправлена как событие "panic" через EventSink.
+// Долгоживущие подсистемы (supervisor транспорта, serve-циклы инбаундов)
+// запускаются через SafeGoRestart и после паники перезапускаются по политике.
 package runtime
 
 import (
 	"fmt"
+	rtdebug "runtime/debug"
+	"sync"
+	"time"
 
 	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/telemetry"
 	logpkg "github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/pkg/logging"
 )
 
-// safeGo запускает переданную функцию fn() в отдельной горутине
+var log = logpkg.With(logpkg.CompRuntime)
+
+// Политики реакции на панику в долгоживущих подсистемах (SafeGoRestart).
+const (
+	PanicPolicyRestart = "restart" // перезапустить подсистему (по умолчанию)
+	PanicPolicyStop    = "stop"    // оставить подсистему остановленной
+)
+
+// DefaultMaxPanicRestarts — лимит перезапусков одной подсистемы по умолчанию.
+const DefaultMaxPanicRestarts = 5
+
+var (
+	panicMu          sync.RWMutex
+	panicPolicy      = PanicPolicyRestart
+	panicMaxRestarts = DefaultMaxPanicRestarts
+)
+
+// SetPanicPolicy задаёт политику для SafeGoRestart: "restart" | "stop".
+// maxRestarts <= 0 — DefaultMaxPanicRestarts.
+func SetPanicPolicy(policy string, maxRestarts int) {
+	if policy != PanicPolicyStop {
+		policy = PanicPolicyRestart
+	}
+	if maxRestarts <= 0 {
+		maxRestarts = DefaultMaxPanicRestarts
+	}
+	panicMu.Lock()
+	panicPolicy, panicMaxRestarts = policy, maxRestarts
+	panicMu.Unlock()
+}
+
+// SafeGo запускает переданную функцию fn() в отдельной горутине
 // с автоматическим перехватом panic и уведомлением через события SDK.
-//
-// Аргументы:
-//   - fn — функция, которая выполняется в отдельной горутине.
-//
-// Поведение:
-//   - Любая panic() внутри fn не прерывает работу ядра;
-//   - Логируется через logE(...);
-//   - Отправляется событие emit("panic", {"msg":"panic recovered"}).
+// Эквивалентно SafeGoNamed("anonymous", fn).
 //
 // Пример использования:
 //
-//	safeGo(func() {
+//	SafeGo(func() {
 //	    doNetworkLoop()
 //	})
-//
-// Потокобезопасность:
-//   - safeGo не требует внешней синхронизации;
-//   - Все вызовы logE() и emit() — потокобезопасны.
+func SafeGo(fn func()) { SafeGoNamed("anonymous", fn) }
+
+// SafeGoNamed — SafeGo с именем горутины (label), которое попадает
+// в лог, событие "panic" и Health.last_panic. Подходит для задач на одно
+// соединение: после паники горутина просто завершается.
 //
 // Побочные эффекты:
-//   - Создаёт новую горутину;
-//   - В случае panic пишет лог уровня "error" и триггерит событие "panic".
-func SafeGo(fn func()) {
+//   - создаёт новую горутину;
+//   - в случае panic пишет лог уровня "error" со стеком, увеличивает
+//     telemetry.Panics и эмитит событие "panic".
+func SafeGoNamed(label string, fn func()) {
 	go func() {
-		defer func() {
-			if r := recover(); r != nil {
-				logpkg.LogE(fmt.Sprintf("panic: %v", r))
-				telemetry.Emit("panic", `{"msg":"panic recovered"}`)
-			}
-		}()
-		fn()
+		if v, stack, panicked := runGuarded(fn); panicked {
+			reportPanic(label, v, stack, false)
+		}
 	}()
 }
+
+// SafeGoRestart запускает долгоживущую подсистему (supervisor, serve-цикл)
+// в отдельной горутине; см. RunRestartable.
+func SafeGoRestart(label string, fn func(), alive func() bool) {
+	go RunRestartable(label, fn, alive)
+}
+
+// RunRestartable выполняет fn и, если она упала с паникой, перезапускает её
+// согласно политике (SetPanicPolicy) — не более maxRestarts раз, с нарастающей
+// паузой. alive (может быть nil) сообщает, нужна ли подсистема до сих пор:
+// остановленную подсистему не перезапускаем. Возвращает, когда fn завершилась
+// штатно или перезапуски больше не положены.
+func RunRestartable(label string, fn func(), alive func() bool) {
+	for restarts := 0; ; restarts++ {
+		v, stack, panicked := runGuarded(fn)
+		if !panicked {
+			return
+		}
+		panicMu.RLock()
+		restart := panicPolicy == PanicPolicyRestart && restarts < panicMaxRestarts
+		panicMu.RUnlock()
+		if alive != nil && !alive() {
+			restart = false
+		}
+		reportPanic(label, v, stack, restart)
+		if !restart {
+			return
+		}
+		time.Sleep(restartDelay(restarts))
+		if alive != nil && !alive() {
+			return
+		}
+	}
+}
+
+// restartDelay — 250ms, 500ms, 1s, ... но не более 5s.
+func restartDelay(n int) time.Duration {
+	d := 250 * time.Millisecond << n
+	if d <= 0 || d > 5*time.Second {
+		d = 5 * time.Second
+	}
+	return d
+}
+
+// runGuarded вызывает fn и возвращает значение паники и стек (если была).
+func runGuarded(fn func()) (v any, stack []byte, panicked bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			v, stack, panicked = r, rtdebug.Stack(), true
+		}
+	}()
+	fn()
+	return nil, nil, false
+}
+
+func reportPanic(label string, v any, stack []byte, restart bool) {
+	p := telemetry.RecordPanic(label, v, stack, restart)
+	log.Error("panic: "+fmt.Sprint(v), logpkg.F("label", label), logpkg.F("restart", restart), logpkg.F("count", p.Count))
+	log.Debug("panic stack", logpkg.F("label", label), logpkg.F("stack", p.Stack))
+}
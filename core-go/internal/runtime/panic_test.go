@@ -0,0 +1,93 @@
+//go:build mobile_skel
+
+package runtime
+
+import (
+	"encoding/json"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/telemetry"
+)
+
+func TestRunRestartable_RestartsAfterPanic(t *testing.T) {
+	SetPanicPolicy(PanicPolicyRestart, 3)
+	defer SetPanicPolicy("", 0)
+
+	before := telemetry.Panics.Load()
+	var runs atomic.Int32
+	RunRestartable("test.loop", func() {
+		if runs.Add(1) < 3 {
+			panic("boom password=hunter22")
+		}
+	}, nil)
+
+	if runs.Load() != 3 {
+		t.Fatalf("expected 3 runs (2 panics + ok), got %d", runs.Load())
+	}
+	if got := telemetry.Panics.Load() - before; got != 2 {
+		t.Fatalf("expected 2 recorded panics, got %d", got)
+	}
+	lp := telemetry.LastPanic()
+	if lp == nil || lp.Label != "test.loop" || !lp.Restart {
+		t.Fatalf("unexpected last panic: %+v", lp)
+	}
+	if strings.Contains(lp.Msg, "hunter22") || !strings.Contains(lp.Stack, "panic_test.go") {
+		t.Fatalf("panic must be redacted and carry a stack: %+v", lp)
+	}
+}
+
+func TestRunRestartable_StopPolicyAndLimit(t *testing.T) {
+	SetPanicPolicy(PanicPolicyStop, 0)
+	var runs atomic.Int32
+	RunRestartable("test.stop", func() { runs.Add(1); panic("x") }, nil)
+	if runs.Load() != 1 {
+		t.Fatalf("stop policy must not restart, runs=%d", runs.Load())
+	}
+	if lp := telemetry.LastPanic(); lp.Restart {
+		t.Fatalf("restart flag must be false under stop policy: %+v", lp)
+	}
+
+	SetPanicPolicy(PanicPolicyRestart, 1)
+	defer SetPanicPolicy("", 0)
+	runs.Store(0)
+	RunRestartable("test.limit", func() { runs.Add(1); panic("x") }, nil)
+	if runs.Load() != 2 {
+		t.Fatalf("max_restarts=1 must allow exactly one restart, runs=%d", runs.Load())
+	}
+
+	runs.Store(0)
+	RunRestartable("test.dead", func() { runs.Add(1); panic("x") }, func() bool { return false })
+	if runs.Load() != 1 {
+		t.Fatalf("stopped subsystem must not restart, runs=%d", runs.Load())
+	}
+}
+
+func TestSafeGoNamed_PanicEvent(t *testing.T) {
+	telemetry.FlushEvents(time.Second) // не ловим события предыдущих тестов
+	got := make(chan string, 8)
+	id := telemetry.SubscribeEvents(telemetry.EventSinkFunc(func(name, data string) {
+		select {
+		case got <- data:
+		default:
+		}
+	}), telemetry.EvtPanic)
+	defer telemetry.UnsubscribeEvents(id)
+
+	SafeGoNamed("test.conn", func() { panic("conn crash") })
+
+	select {
+	case data := <-got:
+		var p telemetry.PanicInfo
+		if err := json.Unmarshal([]byte(data), &p); err != nil {
+			t.Fatalf("invalid panic payload: %v", err)
+		}
+		if p.Label != "test.conn" || p.Msg != "conn crash" || p.Stack == "" || p.Restart {
+			t.Fatalf("unexpected panic payload: %+v", p)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("panic event was not emitted")
+	}
+}
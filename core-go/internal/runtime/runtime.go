@@ -4,12 +4,20 @@ package runtime
 
 import (
 	"context"
+	"errors"
+	"net"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/netstack/forward"
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/netstack/upstream"
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/routing"
 	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/telemetry"
 	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/transport"
 	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/pkg/config"
+	ers "github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/pkg/errors"
+	logpkg "github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/pkg/logging"
 )
 
 var (
@@ -18,8 +26,32 @@ var (
 	RtCancel  context.CancelFunc
 	RtTrans   transport.Transport
 	RtUptime  time.Time
+	RtConfig  config.HY2Config // конфиг, применённый последним Start/Reload
 )
 
+// activeTrans — копия RtTrans для горячих путей (dial пробросов), которые
+// идут без блокировок, пока reconnectTransport меняет транспорт.
+// RtTrans меняется только через setTransport.
+var activeTrans atomic.Pointer[transport.Transport]
+
+func setTransport(tr transport.Transport) {
+	RtTrans = tr
+	if tr == nil {
+		activeTrans.Store(nil)
+		return
+	}
+	activeTrans.Store(&tr)
+}
+
+// ActiveTransport — текущий транспорт (nil, если ядро не запущено);
+// безопасно из любых горутин.
+func ActiveTransport() transport.Transport {
+	if p := activeTrans.Load(); p != nil {
+		return *p
+	}
+	return nil
+}
+
 func RuntimeStart() error {
 	if RtStarted {
 		return nil
@@ -27,20 +59,43 @@ func RuntimeStart() error {
 
 	hc, err := config.ParseHY2Config()
 	if err != nil {
-		return err
+		return ers.Wrap(err, ers.ErrInvalidConfig, ers.StageConfig, "")
+	}
+	ApplyLog(hc)
+	SetPanicPolicy(hc.Panic.Policy, hc.Panic.MaxRestarts)
+	ApplyRoute(hc.Route)
+	if err := ApplyDetour(hc.Detour); err != nil {
+		return ers.Wrap(err, ers.ErrInvalidConfig, ers.StageConfig, "")
 	}
 
-	// Выбор реализации переносим в selectTransport (см. ниже)
-	RtTrans = transport.SelectTransport(hc)
+	// Выбор реализации — в SelectTransport (transport_select.go)
+	tr, err := SelectTransport(hc)
+	if err != nil {
+		return err
+	}
+	setTransport(tr)
 
 	// контекст и запуск
 	ctx, cancel := context.WithCancel(context.Background())
 	if RtTrans != nil {
 		if err := RtTrans.Start(ctx); err != nil {
 			cancel()
-			return err
+			setTransport(nil)
+			return ers.Classify(err, ers.StageHandshake)
+		}
+	}
+	RtConfig = hc
+	// Пробросы портов — после подключения транспорта, через него.
+	if err := ApplyForwarding(hc.Forwarding); err != nil {
+		log.Warn("port forwarding disabled", logpkg.F("err", err))
+		telemetry.EmitErr(err, ers.StageRuntime)
+	}
+	if hc.Metrics.Enabled {
+		if err := telemetry.StartMetricsServer(hc.Metrics.Listen); err != nil {
+			log.Warn("metrics endpoint disabled", logpkg.F("listen", hc.Metrics.Listen), logpkg.F("err", err))
 		}
 	}
+	telemetry.StartRateSampler(time.Duration(hc.Telemetry.TrafficIntervalMs) * time.Millisecond)
 	RtCancel = cancel
 	RtStarted = true
 	RtUptime = time.Now()
@@ -54,15 +109,100 @@ func RuntimeStop() {
 	}
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
+	forward.Stop()
 	if RtTrans != nil {
 		_ = RtTrans.Stop(ctx)
-		RtTrans = nil
+		setTransport(nil)
 	}
 	RtCancel()
+	telemetry.StopMetricsServer()
+	telemetry.StopRateSampler()
+	upstream.Reset()
 	RtStarted = false
+	RtConfig = config.HY2Config{}
 	telemetry.Emit(telemetry.EvtStopped, "{}")
 }
 
+// RuntimeReload применяет текущий конфиг к работающему ядру без полного
+// рестарта: изменения вне ChangeTransport применяются на месте, транспорт
+// переподключается, только если изменились его поля (server/auth/TLS/...).
+// Ошибка разбора конфига — ErrInvalidConfig, ничего не меняется.
+func RuntimeReload() (config.ConfigDiff, error) {
+	hc, err := config.ParseHY2Config()
+	if err != nil {
+		return config.ConfigDiff{}, ers.Wrap(err, ers.ErrInvalidConfig, ers.StageConfig, "")
+	}
+	d := config.Diff(RtConfig, hc)
+	if !RtStarted || d.Empty() {
+		return d, nil
+	}
+	if d.Has(config.ChangeLogging) || d.Has(config.ChangeTransport) || d.Has(config.ChangeInbound) {
+		ApplyLog(hc) // секреты конфига зависят и от транспортных полей
+	}
+	if d.Has(config.ChangePanic) {
+		SetPanicPolicy(hc.Panic.Policy, hc.Panic.MaxRestarts)
+	}
+	if d.Has(config.ChangeRouting) {
+		ApplyRoute(hc.Route)
+	}
+	if d.Has(config.ChangeTransport) {
+		if err := reconnectTransport(hc); err != nil {
+			return d, err
+		}
+	} else if d.Has(config.ChangeForwarding) {
+		if err := ApplyForwarding(hc.Forwarding); err != nil {
+			log.Warn("port forwarding disabled", logpkg.F("err", err))
+			telemetry.EmitErr(err, ers.StageRuntime)
+		}
+	}
+	if d.Has(config.ChangeMetrics) {
+		telemetry.StopMetricsServer()
+		if hc.Metrics.Enabled {
+			if err := telemetry.StartMetricsServer(hc.Metrics.Listen); err != nil {
+				log.Warn("metrics endpoint disabled", logpkg.F("listen", hc.Metrics.Listen), logpkg.F("err", err))
+			}
+		}
+	}
+	if d.Has(config.ChangeTelemetry) {
+		telemetry.StartRateSampler(time.Duration(hc.Telemetry.TrafficIntervalMs) * time.Millisecond)
+	}
+	RtConfig = hc
+	return d, nil
+}
+
+// reconnectTransport заменяет транспорт на собранный по hc; пробросы портов
+// перезапускаются поверх нового.
+func reconnectTransport(hc config.HY2Config) error {
+	tr, err := SelectTransport(hc)
+	if err != nil {
+		return err
+	}
+	if err := ApplyDetour(hc.Detour); err != nil {
+		return ers.Wrap(err, ers.ErrInvalidConfig, ers.StageConfig, "")
+	}
+	forward.Stop()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	if RtTrans != nil {
+		_ = RtTrans.Stop(ctx)
+	}
+	cancel()
+	RtCancel()
+
+	runCtx, runCancel := context.WithCancel(context.Background())
+	RtCancel = runCancel
+	setTransport(tr)
+	if err := tr.Start(runCtx); err != nil {
+		setTransport(nil)
+		return ers.Classify(err, ers.StageHandshake)
+	}
+	if err := ApplyForwarding(hc.Forwarding); err != nil {
+		log.Warn("port forwarding disabled", logpkg.F("err", err))
+		telemetry.EmitErr(err, ers.StageRuntime)
+	}
+	log.Info("transport reconnected after reload", logpkg.F("engine", hc.Engine))
+	return nil
+}
+
 func RuntimeStatusInto(h *telemetry.Health) {
 	if !RtStarted || RtTrans == nil {
 		return
@@ -72,3 +212,115 @@ func RuntimeStatusInto(h *telemetry.Health) {
 		h.QuicRttMs = st.RTTms
 	}
 }
+
+// ApplyLog применяет секцию log и регистрирует секреты конфига для редакции логов.
+// Пустые level/format (поле убрано из конфига при Reload) возвращают
+// значения по умолчанию: info и text.
+func ApplyLog(hc config.HY2Config) {
+	level, format := hc.Log.Level, hc.Log.Format
+	if level == "" {
+		level = "info"
+	}
+	if format == "" {
+		format = logpkg.FormatText
+	}
+	logpkg.SetLogLevel(level)
+	logpkg.SetLogFormat(format)
+	secrets := []string{hc.Password, hc.Inbound.Password, hc.Fallback.Password, hc.Detour.Password, hc.Obfs.Password, hc.WireGuard.PrivateKey}
+	for _, p := range hc.WireGuard.Peers {
+		secrets = append(secrets, p.PresharedKey)
+	}
+	logpkg.SetSecrets("config", secrets...)
+}
+
+// ApplyDetour включает вышестоящий прокси для сокетов транспорта (пустой type — выключает).
+func ApplyDetour(dc config.DetourConfig) error {
+	if dc.Type == "" {
+		upstream.Reset()
+		return nil
+	}
+	return upstream.Set(upstream.Config{
+		Type:     dc.Type,
+		Server:   dc.Server,
+		Username: dc.Username,
+		Password: dc.Password,
+	})
+}
+
+// ApplyForwarding (пере)запускает пробросы портов через текущий транспорт.
+// Пустой список останавливает все пробросы; правило, сеть которого
+// транспорт не несёт (см. transport.CarriesTCP/CarriesUDP), — ошибка
+// forward.ErrNoTunnel, ни один проброс тогда не поднимается.
+func ApplyForwarding(fc []config.ForwardConfig) error {
+	if len(fc) == 0 {
+		forward.Stop()
+		return nil
+	}
+	rules := make([]forward.Rule, 0, len(fc))
+	for _, f := range fc {
+		network := f.Network
+		if network == "" {
+			network = "tcp"
+		}
+		rules = append(rules, forward.Rule{
+			Network:    network,
+			Listen:     f.Listen,
+			Remote:     f.Remote,
+			UDPTimeout: time.Duration(f.UDPTimeoutS) * time.Second,
+		})
+	}
+	var t forward.Tunnel
+	if tr := ActiveTransport(); tr != nil {
+		if transport.CarriesTCP(tr) {
+			t.Dial = TunnelDial
+		}
+		if transport.CarriesUDP(tr) {
+			t.ListenPacket = TunnelListenPacket
+		}
+	}
+	return forward.Start(rules, t)
+}
+
+// ErrNoTunnel — активный транспорт (сейчас) не несёт потоки этой сети:
+// ядро не запущено, у движка нет data plane или fallback на основном
+// транспорте без Dialer.
+var ErrNoTunnel = forward.ErrNoTunnel
+
+// TunnelDial/TunnelListenPacket открывают потоки через активный транспорт
+// (берётся на момент dial, поэтому переживают замену транспорта).
+func TunnelDial(ctx context.Context, network, addr string) (net.Conn, error) {
+	d, ok := ActiveTransport().(transport.Dialer)
+	if !ok {
+		return nil, ErrNoTunnel
+	}
+	c, err := d.DialContext(ctx, network, addr)
+	if errors.Is(err, transport.ErrNoDialer) {
+		return nil, ErrNoTunnel
+	}
+	return c, err
+}
+
+func TunnelListenPacket(ctx context.Context) (net.PacketConn, error) {
+	d, ok := ActiveTransport().(transport.PacketDialer)
+	if !ok {
+		return nil, ErrNoTunnel
+	}
+	pc, err := d.ListenPacket(ctx)
+	if errors.Is(err, transport.ErrNoDialer) {
+		return nil, ErrNoTunnel
+	}
+	return pc, err
+}
+
+// ApplyRoute переносит правила из конфига в роутер потоков.
+func ApplyRoute(rc config.RouteConfig) {
+	rules := make([]routing.Rule, 0, len(rc.Rules))
+	for _, r := range rc.Rules {
+		rules = append(rules, routing.Rule{
+			PackageName: r.PackageName,
+			BundleID:    r.BundleID,
+			Outbound:    r.Outbound,
+		})
+	}
+	routing.SetRules(rules, rc.Final)
+}
@@ -0,0 +1,36 @@
+// go:build android || ios || mobile_skel
+
+package runtime
+
+import (
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/transport"
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/transport/trojan"
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/pkg/config"
+	ers "github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/pkg/errors"
+)
+
+// SelectTransport создаёт транспорт движка hc.Engine из реестра
+// (internal/transport/registry.go) и, если включён fallback, оборачивает
+// его запасным TCP/TLS-транспортом (Trojan). Неизвестный движок —
+// ErrInvalidConfig со списком доступных.
+func SelectTransport(hc config.HY2Config) (transport.Transport, error) {
+	tr, err := transport.SelectTransport(hc)
+	if err != nil {
+		return nil, ers.Wrap(err, ers.ErrInvalidConfig, ers.StageConfig, "")
+	}
+	if !hc.Fallback.Enabled || hc.Engine == "trojan" {
+		// Явный выбор TCP-транспорта: fallback не нужен.
+		return tr, nil
+	}
+	fb := hc.Fallback
+	secondary := trojan.NewTransport(trojan.Config{
+		Server:   fb.Server,
+		Password: fb.Password,
+		SNI:      fb.SNI,
+		Insecure: fb.Insecure,
+	})
+	return transport.NewFallback(tr, secondary, transport.FallbackOptions{
+		Server: hc.Server,
+		After:  fb.AfterUDPFailures,
+	}), nil
+}
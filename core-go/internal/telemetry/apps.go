@@ -0,0 +1,91 @@
+// go:build android || ios || mobile_skel
+
+package telemetry
+
+import (
+	"encoding/json"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// AppStats — счётчики трафика одного приложения (split tunneling).
+type AppStats struct {
+	App      string `json:"app"`
+	BytesIn  uint64 `json:"bytes_in"`
+	BytesOut uint64 `json:"bytes_out"`
+	Flows    uint64 `json:"flows"`
+}
+
+type appCounters struct {
+	in, out, flows atomic.Uint64
+}
+
+var (
+	appMu    sync.RWMutex
+	appStats = map[string]*appCounters{}
+)
+
+func appEntry(app string) *appCounters {
+	appMu.RLock()
+	c := appStats[app]
+	appMu.RUnlock()
+	if c != nil {
+		return c
+	}
+	appMu.Lock()
+	defer appMu.Unlock()
+	if c = appStats[app]; c == nil {
+		c = &appCounters{}
+		appStats[app] = c
+	}
+	return c
+}
+
+// AppFlowOpened учитывает новый поток приложения app. Пустой app игнорируется.
+func AppFlowOpened(app string) {
+	if app == "" {
+		return
+	}
+	appEntry(app).flows.Add(1)
+}
+
+// AddAppBytes добавляет трафик к счётчикам приложения app.
+func AddAppBytes(app string, in, out uint64) {
+	if app == "" || (in == 0 && out == 0) {
+		return
+	}
+	c := appEntry(app)
+	c.in.Add(in)
+	c.out.Add(out)
+}
+
+// AppStatsSnapshot возвращает счётчики всех приложений, отсортированные по имени.
+func AppStatsSnapshot() []AppStats {
+	appMu.RLock()
+	defer appMu.RUnlock()
+	out := make([]AppStats, 0, len(appStats))
+	for app, c := range appStats {
+		out = append(out, AppStats{
+			App:      app,
+			BytesIn:  c.in.Load(),
+			BytesOut: c.out.Load(),
+			Flows:    c.flows.Load(),
+		})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].App < out[j].App })
+	return out
+}
+
+// AppStatsJSON — то же, что AppStatsSnapshot, в виде JSON-массива.
+func AppStatsJSON() string {
+	b, _ := json.Marshal(AppStatsSnapshot())
+	return string(b)
+}
+
+// ResetAppStats очищает per-app счётчики.
+func ResetAppStats() {
+	appMu.Lock()
+	appStats = map[string]*appCounters{}
+	appMu.Unlock()
+}
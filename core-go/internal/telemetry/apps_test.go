@@ -0,0 +1,35 @@
+//go:build mobile_skel
+
+package telemetry
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestAppStats_CountersAndJSON(t *testing.T) {
+	ResetAppStats()
+	defer ResetAppStats()
+
+	AppFlowOpened("org.example.b")
+	AppFlowOpened("org.example.a")
+	AppFlowOpened("") // неизвестный владелец не учитывается
+	AddAppBytes("org.example.a", 100, 10)
+	AddAppBytes("org.example.a", 1, 2)
+	AddAppBytes("", 5, 5)
+
+	var got []AppStats
+	if err := json.Unmarshal([]byte(AppStatsJSON()), &got); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected 2 apps, got %#v", got)
+	}
+	a := got[0]
+	if a.App != "org.example.a" || a.BytesIn != 101 || a.BytesOut != 12 || a.Flows != 1 {
+		t.Fatalf("unexpected stats for app a: %+v", a)
+	}
+	if got[1].App != "org.example.b" || got[1].Flows != 1 {
+		t.Fatalf("unexpected stats for app b: %+v", got[1])
+	}
+}
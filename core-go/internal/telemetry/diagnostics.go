@@ -0,0 +1,177 @@
+// go:build android || ios || mobile_skel
+
+package telemetry
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	goruntime "runtime"
+	"strings"
+	"time"
+
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/netstack/conntrack"
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/routing"
+	logpkg "github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/pkg/logging"
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/pkg/version"
+	sjson "github.com/sagernet/sing/common/json"
+)
+
+// Диагностический бандл — один zip для поддержки. Собирается целиком в Go,
+// поэтому Android и iOS получают одинаковое содержимое:
+//
+//	manifest.json     — версия/сборка SDK, платформа, время генерации
+//	config.json       — текущий конфиг с вырезанными секретами
+//	health.json       — HealthJSON()
+//	transport.json    — статус активного транспорта
+//	events.json       — буфер последних событий (EventsSince(0))
+//	connections.json  — таблица активных потоков
+//	routing.json      — срабатывания правил маршрутизации и per-app трафик
+//	metrics.txt       — снимок OpenMetrics
+//	logs/recent.log   — последние строки лога из памяти
+//	logs/<file>       — файлы файлового лога (если включён)
+
+// DiagnosticsInput — данные, которые знает только вызывающий слой (mobile/runtime).
+type DiagnosticsInput struct {
+	ConfigJSON []byte // сырой конфиг (CfgGet); секреты вырезаются здесь
+	Transport  any    // статус транспорта (nil — не запущен)
+}
+
+// WriteDiagnosticsFile пишет бандл в dir и возвращает путь к zip-файлу.
+func WriteDiagnosticsFile(dir string, in DiagnosticsInput) (string, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", err
+	}
+	name := "hy2-diagnostics-" + time.Now().UTC().Format("20060102-150405") + ".zip"
+	path := filepath.Join(dir, name)
+	tmp, err := os.CreateTemp(dir, name+".*.tmp")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+	if err := WriteDiagnostics(tmp, in); err != nil {
+		_ = tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// WriteDiagnostics пишет zip-бандл в w.
+func WriteDiagnostics(w io.Writer, in DiagnosticsInput) error {
+	zw := zip.NewWriter(w)
+
+	add := func(name string, data []byte) error {
+		f, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: time.Now()})
+		if err != nil {
+			return err
+		}
+		_, err = f.Write(data)
+		return err
+	}
+	addJSON := func(name string, v any) error {
+		b, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			return err
+		}
+		return add(name, b)
+	}
+
+	var metrics strings.Builder
+	WriteMetrics(&metrics)
+
+	steps := []func() error{
+		func() error {
+			return addJSON("manifest.json", map[string]any{
+				"sdk":          version.SdkName,
+				"version":      version.SdkVersion,
+				"engine":       version.EngineID,
+				"build_time":   version.BuildTime,
+				"commit":       version.CommitHash,
+				"go":           goruntime.Version(),
+				"os":           goruntime.GOOS,
+				"arch":         goruntime.GOARCH,
+				"generated_at": time.Now().UTC().Format(time.RFC3339),
+			})
+		},
+		func() error { return add("config.json", RedactConfigJSON(in.ConfigJSON)) },
+		func() error { return add("health.json", []byte(HealthJSON())) },
+		func() error { return addJSON("transport.json", in.Transport) },
+		func() error { return addJSON("events.json", EventsSince(0)) },
+		func() error { return addJSON("connections.json", conntrack.Snapshot()) },
+		func() error {
+			return addJSON("routing.json", map[string]any{
+				"rules": routing.Stats(),
+				"apps":  AppStatsSnapshot(),
+			})
+		},
+		func() error { return add("metrics.txt", []byte(metrics.String())) },
+		func() error {
+			return add("logs/recent.log", []byte(strings.Join(logpkg.RecentLogs(), "\n")+"\n"))
+		},
+	}
+	for _, step := range steps {
+		if err := step(); err != nil {
+			_ = zw.Close()
+			return err
+		}
+	}
+	for _, p := range logpkg.FileLogPaths() {
+		b, err := os.ReadFile(p)
+		if err != nil {
+			continue // файл мог уйти в ротацию/удалиться — не критично
+		}
+		if err := add("logs/"+filepath.Base(p), b); err != nil {
+			_ = zw.Close()
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// RedactConfigJSON возвращает конфиг с вырезанными секретными полями
+// (password, obfs password, auth, ...). Конфиг с комментариями читается
+// расширенным парсером; если он не разбирается — редактируется как текст.
+func RedactConfigJSON(raw []byte) []byte {
+	if len(raw) == 0 {
+		return []byte("{}")
+	}
+	m, err := sjson.UnmarshalExtended[map[string]any](raw)
+	if err != nil {
+		return []byte(logpkg.Redact(string(raw)))
+	}
+	b, err := json.MarshalIndent(redactValue(m), "", "  ")
+	if err != nil {
+		return []byte(logpkg.Redact(string(raw)))
+	}
+	return b
+}
+
+func redactValue(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		for k, val := range t {
+			if logpkg.IsSecretKey(k) {
+				if s, ok := val.(string); !ok || s != "" {
+					t[k] = "***"
+				}
+				continue
+			}
+			t[k] = redactValue(val)
+		}
+	case []any:
+		for i := range t {
+			t[i] = redactValue(t[i])
+		}
+	case string:
+		return logpkg.Redact(t)
+	}
+	return v
+}
@@ -0,0 +1,82 @@
+//go:build mobile_skel
+
+package telemetry
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestRedactConfigJSON(t *testing.T) {
+	raw := []byte(`{
+	  // комментарии допустимы
+	  "server": "hy2.example:443",
+	  "password": "hunter22",
+	  "obfs": {"type": "salamander", "password": "obfs-secret"},
+	  "inbound": {"username": "u", "password": ""},
+	  "outbounds": [{"auth": "tok"}]
+	}`)
+	out := string(RedactConfigJSON(raw))
+	for _, leak := range []string{"hunter22", "obfs-secret", "tok\""} {
+		if strings.Contains(out, leak) {
+			t.Fatalf("secret %q leaked: %s", leak, out)
+		}
+	}
+	var m map[string]any
+	if err := json.Unmarshal([]byte(out), &m); err != nil {
+		t.Fatalf("redacted config is not JSON: %v", err)
+	}
+	if m["server"] != "hy2.example:443" || m["password"] != "***" {
+		t.Fatalf("unexpected redacted config: %v", m)
+	}
+	if in := m["inbound"].(map[string]any); in["password"] != "" {
+		t.Fatalf("empty secrets must stay empty: %v", in)
+	}
+}
+
+func TestWriteDiagnosticsFile(t *testing.T) {
+	Emit("diag_test", `{"ok":true}`)
+	dir := t.TempDir()
+	path, err := WriteDiagnosticsFile(dir, DiagnosticsInput{
+		ConfigJSON: []byte(`{"server":"s:443","password":"hunter22"}`),
+		Transport:  map[string]any{"remote": "s:443"},
+	})
+	if err != nil {
+		t.Fatalf("WriteDiagnosticsFile: %v", err)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	if err != nil {
+		t.Fatalf("invalid zip: %v", err)
+	}
+	files := map[string]string{}
+	for _, f := range zr.File {
+		rc, _ := f.Open()
+		data, _ := io.ReadAll(rc)
+		rc.Close()
+		files[f.Name] = string(data)
+	}
+	for _, name := range []string{"manifest.json", "config.json", "health.json", "transport.json",
+		"events.json", "connections.json", "routing.json", "metrics.txt", "logs/recent.log"} {
+		if _, ok := files[name]; !ok {
+			t.Errorf("bundle lacks %s (has %v)", name, len(files))
+		}
+	}
+	if strings.Contains(files["config.json"], "hunter22") {
+		t.Fatalf("config.json leaks password: %s", files["config.json"])
+	}
+	if !strings.Contains(files["events.json"], "diag_test") {
+		t.Fatalf("events.json lacks recent events: %s", files["events.json"])
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Fatalf("temporary files left in %s: %v", dir, entries)
+	}
+}
@@ -0,0 +1,242 @@
+//go:build mobile_skel
+
+package telemetry
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	ers "github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/pkg/errors"
+)
+
+func TestEventBus_SeqAndReplay(t *testing.T) {
+	SetEventSink(nil)
+	base := LastEventSeq()
+
+	Emit("a", `{"n":1}`)
+	Emit("b", "not-json")
+	Emit("c", "{}")
+
+	evs := EventsSince(base)
+	if len(evs) != 3 {
+		t.Fatalf("expected 3 events since %d, got %#v", base, evs)
+	}
+	for i, ev := range evs {
+		if ev.Seq != base+uint64(i)+1 || ev.Ts == 0 {
+			t.Fatalf("event[%d] has unexpected seq/ts: %+v", i, ev)
+		}
+	}
+	if got := EventsSince(base + 2); len(got) != 1 || got[0].Name != "c" {
+		t.Fatalf("EventsSince(base+2) = %#v", got)
+	}
+
+	var raw []map[string]any
+	if err := json.Unmarshal([]byte(EventsSinceJSON(base)), &raw); err != nil {
+		t.Fatalf("invalid EventsSinceJSON: %v", err)
+	}
+	if d, _ := raw[0]["data"].(map[string]any); d["n"] != float64(1) {
+		t.Fatalf("JSON payload must be embedded as object: %#v", raw[0])
+	}
+	if raw[1]["data"] != "not-json" {
+		t.Fatalf("non-JSON payload must be a string: %#v", raw[1])
+	}
+}
+
+func TestEventBus_RingBufferBounded(t *testing.T) {
+	SetEventSink(nil)
+	base := LastEventSeq()
+	for i := 0; i < EventBufferSize+10; i++ {
+		Emit("x", "{}")
+	}
+	evs := EventsSince(base)
+	if len(evs) != EventBufferSize {
+		t.Fatalf("expected %d buffered events, got %d", EventBufferSize, len(evs))
+	}
+	if evs[0].Seq != base+11 || evs[len(evs)-1].Seq != LastEventSeq() {
+		t.Fatalf("unexpected window: first=%d last=%d", evs[0].Seq, evs[len(evs)-1].Seq)
+	}
+	FlushEvents(time.Second)
+}
+
+type seqSink struct {
+	mu   sync.Mutex
+	seqs []int64
+}
+
+func (s *seqSink) OnEvent(name, data string) {}
+
+func (s *seqSink) OnSeqEvent(seq, ts int64, name, data string) {
+	s.mu.Lock()
+	s.seqs = append(s.seqs, seq)
+	s.mu.Unlock()
+}
+
+func TestEventBus_AsyncOrderedDelivery(t *testing.T) {
+	block := make(chan struct{})
+	SetEventSink(EventSinkFunc(func(name, data string) { <-block }))
+
+	// медленный sink не должен блокировать Emit
+	done := make(chan struct{})
+	go func() {
+		Emit("slow", "{}")
+		Emit("slow", "{}")
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Emit blocked on a slow sink")
+	}
+	close(block)
+	FlushEvents(time.Second)
+
+	s := &seqSink{}
+	SetEventSink(s)
+	defer SetEventSink(nil)
+	for i := 0; i < 50; i++ {
+		Emit("seq", "{}")
+	}
+	if !FlushEvents(time.Second) {
+		t.Fatal("FlushEvents timed out")
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.seqs) != 50 {
+		t.Fatalf("expected 50 deliveries, got %d", len(s.seqs))
+	}
+	for i := 1; i < len(s.seqs); i++ {
+		if s.seqs[i] != s.seqs[i-1]+1 {
+			t.Fatalf("deliveries out of order: %v", s.seqs)
+		}
+	}
+}
+
+func TestEventBus_SinkPanicDoesNotStopBus(t *testing.T) {
+	SetEventSink(EventSinkFunc(func(name, data string) { panic("sink") }))
+	Emit("boom", "{}")
+	FlushEvents(time.Second)
+
+	got := make(chan string, 1)
+	SetEventSink(EventSinkFunc(func(name, data string) { got <- name }))
+	defer SetEventSink(nil)
+	Emit("after", "{}")
+	select {
+	case n := <-got:
+		if n != "after" {
+			t.Fatalf("unexpected event %q", n)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("bus stopped after sink panic")
+	}
+}
+
+func TestEventBus_MultipleSubscribersWithFilter(t *testing.T) {
+	var mu sync.Mutex
+	var all, errs, legacy []string
+	rec := func(dst *[]string) EventSinkFunc {
+		return func(name, data string) {
+			mu.Lock()
+			*dst = append(*dst, name)
+			mu.Unlock()
+		}
+	}
+	idAll := SubscribeEvents(rec(&all))
+	idErr := SubscribeEvents(rec(&errs), EvtError)
+	defer UnsubscribeEvents(idErr)
+	SetEventSink(rec(&legacy))
+	defer SetEventSink(nil)
+
+	Emit(EvtStarted, "{}")
+	EmitError(1, "x")
+	FlushEvents(time.Second)
+
+	mu.Lock()
+	if len(all) != 2 || len(legacy) != 2 || len(errs) != 1 || errs[0] != EvtError {
+		t.Fatalf("unexpected deliveries: all=%v errs=%v legacy=%v", all, errs, legacy)
+	}
+	mu.Unlock()
+
+	if !UnsubscribeEvents(idAll) || UnsubscribeEvents(idAll) {
+		t.Fatal("UnsubscribeEvents must succeed exactly once")
+	}
+	SetEventSink(nil) // снимает только legacy-подписку
+	Emit(EvtError, "{}")
+	FlushEvents(time.Second)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(all) != 2 || len(legacy) != 2 || len(errs) != 2 {
+		t.Fatalf("unexpected deliveries after unsubscribe: all=%v errs=%v legacy=%v", all, errs, legacy)
+	}
+}
+
+func TestEventBus_SlowSubscriberIsolated(t *testing.T) {
+	block, entered := make(chan struct{}), make(chan struct{}, 1)
+	idSlow := SubscribeEvents(EventSinkFunc(func(name, data string) {
+		select {
+		case entered <- struct{}{}:
+		default:
+		}
+		<-block
+	}))
+	defer UnsubscribeEvents(idSlow)
+	got := make(chan string, eventQueueSize+10)
+	idFast := SubscribeEvents(EventSinkFunc(func(name, data string) { got <- name }))
+	defer UnsubscribeEvents(idFast)
+
+	// Медленный подписчик висит на первом событии — быстрый получает своё сразу.
+	Emit("first", "{}")
+	select {
+	case n := <-got:
+		if n != "first" {
+			t.Fatalf("unexpected event %q", n)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("slow subscriber delayed another one")
+	}
+	<-entered
+
+	// Очередь медленного подписчика переполняется: ему не доставляются ровно
+	// 5 событий; быстрый теряет только то, что не успел разобрать сам.
+	const burst = eventQueueSize + 5
+	dropped := EventsDropped.Load()
+	for i := 0; i < burst; i++ {
+		Emit("burst", "{}")
+	}
+	d := EventsDropped.Load() - dropped
+	close(block)
+	if !FlushEvents(2 * time.Second) {
+		t.Fatal("FlushEvents timed out")
+	}
+	if d < 5 || uint64(len(got))+d-5 != burst {
+		t.Fatalf("dropped %d, fast subscriber got %d of %d", d, len(got), burst)
+	}
+}
+
+func TestEmitErr_ClassifiedPayload(t *testing.T) {
+	base := LastEventSeq()
+	EmitErr(ers.Wrap(errors.New("refused"), ers.ErrServerUnreachable, ers.StageDial, "hy2.example:443"), ers.StageRuntime)
+	EmitErr(nil, ers.StageRuntime) // nil — не событие
+
+	evs := EventsSince(base)
+	if len(evs) != 1 || evs[0].Name != EvtError {
+		t.Fatalf("expected one error event, got %#v", evs)
+	}
+	var p struct {
+		Code      int    `json:"code"`
+		Name      string `json:"name"`
+		Stage     string `json:"stage"`
+		Retryable bool   `json:"retryable"`
+		Msg       string `json:"msg"`
+	}
+	if err := json.Unmarshal([]byte(evs[0].Data), &p); err != nil {
+		t.Fatalf("invalid payload: %v", err)
+	}
+	if p.Code != int(ers.ErrServerUnreachable) || p.Name != "server_unreachable" || p.Stage != "dial" ||
+		!p.Retryable || p.Msg != "hy2.example:443: refused" {
+		t.Fatalf("unexpected payload: %+v", p)
+	}
+}
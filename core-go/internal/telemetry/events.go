@@ -11,7 +11,15 @@
 // События используются внутри api.go, lifecycle.go и будущих модулей (HY2 runtime).
 package telemetry
 
-import "fmt"
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	ers "github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/pkg/errors"
+)
 
 // EventSink — интерфейс для передачи событий из Go в Kotlin/Swift.
 // Реализуется на стороне платформенного кода (например, в Kotlin SDK).
@@ -35,24 +43,142 @@ type EventSink interface{ OnEvent(name, data string) }
 
 func (f EventSinkFunc) OnEvent(name, payload string) { f(name, payload) }
 
+// SeqEventSink — опциональное расширение EventSink: если sink его реализует,
+// вместо OnEvent вызывается OnSeqEvent с порядковым номером и временем события
+// (удобно для дедупликации с EventsSince после холодного старта).
+type SeqEventSink interface {
+	OnSeqEvent(seq, tsMs int64, name, data string)
+}
+
+// Event — событие шины: монотонный Seq, время (unix ms), имя и JSON-полезная нагрузка.
+type Event struct {
+	Seq  uint64
+	Ts   int64
+	Name string
+	Data string
+}
+
+// MarshalJSON отдаёт Data как вложенный JSON (или строкой, если это не JSON).
+func (e Event) MarshalJSON() ([]byte, error) {
+	data := json.RawMessage(e.Data)
+	if !json.Valid(data) {
+		data, _ = json.Marshal(e.Data)
+	}
+	return json.Marshal(struct {
+		Seq  uint64          `json:"seq"`
+		Ts   int64           `json:"ts"`
+		Name string          `json:"name"`
+		Data json.RawMessage `json:"data"`
+	}{e.Seq, e.Ts, e.Name, data})
+}
+
+// EventBufferSize — сколько последних событий хранит кольцевой буфер для EventsSince.
+const EventBufferSize = 256
+
+// eventQueueSize — ёмкость очереди доставки подписчика; при переполнении
+// событие не доставляется этому подписчику (но остаётся в буфере) и
+// учитывается в EventsDropped.
+const eventQueueSize = 1024
+
+type queuedEvent struct {
+	ev    Event
+	flush chan struct{} // маркер FlushEvents
+}
+
+// eventSub — подписчик шины; names — фильтр по именам событий (nil — все).
+// У каждого подписчика своя очередь и горутина доставки: медленный sink
+// не задерживает остальных. stop закрывается при отписке.
+type eventSub struct {
+	id    int64
+	sink  EventSink
+	names map[string]bool
+	queue chan queuedEvent
+	stop  chan struct{}
+}
+
 var (
-	// evt — текущий зарегистрированный EventSink.
-	// Если не установлен, события будут проигнорированы.
-	evt EventSink
+	evtMu     sync.Mutex
+	evtSubs   []*eventSub // подписчики в порядке регистрации; пусто — события только копятся в буфере
+	evtNextID int64
+	evtLegacy int64 // id подписки, созданной через SetEventSink
+	evtSeq    uint64
+	evtRing   [EventBufferSize]Event
+
+	// EventsDropped — доставки, пропущенные из-за переполнения очереди подписчика.
+	EventsDropped atomic.Uint64
 )
 
+// SubscribeEvents регистрирует ещё одного подписчика на события и возвращает
+// его id (для UnsubscribeEvents). names — фильтр по именам событий;
+// пустой список — все события. Каждый подписчик получает события в
+// порядке Seq из своей горутины доставки; если он не успевает и его
+// очередь переполнена, новые события ему не доставляются (EventsDropped).
+func SubscribeEvents(s EventSink, names ...string) int64 {
+	if s == nil {
+		return 0
+	}
+	var filter map[string]bool
+	for _, n := range names {
+		if n == "" {
+			continue
+		}
+		if filter == nil {
+			filter = map[string]bool{}
+		}
+		filter[n] = true
+	}
+	sub := &eventSub{sink: s, names: filter, queue: make(chan queuedEvent, eventQueueSize), stop: make(chan struct{})}
+	go sub.loop()
+	evtMu.Lock()
+	defer evtMu.Unlock()
+	evtNextID++
+	sub.id = evtNextID
+	evtSubs = append(evtSubs, sub)
+	return sub.id
+}
+
+// UnsubscribeEvents снимает подписку id. Возвращает false, если её уже нет.
+func UnsubscribeEvents(id int64) bool {
+	evtMu.Lock()
+	defer evtMu.Unlock()
+	return unsubscribeEventsLocked(id)
+}
+
+func unsubscribeEventsLocked(id int64) bool {
+	for i, sub := range evtSubs {
+		if sub.id == id {
+			evtSubs = append(evtSubs[:i:i], evtSubs[i+1:]...)
+			close(sub.stop)
+			return true
+		}
+	}
+	return false
+}
+
 // SetEventSink регистрирует внешний обработчик событий SDK.
 // Обычно вызывается из Kotlin/Swift после инициализации ядра.
+// Совместимая обёртка над SubscribeEvents: заменяет только подписку,
+// созданную предыдущим SetEventSink, остальных подписчиков не трогает.
+// События, отправленные до регистрации, можно дочитать через EventsSince(0).
 //
 // Аргументы:
-//   - s — объект, реализующий интерфейс EventSink.
-//
-// Побочные эффекты:
-//   - сохраняет ссылку в глобальную переменную evt.
-func SetEventSink(s EventSink) { evt = s }
+//   - s — объект, реализующий интерфейс EventSink (nil — отписаться).
+func SetEventSink(s EventSink) {
+	evtMu.Lock()
+	unsubscribeEventsLocked(evtLegacy)
+	evtLegacy = 0
+	evtMu.Unlock()
+	if s != nil {
+		id := SubscribeEvents(s)
+		evtMu.Lock()
+		evtLegacy = id
+		evtMu.Unlock()
+	}
+}
 
-// emit — внутренняя функция для отправки события (если EventSink установлен).
-// Используется другими модулями SDK (api.go, lifecycle.go, logging.go).
+// Emit публикует событие в шину: присваивает Seq/Ts, кладёт в кольцевой буфер
+// и ставит в очереди доставки подписчиков. Вызывающий не блокируется
+// медленным sink'ом; каждому подписчику события приходят в порядке Seq.
 //
 // Аргументы:
 //   - name — идентификатор события (строка);
@@ -60,9 +186,107 @@ func SetEventSink(s EventSink) { evt = s }
 //
 // Безопасна для вызова из любых горутин.
 func Emit(name, data string) {
-	if evt != nil {
-		evt.OnEvent(name, data)
+	evtMu.Lock()
+	defer evtMu.Unlock()
+	evtSeq++
+	ev := Event{Seq: evtSeq, Ts: time.Now().UnixMilli(), Name: name, Data: data}
+	evtRing[ev.Seq%EventBufferSize] = ev
+	for _, sub := range evtSubs {
+		if sub.names != nil && !sub.names[name] {
+			continue
+		}
+		select {
+		case sub.queue <- queuedEvent{ev: ev}:
+		default:
+			EventsDropped.Add(1)
+		}
+	}
+}
+
+// EventsSince возвращает события с Seq > seq, ещё живые в буфере, по возрастанию Seq.
+func EventsSince(seq uint64) []Event {
+	evtMu.Lock()
+	defer evtMu.Unlock()
+	from := seq + 1
+	if evtSeq >= EventBufferSize && from <= evtSeq-EventBufferSize {
+		from = evtSeq - EventBufferSize + 1
+	}
+	out := []Event{}
+	for s := from; s <= evtSeq; s++ {
+		out = append(out, evtRing[s%EventBufferSize])
+	}
+	return out
+}
+
+// EventsSinceJSON — EventsSince в виде JSON-массива:
+// [{"seq":12,"ts":1730000000000,"name":"started","data":{}}, ...]
+func EventsSinceJSON(seq uint64) string {
+	b, _ := json.Marshal(EventsSince(seq))
+	return string(b)
+}
+
+// LastEventSeq — номер последнего опубликованного события (0 — событий не было).
+func LastEventSeq() uint64 {
+	evtMu.Lock()
+	defer evtMu.Unlock()
+	return evtSeq
+}
+
+// FlushEvents ждёт, пока все ранее опубликованные события будут доставлены
+// текущим подписчикам. Возвращает false по таймауту.
+func FlushEvents(timeout time.Duration) bool {
+	evtMu.Lock()
+	subs := append([]*eventSub(nil), evtSubs...)
+	evtMu.Unlock()
+
+	t := time.NewTimer(timeout)
+	defer t.Stop()
+	marks := make([]chan struct{}, len(subs))
+	for i, sub := range subs {
+		marks[i] = make(chan struct{})
+		select {
+		case sub.queue <- queuedEvent{flush: marks[i]}:
+		case <-sub.stop:
+			close(marks[i]) // отписан — ждать нечего
+		case <-t.C:
+			return false
+		}
+	}
+	for i, sub := range subs {
+		select {
+		case <-marks[i]:
+		case <-sub.stop:
+		case <-t.C:
+			return false
+		}
 	}
+	return true
+}
+
+// loop — горутина доставки событий подписчику; завершается при отписке.
+func (s *eventSub) loop() {
+	for {
+		select {
+		case q := <-s.queue:
+			if q.flush != nil {
+				close(q.flush)
+				continue
+			}
+			deliverEvent(s.sink, q.ev)
+		case <-s.stop:
+			return
+		}
+	}
+}
+
+// deliverEvent вызывает sink; паника в платформенном коде не роняет шину.
+func deliverEvent(s EventSink, ev Event) {
+	defer func() { _ = recover() }()
+	if ss, ok := s.(SeqEventSink); ok {
+		ss.OnSeqEvent(int64(ev.Seq), ev.Ts, ev.Name, ev.Data)
+		return
+	}
+	s.OnEvent(ev.Name, ev.Data)
 }
 
 // ===============================
@@ -80,6 +304,8 @@ const (
 	EvtReconnect    = "reconnect" // переподключение / попытка восстановления
 	EvtReconnecting = "reconnecting"
 	EvtReconnected  = "reconnected"
+	// EvtTransportSwitched — переключение основной/запасной транспорт (UDP заблокирован / восстановлен).
+	EvtTransportSwitched = "transport_switched"
 )
 
 type evtReconnecting struct {
@@ -108,6 +334,25 @@ func EmitError(code int, msg string) {
 	Emit(EvtError, fmt.Sprintf(`{"code":%d,"msg":%q}`, code, msg))
 }
 
+// EmitErr — событие об ошибке с полной классификацией (pkg/errors):
+//
+//	{"code":10,"name":"server_unreachable","message":"...","stage":"dial",
+//	 "retryable":true,"msg":"..."}
+//
+// Поле msg дублирует message для совместимости с EmitError.
+// stage — этап по умолчанию, если его не удалось определить из err.
+func EmitErr(err error, stage ers.Stage) {
+	if err == nil {
+		return
+	}
+	me := ers.Classify(err, stage).Mobile()
+	b, _ := json.Marshal(struct {
+		ers.MobileError
+		Msg string `json:"msg"`
+	}{me, me.Message})
+	Emit(EvtError, string(b))
+}
+
 // emitState — отправляет простые служебные события
 // (например, started, stopped, reloaded).
 //
@@ -5,6 +5,7 @@ package telemetry
 import (
 	"encoding/json"
 	"testing"
+	"time"
 )
 
 /********* helpers *********/
@@ -33,6 +34,7 @@ func TestSetEventSinkAndEmit(t *testing.T) {
 	SetEventSink(m)
 
 	emit("started", `{"ok":true}`)
+	FlushEvents(time.Second)
 	if len(m.received) != 1 {
 		t.Fatalf("expected 1 event, got %d", len(m.received))
 	}
@@ -63,6 +65,7 @@ func TestEmitStateConstants(t *testing.T) {
 	emitState(EvtStarted)
 	emitState(EvtStopped)
 	emitState(EvtReloaded)
+	FlushEvents(time.Second)
 
 	if len(m.received) != 3 {
 		t.Fatalf("expected 3 events, got %d", len(m.received))
@@ -84,6 +87,7 @@ func TestEmitErrorPayload(t *testing.T) {
 	SetEventSink(m)
 
 	emitError(42, "connection failed")
+	FlushEvents(time.Second)
 	if len(m.received) != 1 {
 		t.Fatalf("expected 1 event, got %d", len(m.received))
 	}
@@ -50,6 +50,14 @@ type Health struct {
 	ALPN          string `json:"alpn,omitempty"`
 	LastBackoffMs int64  `json:"last_backoff_ms"`
 	LastErrorTs   int64  `json:"last_error_ts"`
+
+	// Latency — сводки гистограмм задержек: rtt, handshake, reconnect_downtime, dial.
+	Latency map[string]LatencySummary `json:"latency,omitempty"`
+	// Rates — скорости upload/download (бит/с) по окнам 1s/10s/60s и пики сессии.
+	Rates *Rates `json:"rates,omitempty"`
+	// Panics — число перехваченных паник; LastPanic — последняя из них (со стеком).
+	Panics    uint64     `json:"panics,omitempty"`
+	LastPanic *PanicInfo `json:"last_panic,omitempty"`
 }
 
 // Глобальные счётчики (обновляются в tun2socks)
@@ -136,6 +144,14 @@ func HealthJSON() string {
 	}
 	h.LastBackoffMs = LastBackoffMs.Load()
 	h.LastErrorTs = LastErrTs.Load()
+	if lat := LatencySummaries(); len(lat) > 0 {
+		h.Latency = lat
+	}
+	if r, ok := CurrentRates(); ok {
+		h.Rates = &r
+	}
+	h.Panics = Panics.Load()
+	h.LastPanic = LastPanic()
 	if su := StartUnix.Load(); su > 0 {
 		now := time.Now().Unix()
 		if now > su {
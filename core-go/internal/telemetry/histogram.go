@@ -0,0 +1,109 @@
+// go:build android || ios || mobile_skel
+
+package telemetry
+
+import "sync"
+
+// Histogram — гистограмма с фиксированными границами бакетов (в миллисекундах).
+// Память ограничена числом бакетов; в Observe линейный поиск — бакетов мало.
+type Histogram struct {
+	mu     sync.Mutex
+	bounds []float64 // верхние границы бакетов (включительно), по возрастанию
+	counts []uint64  // len(bounds)+1, последний — +Inf
+	sum    float64
+	count  uint64
+	max    float64
+}
+
+// LatencyBucketsMs — бакеты по умолчанию для сетевых задержек.
+var LatencyBucketsMs = []float64{5, 10, 25, 50, 100, 250, 500, 1000, 2500, 5000, 10000}
+
+// NewHistogram создаёт гистограмму с заданными границами (мс).
+func NewHistogram(bounds []float64) *Histogram {
+	b := append([]float64(nil), bounds...)
+	return &Histogram{bounds: b, counts: make([]uint64, len(b)+1)}
+}
+
+// Observe добавляет значение v (мс).
+func (h *Histogram) Observe(v float64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	i := 0
+	for i < len(h.bounds) && v > h.bounds[i] {
+		i++
+	}
+	h.counts[i]++
+	h.sum += v
+	h.count++
+	if v > h.max {
+		h.max = v
+	}
+}
+
+// Reset обнуляет гистограмму.
+func (h *Histogram) Reset() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for i := range h.counts {
+		h.counts[i] = 0
+	}
+	h.sum = 0
+	h.count = 0
+	h.max = 0
+}
+
+// HistogramSnapshot — согласованный снимок гистограммы.
+// Cumulative[i] — число наблюдений <= Bounds[i]; последний элемент — +Inf (= Count).
+type HistogramSnapshot struct {
+	Bounds     []float64
+	Cumulative []uint64
+	Sum        float64
+	Count      uint64
+	Max        float64
+}
+
+// Snapshot возвращает кумулятивный снимок гистограммы.
+func (h *Histogram) Snapshot() HistogramSnapshot {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	s := HistogramSnapshot{
+		Bounds:     append([]float64(nil), h.bounds...),
+		Cumulative: make([]uint64, len(h.counts)),
+		Sum:        h.sum,
+		Count:      h.count,
+		Max:        h.max,
+	}
+	var acc uint64
+	for i, c := range h.counts {
+		acc += c
+		s.Cumulative[i] = acc
+	}
+	return s
+}
+
+// Quantile оценивает q-квантиль (0..1) линейной интерполяцией внутри бакета.
+// Для бакета +Inf верхней границей считается максимальное наблюдение.
+func (s HistogramSnapshot) Quantile(q float64) float64 {
+	if s.Count == 0 {
+		return 0
+	}
+	rank := q * float64(s.Count)
+	var prev uint64
+	lo := 0.0
+	for i, cum := range s.Cumulative {
+		hi := s.Max
+		if i < len(s.Bounds) {
+			hi = s.Bounds[i]
+		}
+		if float64(cum) >= rank && cum > prev {
+			if hi > s.Max {
+				hi = s.Max
+			}
+			frac := (rank - float64(prev)) / float64(cum-prev)
+			return lo + (hi-lo)*frac
+		}
+		prev = cum
+		lo = hi
+	}
+	return s.Max
+}
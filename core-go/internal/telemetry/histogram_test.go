@@ -0,0 +1,91 @@
+//go:build mobile_skel
+
+package telemetry
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestHistogram_Quantile(t *testing.T) {
+	h := NewHistogram([]float64{10, 100})
+	for i := 0; i < 90; i++ {
+		h.Observe(5)
+	}
+	for i := 0; i < 9; i++ {
+		h.Observe(50)
+	}
+	h.Observe(400)
+
+	s := h.Snapshot()
+	if s.Count != 100 || s.Max != 400 {
+		t.Fatalf("unexpected snapshot: %+v", s)
+	}
+	if p50 := s.Quantile(0.5); p50 <= 0 || p50 > 10 {
+		t.Fatalf("p50 = %v, want within (0,10]", p50)
+	}
+	if p99 := s.Quantile(0.99); p99 <= 10 || p99 > 100 {
+		t.Fatalf("p99 = %v, want within (10,100]", p99)
+	}
+	if p100 := s.Quantile(1); p100 != 400 {
+		t.Fatalf("p100 = %v, want max 400", p100)
+	}
+}
+
+func TestHistogram_SummaryAndReset(t *testing.T) {
+	h := NewHistogram(LatencyBucketsMs)
+	if s := h.Summary(); s.Count != 0 {
+		t.Fatalf("empty summary expected, got %+v", s)
+	}
+	h.Observe(20)
+	h.Observe(40)
+	s := h.Summary()
+	if s.Count != 2 || s.AvgMs != 30 || s.MaxMs != 40 {
+		t.Fatalf("unexpected summary: %+v", s)
+	}
+	if s.P50Ms > s.P90Ms || s.P90Ms > s.P99Ms || s.P99Ms > s.MaxMs {
+		t.Fatalf("percentiles must be monotonic: %+v", s)
+	}
+	h.Reset()
+	if s := h.Summary(); s.Count != 0 || s.MaxMs != 0 {
+		t.Fatalf("summary after reset: %+v", s)
+	}
+}
+
+func TestTransportConnectResult_FeedsLatency(t *testing.T) {
+	ResetLatency()
+	defer ResetLatency()
+
+	TransportConnectResult("srv:443", time.Now().Add(-30*time.Millisecond), 42, nil)
+	TransportConnectResult("srv:443", time.Now(), 0, errTest{})
+	ObserveDowntime(time.Now().Add(-2 * time.Second))
+	ObserveDowntime(time.Time{}) // не задано — не учитывается
+
+	if QuicRttMs.Load() != 42 {
+		t.Fatalf("QuicRttMs = %d, want 42", QuicRttMs.Load())
+	}
+	sum := LatencySummaries()
+	if sum["rtt"].Count != 1 || sum["handshake"].Count != 1 || sum["reconnect_downtime"].Count != 1 {
+		t.Fatalf("unexpected summaries: %+v", sum)
+	}
+	if hs := sum["handshake"].MaxMs; hs < 30 || math.IsNaN(hs) {
+		t.Fatalf("handshake max = %v, want >= 30", hs)
+	}
+	if _, ok := sum["dial"]; ok {
+		t.Fatal("empty dial histogram must be omitted")
+	}
+
+	var h Health
+	if err := json.Unmarshal([]byte(HealthJSON()), &h); err != nil {
+		t.Fatalf("invalid HealthJSON: %v", err)
+	}
+	if h.Latency["rtt"].P50Ms == 0 {
+		t.Fatalf("HealthJSON must include latency summaries: %+v", h.Latency)
+	}
+}
+
+type errTest struct{}
+
+func (errTest) Error() string { return "test" }
@@ -0,0 +1,103 @@
+// go:build android || ios || mobile_skel
+
+package telemetry
+
+import (
+	"math"
+	"time"
+)
+
+// Гистограммы задержек. Health.QuicRttMs — только последнее значение;
+// здесь копится распределение, чтобы видеть хвосты (p90/p99).
+var (
+	// RttLatency — RTT-пробы транспорта.
+	RttLatency = NewHistogram(LatencyBucketsMs)
+	// HandshakeLatency — длительность (пере)подключения транспорта (StartOnce).
+	HandshakeLatency = NewHistogram(LatencyBucketsMs)
+	// ReconnectDowntime — время от потери соединения до успешного reconnect.
+	ReconnectDowntime = NewHistogram([]float64{100, 250, 500, 1000, 2500, 5000, 10000, 30000, 60000, 120000, 300000})
+	// DialLatency — задержка исходящих соединений инбаундов (SOCKS/HTTP/TUN).
+	DialLatency = NewHistogram(LatencyBucketsMs)
+)
+
+// LatencySummary — сводка по гистограмме для HealthJSON.
+type LatencySummary struct {
+	Count uint64  `json:"count"`
+	AvgMs float64 `json:"avg_ms"`
+	P50Ms float64 `json:"p50_ms"`
+	P90Ms float64 `json:"p90_ms"`
+	P99Ms float64 `json:"p99_ms"`
+	MaxMs float64 `json:"max_ms"`
+}
+
+// ObserveRTT сохраняет последний RTT (QuicRttMs) и добавляет его в RttLatency.
+func ObserveRTT(ms int64) {
+	if ms <= 0 {
+		return
+	}
+	QuicRttMs.Store(ms)
+	RttLatency.Observe(float64(ms))
+}
+
+// TransportConnectResult учитывает результат (пере)подключения транспорта:
+// per-server счётчики, а при успехе — длительность рукопожатия и RTT.
+func TransportConnectResult(server string, started time.Time, rttMs int64, err error) {
+	ServerConnectResult(server, err == nil)
+	if err != nil {
+		return
+	}
+	HandshakeLatency.Observe(MsSince(started))
+	ObserveRTT(rttMs)
+}
+
+// ObserveDowntime учитывает простой от lostAt до текущего момента (если lostAt задан).
+func ObserveDowntime(lostAt time.Time) {
+	if !lostAt.IsZero() {
+		ReconnectDowntime.Observe(MsSince(lostAt))
+	}
+}
+
+// MsSince — прошедшее с t время в миллисекундах (дробное).
+func MsSince(t time.Time) float64 { return float64(time.Since(t).Microseconds()) / 1000 }
+
+// Summary сворачивает гистограмму в count/avg/p50/p90/p99/max (мс, 0.1 точность).
+func (h *Histogram) Summary() LatencySummary {
+	s := h.Snapshot()
+	if s.Count == 0 {
+		return LatencySummary{}
+	}
+	return LatencySummary{
+		Count: s.Count,
+		AvgMs: round1(s.Sum / float64(s.Count)),
+		P50Ms: round1(s.Quantile(0.50)),
+		P90Ms: round1(s.Quantile(0.90)),
+		P99Ms: round1(s.Quantile(0.99)),
+		MaxMs: round1(s.Max),
+	}
+}
+
+// LatencySummaries возвращает сводки всех непустых гистограмм задержек.
+func LatencySummaries() map[string]LatencySummary {
+	out := map[string]LatencySummary{}
+	for name, h := range map[string]*Histogram{
+		"rtt":                RttLatency,
+		"handshake":          HandshakeLatency,
+		"reconnect_downtime": ReconnectDowntime,
+		"dial":               DialLatency,
+	} {
+		if s := h.Summary(); s.Count > 0 {
+			out[name] = s
+		}
+	}
+	return out
+}
+
+// ResetLatency обнуляет все гистограммы задержек (без рестарта ядра).
+func ResetLatency() {
+	RttLatency.Reset()
+	HandshakeLatency.Reset()
+	ReconnectDowntime.Reset()
+	DialLatency.Reset()
+}
+
+func round1(v float64) float64 { return math.Round(v*10) / 10 }
@@ -0,0 +1,177 @@
+// go:build android || ios || mobile_skel
+
+package telemetry
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/netstack/conntrack"
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/routing"
+)
+
+// Метрики ядра в формате OpenMetrics (text/1.0.0) на loopback-эндпоинте /metrics.
+// Предназначено для desktop/тестовых сборок и dogfood-устройств;
+// включается секцией "metrics" в конфиге (см. runtime.RuntimeStart).
+
+const openMetricsContentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+
+var (
+	metricsMu  sync.Mutex
+	metricsSrv *http.Server
+	metricsLn  net.Listener
+)
+
+// StartMetricsServer поднимает HTTP-эндпоинт /metrics на addr (host:port).
+// Повторный вызов при запущенном сервере — no-op.
+func StartMetricsServer(addr string) error {
+	metricsMu.Lock()
+	defer metricsMu.Unlock()
+	if metricsSrv != nil {
+		return nil
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", openMetricsContentType)
+		WriteMetrics(w)
+	})
+	srv := &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+	metricsSrv, metricsLn = srv, ln
+	go func() { _ = srv.Serve(ln) }()
+	Emit("metrics_started", fmt.Sprintf(`{"addr":%q}`, ln.Addr().String()))
+	return nil
+}
+
+// StopMetricsServer останавливает эндпоинт (если запущен).
+func StopMetricsServer() {
+	metricsMu.Lock()
+	defer metricsMu.Unlock()
+	if metricsSrv == nil {
+		return
+	}
+	_ = metricsSrv.Close()
+	metricsSrv, metricsLn = nil, nil
+}
+
+// MetricsAddr — фактический адрес эндпоинта или "" если выключен.
+func MetricsAddr() string {
+	metricsMu.Lock()
+	defer metricsMu.Unlock()
+	if metricsLn == nil {
+		return ""
+	}
+	return metricsLn.Addr().String()
+}
+
+// WriteMetrics пишет все метрики ядра в w в формате OpenMetrics.
+func WriteMetrics(w io.Writer) {
+	bw := bufio.NewWriter(w)
+	defer bw.Flush()
+	m := metricsWriter{w: bw}
+
+	up := 0.0
+	var uptime int64
+	if su := StartUnix.Load(); su > 0 {
+		up = 1
+		uptime = time.Now().Unix() - su
+	}
+	m.gauge("hy2_up", "1 if the core is running", up)
+	m.gauge("hy2_uptime_seconds", "Seconds since the core was started", float64(uptime))
+
+	in, out := BytesStats()
+	m.counter("hy2_received_bytes", "Bytes received from remote peers", float64(in))
+	m.counter("hy2_sent_bytes", "Bytes sent to remote peers", float64(out))
+	m.counter("hy2_reconnects", "Transport reconnects", float64(Reconnects.Load()))
+
+	m.gauge("hy2_quic_rtt_seconds", "Last measured QUIC RTT", ms(QuicRttMs.Load()))
+	m.gauge("hy2_last_backoff_seconds", "Last reconnect backoff delay", ms(LastBackoffMs.Load()))
+	m.gauge("hy2_last_error_timestamp_seconds", "Unix time of the last transport error", float64(LastErrTs.Load()))
+
+	m.gauge("hy2_connections_active", "Active proxied connections", float64(conntrack.Count()))
+	m.counter("hy2_connections_closed", "Closed proxied connections", float64(conntrack.ClosedTotal()))
+
+	m.header("hy2_server_connects", "counter", "Transport connect attempts per server")
+	for _, s := range ServerStatsSnapshot() {
+		m.sample("hy2_server_connects_total", float64(s.Connects), "server", s.Server, "result", "ok")
+		m.sample("hy2_server_connects_total", float64(s.Failures), "server", s.Server, "result", "fail")
+	}
+
+	m.header("hy2_route_rule_hits", "counter", "Routing decisions per rule")
+	for _, r := range routing.Stats() {
+		m.sample("hy2_route_rule_hits_total", float64(r.Hits), "rule", r.Rule, "outbound", r.Outbound)
+	}
+
+	m.header("hy2_app_bytes", "counter", "Bytes per application (split tunneling)")
+	for _, a := range AppStatsSnapshot() {
+		m.sample("hy2_app_bytes_total", float64(a.BytesIn), "app", a.App, "direction", "in")
+		m.sample("hy2_app_bytes_total", float64(a.BytesOut), "app", a.App, "direction", "out")
+	}
+
+	m.histogram("hy2_dial_duration_seconds", "Outbound dial latency of local inbounds", DialLatency.Snapshot())
+	m.histogram("hy2_rtt_seconds", "Transport RTT probes", RttLatency.Snapshot())
+	m.histogram("hy2_handshake_duration_seconds", "Transport (re)connect handshake duration", HandshakeLatency.Snapshot())
+	m.histogram("hy2_reconnect_downtime_seconds", "Time from connection loss to successful reconnect", ReconnectDowntime.Snapshot())
+
+	fmt.Fprint(bw, "# EOF\n")
+}
+
+func ms(v int64) float64 { return float64(v) / 1000 }
+
+type metricsWriter struct{ w io.Writer }
+
+func (m metricsWriter) header(name, typ, help string) {
+	fmt.Fprintf(m.w, "# TYPE %s %s\n# HELP %s %s\n", name, typ, name, help)
+}
+
+func (m metricsWriter) sample(name string, v float64, labels ...string) {
+	fmt.Fprint(m.w, name)
+	if len(labels) > 0 {
+		fmt.Fprint(m.w, "{")
+		for i := 0; i+1 < len(labels); i += 2 {
+			if i > 0 {
+				fmt.Fprint(m.w, ",")
+			}
+			fmt.Fprintf(m.w, "%s=\"%s\"", labels[i], escapeLabel(labels[i+1]))
+		}
+		fmt.Fprint(m.w, "}")
+	}
+	fmt.Fprintf(m.w, " %s\n", formatFloat(v))
+}
+
+func (m metricsWriter) gauge(name, help string, v float64) {
+	m.header(name, "gauge", help)
+	m.sample(name, v)
+}
+
+func (m metricsWriter) counter(name, help string, v float64) {
+	m.header(name, "counter", help)
+	m.sample(name+"_total", v)
+}
+
+// histogram пишет гистограмму; значения в Histogram — мс, в метриках — секунды.
+func (m metricsWriter) histogram(name, help string, s HistogramSnapshot) {
+	m.header(name, "histogram", help)
+	for i, b := range s.Bounds {
+		m.sample(name+"_bucket", float64(s.Cumulative[i]), "le", formatFloat(b/1000))
+	}
+	m.sample(name+"_bucket", float64(s.Count), "le", "+Inf")
+	m.sample(name+"_sum", s.Sum/1000)
+	m.sample(name+"_count", float64(s.Count))
+}
+
+func formatFloat(v float64) string { return strconv.FormatFloat(v, 'g', -1, 64) }
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(s string) string { return labelEscaper.Replace(s) }
@@ -0,0 +1,76 @@
+//go:build mobile_skel
+
+package telemetry
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestWriteMetrics_Format(t *testing.T) {
+	ResetBytesStats()
+	ResetServerStats()
+	defer ResetServerStats()
+	BytesIn.Add(1000)
+	BytesOut.Add(500)
+	QuicRttMs.Store(42)
+	ServerConnectResult("hy2.example:443", true)
+	ServerConnectResult("hy2.example:443", false)
+	DialLatency.Reset()
+	DialLatency.Observe(7)
+	DialLatency.Observe(3000)
+
+	var sb strings.Builder
+	WriteMetrics(&sb)
+	out := sb.String()
+
+	for _, want := range []string{
+		"# TYPE hy2_received_bytes counter\n",
+		"hy2_received_bytes_total 1000\n",
+		"hy2_sent_bytes_total 500\n",
+		"# TYPE hy2_quic_rtt_seconds gauge\n",
+		"hy2_quic_rtt_seconds 0.042\n",
+		`hy2_server_connects_total{server="hy2.example:443",result="ok"} 1` + "\n",
+		`hy2_server_connects_total{server="hy2.example:443",result="fail"} 1` + "\n",
+		`hy2_route_rule_hits_total{rule="final",outbound="proxy"}`,
+		"# TYPE hy2_dial_duration_seconds histogram\n",
+		`hy2_dial_duration_seconds_bucket{le="0.005"} 0` + "\n",
+		`hy2_dial_duration_seconds_bucket{le="0.01"} 1` + "\n",
+		`hy2_dial_duration_seconds_bucket{le="+Inf"} 2` + "\n",
+		"hy2_dial_duration_seconds_count 2\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("metrics output missing %q", want)
+		}
+	}
+	if !strings.HasSuffix(out, "# EOF\n") {
+		t.Fatalf("metrics output must end with # EOF, got tail %q", out[len(out)-20:])
+	}
+}
+
+func TestMetricsServer_Serves(t *testing.T) {
+	if err := StartMetricsServer("127.0.0.1:0"); err != nil {
+		t.Fatalf("StartMetricsServer: %v", err)
+	}
+	defer StopMetricsServer()
+
+	resp, err := http.Get("http://" + MetricsAddr() + "/metrics")
+	if err != nil {
+		t.Fatalf("GET /metrics: %v", err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); !strings.HasPrefix(ct, "application/openmetrics-text") {
+		t.Fatalf("unexpected content type %q", ct)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	if !strings.Contains(string(body), "hy2_up ") {
+		t.Fatalf("body lacks hy2_up: %s", body)
+	}
+
+	StopMetricsServer()
+	if MetricsAddr() != "" {
+		t.Fatal("MetricsAddr must be empty after stop")
+	}
+}
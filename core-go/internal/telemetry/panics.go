@@ -0,0 +1,67 @@
+// go:build android || ios || mobile_skel
+
+package telemetry
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	logpkg "github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/pkg/logging"
+)
+
+// maxPanicStack — предел длины стека в событии/Health (байт).
+const maxPanicStack = 8 << 10
+
+// PanicInfo — перехваченная паника горутины ядра.
+type PanicInfo struct {
+	Label   string `json:"label"`   // имя горутины/подсистемы ("sing.supervisor", "socks.serve", ...)
+	Msg     string `json:"msg"`     // значение panic(...)
+	Stack   string `json:"stack"`   // стек (секреты вырезаны, обрезан до 8 КБ)
+	Ts      int64  `json:"ts"`      // unix seconds
+	Count   uint64 `json:"count"`   // порядковый номер паники с запуска процесса
+	Restart bool   `json:"restart"` // будет ли подсистема перезапущена
+}
+
+var (
+	// Panics — число перехваченных паник с запуска процесса.
+	Panics atomic.Uint64
+
+	lastPanicMu sync.Mutex
+	lastPanic   *PanicInfo
+)
+
+// RecordPanic учитывает перехваченную панику: счётчик, LastPanic и событие EvtPanic.
+func RecordPanic(label string, v any, stack []byte, restart bool) PanicInfo {
+	if len(stack) > maxPanicStack {
+		stack = append(stack[:maxPanicStack:maxPanicStack], "\n...truncated"...)
+	}
+	p := PanicInfo{
+		Label:   label,
+		Msg:     logpkg.Redact(fmt.Sprint(v)),
+		Stack:   logpkg.Redact(string(stack)),
+		Ts:      time.Now().Unix(),
+		Count:   Panics.Add(1),
+		Restart: restart,
+	}
+	lastPanicMu.Lock()
+	lastPanic = &p
+	lastPanicMu.Unlock()
+
+	b, _ := json.Marshal(p)
+	Emit(EvtPanic, string(b))
+	return p
+}
+
+// LastPanic возвращает последнюю перехваченную панику (nil — паник не было).
+func LastPanic() *PanicInfo {
+	lastPanicMu.Lock()
+	defer lastPanicMu.Unlock()
+	if lastPanic == nil {
+		return nil
+	}
+	cp := *lastPanic
+	return &cp
+}
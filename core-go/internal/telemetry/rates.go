@@ -0,0 +1,174 @@
+// go:build android || ios || mobile_skel
+
+package telemetry
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// Скорости трафика по скользящим окнам 1s/10s/60s и пики за сессию.
+// Сэмплер раз в секунду снимает BytesIn/BytesOut в кольцевой буфер,
+// скорости считаются по разнице снимков. Upload = BytesOut, download = BytesIn.
+
+// EvtTraffic — периодическое событие со скоростями (для спидометров в UI).
+const EvtTraffic = "traffic"
+
+// DefaultTrafficEventInterval — интервал события traffic по умолчанию.
+const DefaultTrafficEventInterval = time.Second
+
+// Rates — текущие скорости в битах в секунду.
+type Rates struct {
+	UpBps       uint64 `json:"up_bps"`
+	DownBps     uint64 `json:"down_bps"`
+	UpBps10s    uint64 `json:"up_bps_10s"`
+	DownBps10s  uint64 `json:"down_bps_10s"`
+	UpBps60s    uint64 `json:"up_bps_60s"`
+	DownBps60s  uint64 `json:"down_bps_60s"`
+	PeakUpBps   uint64 `json:"peak_up_bps"`
+	PeakDownBps uint64 `json:"peak_down_bps"`
+}
+
+type rateSample struct {
+	t       time.Time
+	in, out uint64
+}
+
+const rateWindow = 60
+
+var (
+	rateMu   sync.Mutex
+	rateRing [rateWindow + 1]rateSample // хватает на окно 60s
+	rateHead int                        // индекс следующей записи
+	rateN    int                        // число валидных сэмплов
+	ratePeak Rates                      // заполнены только Peak*
+	rateStop chan struct{}
+	rateWg   sync.WaitGroup
+)
+
+// StartRateSampler запускает сэмплер скоростей и сбрасывает пики сессии.
+// eventEvery > 0 — как часто эмитить EvtTraffic (не чаще раза в секунду);
+// eventEvery < 0 — событие отключено, скорости доступны только в HealthJSON.
+// Повторный вызов перезапускает сэмплер.
+func StartRateSampler(eventEvery time.Duration) {
+	StopRateSampler()
+	ResetRates()
+	if eventEvery == 0 {
+		eventEvery = DefaultTrafficEventInterval
+	}
+
+	stop := make(chan struct{})
+	rateMu.Lock()
+	rateStop = stop
+	rateMu.Unlock()
+	SampleRates(time.Now())
+
+	rateWg.Add(1)
+	go func() {
+		defer rateWg.Done()
+		tk := time.NewTicker(time.Second)
+		defer tk.Stop()
+		var lastEmit time.Time
+		for {
+			select {
+			case <-stop:
+				return
+			case now := <-tk.C:
+				SampleRates(now)
+				if eventEvery > 0 && now.Sub(lastEmit) >= eventEvery-50*time.Millisecond {
+					lastEmit = now
+					Emit(EvtTraffic, TrafficRatesJSON())
+				}
+			}
+		}
+	}()
+}
+
+// StopRateSampler останавливает сэмплер (если запущен). Последние скорости сохраняются.
+func StopRateSampler() {
+	rateMu.Lock()
+	stop := rateStop
+	rateStop = nil
+	rateMu.Unlock()
+	if stop != nil {
+		close(stop)
+		rateWg.Wait()
+	}
+}
+
+// ResetRates очищает окна и пики.
+func ResetRates() {
+	rateMu.Lock()
+	defer rateMu.Unlock()
+	rateHead, rateN = 0, 0
+	ratePeak = Rates{}
+}
+
+// SampleRates снимает текущие счётчики трафика на момент now.
+// Вызывается сэмплером; экспортирована для тестов и ручного тика.
+func SampleRates(now time.Time) {
+	in, out := BytesStats()
+	rateMu.Lock()
+	defer rateMu.Unlock()
+	rateRing[rateHead] = rateSample{t: now, in: in, out: out}
+	rateHead = (rateHead + 1) % len(rateRing)
+	if rateN < len(rateRing) {
+		rateN++
+	}
+	up, down := windowRateLocked(1)
+	if up > ratePeak.PeakUpBps {
+		ratePeak.PeakUpBps = up
+	}
+	if down > ratePeak.PeakDownBps {
+		ratePeak.PeakDownBps = down
+	}
+}
+
+// CurrentRates возвращает скорости; ok=false, если сэмплов ещё недостаточно.
+func CurrentRates() (r Rates, ok bool) {
+	rateMu.Lock()
+	defer rateMu.Unlock()
+	if rateN < 2 {
+		return Rates{}, false
+	}
+	r.UpBps, r.DownBps = windowRateLocked(1)
+	r.UpBps10s, r.DownBps10s = windowRateLocked(10)
+	r.UpBps60s, r.DownBps60s = windowRateLocked(60)
+	r.PeakUpBps, r.PeakDownBps = ratePeak.PeakUpBps, ratePeak.PeakDownBps
+	return r, true
+}
+
+// TrafficRatesJSON — CurrentRates в JSON (нули, если данных ещё нет).
+func TrafficRatesJSON() string {
+	r, _ := CurrentRates()
+	b, _ := json.Marshal(r)
+	return string(b)
+}
+
+// windowRateLocked считает скорость (бит/с) за последние sec сэмплов.
+// Если сэмплов меньше — берётся самый старый доступный.
+func windowRateLocked(sec int) (up, down uint64) {
+	if rateN < 2 {
+		return 0, 0
+	}
+	if sec > rateN-1 {
+		sec = rateN - 1
+	}
+	n := len(rateRing)
+	cur := rateRing[(rateHead-1+n)%n]
+	old := rateRing[(rateHead-1-sec+2*n)%n]
+	dt := cur.t.Sub(old.t).Seconds()
+	if dt <= 0 {
+		return 0, 0
+	}
+	return bps(cur.out, old.out, dt), bps(cur.in, old.in, dt)
+}
+
+// bps — скорость в бит/с; сброс счётчиков (cur < old) даёт 0.
+func bps(cur, old uint64, dt float64) uint64 {
+	if cur < old {
+		return 0
+	}
+	return uint64(float64(cur-old) * 8 / dt)
+}
@@ -0,0 +1,95 @@
+//go:build mobile_skel
+
+package telemetry
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestRates_Windows(t *testing.T) {
+	ResetBytesStats()
+	ResetRates()
+	defer ResetRates()
+
+	if _, ok := CurrentRates(); ok {
+		t.Fatal("rates must be unavailable without samples")
+	}
+
+	t0 := time.Unix(1_700_000_000, 0)
+	SampleRates(t0)
+	// 10 секунд: download 1000 B/s, upload 100 B/s; на 5-й секунде всплеск.
+	for i := 1; i <= 10; i++ {
+		BytesIn.Add(1000)
+		BytesOut.Add(100)
+		if i == 5 {
+			BytesIn.Add(9000)
+		}
+		SampleRates(t0.Add(time.Duration(i) * time.Second))
+	}
+
+	r, ok := CurrentRates()
+	if !ok {
+		t.Fatal("rates must be available")
+	}
+	if r.DownBps != 8000 || r.UpBps != 800 {
+		t.Fatalf("1s rates = %d/%d, want 8000/800", r.DownBps, r.UpBps)
+	}
+	if r.DownBps10s != 15200 || r.UpBps10s != 800 {
+		t.Fatalf("10s rates = %d/%d, want 15200/800", r.DownBps10s, r.UpBps10s)
+	}
+	if r.DownBps60s != r.DownBps10s {
+		t.Fatalf("60s window must fall back to available samples: %+v", r)
+	}
+	if r.PeakDownBps != 80000 || r.PeakUpBps != 800 {
+		t.Fatalf("peaks = %d/%d, want 80000/800", r.PeakDownBps, r.PeakUpBps)
+	}
+
+	var got Rates
+	if err := json.Unmarshal([]byte(TrafficRatesJSON()), &got); err != nil || got != r {
+		t.Fatalf("TrafficRatesJSON mismatch: %v %+v", err, got)
+	}
+}
+
+func TestRates_CounterResetGivesZero(t *testing.T) {
+	ResetBytesStats()
+	ResetRates()
+	defer ResetRates()
+
+	t0 := time.Unix(1_700_000_000, 0)
+	BytesIn.Add(5000)
+	SampleRates(t0)
+	ResetBytesStats()
+	SampleRates(t0.Add(time.Second))
+
+	if r, _ := CurrentRates(); r.DownBps != 0 {
+		t.Fatalf("rate after counter reset = %d, want 0", r.DownBps)
+	}
+}
+
+func TestRateSampler_EmitsTraffic(t *testing.T) {
+	got := make(chan string, 4)
+	SetEventSink(EventSinkFunc(func(name, data string) {
+		if name == EvtTraffic {
+			select {
+			case got <- data:
+			default:
+			}
+		}
+	}))
+	defer SetEventSink(nil)
+
+	StartRateSampler(time.Second)
+	defer StopRateSampler()
+
+	select {
+	case data := <-got:
+		var r Rates
+		if err := json.Unmarshal([]byte(data), &r); err != nil {
+			t.Fatalf("invalid traffic payload %q: %v", data, err)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("traffic event was not emitted")
+	}
+}
@@ -0,0 +1,61 @@
+// go:build android || ios || mobile_skel
+
+package telemetry
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// ServerStats — счётчики подключений транспорта к одному серверу.
+type ServerStats struct {
+	Server   string `json:"server"`
+	Connects uint64 `json:"connects"`
+	Failures uint64 `json:"failures"`
+}
+
+type serverCounters struct{ ok, fail atomic.Uint64 }
+
+var (
+	srvMu    sync.Mutex
+	srvStats = map[string]*serverCounters{}
+)
+
+// ServerConnectResult учитывает попытку подключения транспорта к server.
+func ServerConnectResult(server string, ok bool) {
+	if server == "" {
+		return
+	}
+	srvMu.Lock()
+	c := srvStats[server]
+	if c == nil {
+		c = &serverCounters{}
+		srvStats[server] = c
+	}
+	srvMu.Unlock()
+	if ok {
+		c.ok.Add(1)
+	} else {
+		c.fail.Add(1)
+	}
+}
+
+// ServerStatsSnapshot возвращает per-server счётчики, отсортированные по адресу.
+func ServerStatsSnapshot() []ServerStats {
+	srvMu.Lock()
+	defer srvMu.Unlock()
+	out := make([]ServerStats, 0, len(srvStats))
+	for s, c := range srvStats {
+		out = append(out, ServerStats{Server: s, Connects: c.ok.Load(), Failures: c.fail.Load()})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Server < out[j].Server })
+	return out
+}
+
+// ResetServerStats очищает per-server счётчики.
+func ResetServerStats() {
+	srvMu.Lock()
+	srvStats = map[string]*serverCounters{}
+	srvMu.Unlock()
+}
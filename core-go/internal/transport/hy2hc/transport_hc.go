@@ -5,21 +5,35 @@ package hy2hc
 import (
 	"context"
 	"crypto/tls"
+	"errors"
 	"fmt"
 	"net"
 	"sync"
 	"sync/atomic"
 	"time"
 
-	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/netstack/protect"
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/netstack/upstream"
 	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/runtime"
 	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/telemetry"
 	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/transport"
 	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/transport/sing"
 	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/pkg/config"
+	ers "github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/pkg/errors"
 	hcclient "github.com/apernet/hysteria/core/client"
+	hcerrors "github.com/apernet/hysteria/core/errors"
 )
 
+func init() {
+	transport.Register(transport.Engine{
+		Name:    "hc",
+		Aliases: []string{"hysteria_core"},
+		Caps:    transport.Caps{TCP: true, UDP: true, Migration: true, Obfs: true},
+		New: func(cfg config.HY2Config) (transport.Transport, error) {
+			return NewTransportHC(cfg), nil
+		},
+	})
+}
+
 type transportHC struct {
 	mu     sync.Mutex
 	ctx    context.Context
@@ -30,17 +44,20 @@ type transportHC struct {
 	alpn  string
 	rem   string
 	lastE atomic.Value // string
+	diag  *sing.ConnectDiag
 
 	superWg sync.WaitGroup
 	closed  atomic.Bool
 
-	cli   *hcclient.Client
+	cliMu sync.RWMutex // cli читают DialContext/ListenPacket из чужих горутин
+	cli   hcclient.Client
 	pconn net.PacketConn
 	cfg   config.HY2Config
 }
 
 func NewTransportHC(cfg config.HY2Config) transport.Transport {
 	t := &transportHC{cfg: cfg, sni: cfg.SNI}
+	t.diag = sing.NewConnectDiag(cfg.Server, &t.lastE)
 	if len(cfg.ALPN) > 0 {
 		t.alpn = cfg.ALPN[0]
 	} else {
@@ -63,10 +80,18 @@ func (t *transportHC) Start(parent context.Context) error {
 	t.ctx, t.cancel = ctx, cancel
 	t.closed.Store(false)
 
-	_ = t.StartOnce(ctx)
+	hs := time.Now()
+	err := t.StartOnce(ctx)
+	telemetry.TransportConnectResult(t.cfg.Server, hs, t.rtt.Load(), err)
+	if err != nil {
+		t.diag.Fail(ctx, err)
+	}
 
 	t.superWg.Add(1)
-	go t.Supervisor()
+	go func() {
+		defer t.superWg.Done()
+		runtime.RunRestartable("hc.supervisor", t.Supervisor, func() bool { return !t.closed.Load() })
+	}()
 	return nil
 }
 
@@ -87,8 +112,8 @@ func (t *transportHC) Stop(ctx context.Context) error {
 	if cancel != nil {
 		cancel()
 	}
-	if t.cli != nil {
-		_ = t.cli.Close()
+	if cli := t.client(); cli != nil {
+		_ = cli.Close()
 	}
 	if t.pconn != nil {
 		_ = t.pconn.Close()
@@ -122,8 +147,8 @@ func (t *transportHC) Status() transport.TransportStatus {
 }
 
 func (t *transportHC) Supervisor() {
-	defer t.superWg.Done()
 	bo := runtime.NewBackoffState()
+	var lostAt time.Time // момент потери соединения (для reconnect downtime)
 
 	for {
 		if t.closed.Load() {
@@ -133,12 +158,16 @@ func (t *transportHC) Supervisor() {
 			time.Sleep(200 * time.Millisecond)
 			continue
 		}
+		if lostAt.IsZero() {
+			lostAt = time.Now()
+		}
 
 		next := bo.Next()
 		telemetry.Emit(
 			telemetry.EvtReconnecting,
 			sing.ToJSON(sing.ReconnectingPayload{
 				Reason: "lost", Attempt: bo.Attempt, NextMs: int(next.Milliseconds()),
+				Diagnosis: t.diag.Wait(t.ctx),
 			}))
 		telemetry.SetLastBackoffMs(next.Milliseconds())
 
@@ -153,12 +182,18 @@ func (t *transportHC) Supervisor() {
 		if t.closed.Load() {
 			return
 		}
-		if err := t.StartOnce(t.ctx); err != nil {
-			t.lastE.Store("reconnect: " + err.Error())
+		hs := time.Now()
+		err := t.StartOnce(t.ctx)
+		telemetry.TransportConnectResult(t.cfg.Server, hs, t.rtt.Load(), err)
+		if err != nil {
+			t.diag.Fail(t.ctx, fmt.Errorf("reconnect: %w", err))
 			telemetry.SetLastErrTs(time.Now().Unix())
 			continue
 		}
 		bo.Reset()
+		t.diag.Reset()
+		telemetry.ObserveDowntime(lostAt)
+		lostAt = time.Time{}
 		telemetry.Reconnects.Add(1)
 		telemetry.Emit(telemetry.EvtReconnected, sing.ToJSON(sing.ReconnectedPayload{RttMs: t.rtt.Load()}))
 	}
@@ -172,12 +207,12 @@ func (t *transportHC) IsAlive() bool {
 // --- ключевая точка: запуск Hysteria2 Core + Protect(fd) ---
 
 func (t *transportHC) StartOnce(ctx context.Context) error {
-	// 1) UDP PacketConn с Protect(fd) — это твой protect_dial.go
+	// 1) UDP PacketConn с Protect(fd); при detour socks5 — через UDP ASSOCIATE
 	if t.pconn != nil {
 		_ = t.pconn.Close()
 		t.pconn = nil
 	}
-	pc, err := protect.ProtectedPacketConn(ctx)
+	pc, err := upstream.ListenPacket(ctx)
 	if err != nil {
 		return fmt.Errorf("udp listen: %w", err)
 	}
@@ -191,7 +226,8 @@ func (t *transportHC) StartOnce(ctx context.Context) error {
 			}
 			return []string{"h3"}
 		}(),
-		MinVersion: tls.VersionTLS13,
+		MinVersion:         tls.VersionTLS13,
+		InsecureSkipVerify: t.cfg.Insecure,
 	}
 
 	// 3) Конфиг клиента HC.
@@ -209,16 +245,16 @@ func (t *transportHC) StartOnce(ctx context.Context) error {
 	// dialer := protectedTCPDialer()  // твой helper
 	// и передать его в поля клиента/QUIC (если API даёт такой хук).
 
-	cli, err := hcclient.New(ctx, cconf)
+	cli, _, err := hcclient.New(cconf)
 	if err != nil {
 		_ = pc.Close()
-		return fmt.Errorf("hc new: %w", err)
+		return connectErr(err)
 	}
 
 	// 4) Быстрая проверка канала и первичный RTT (замени на реальный Ping, если есть)
 	t.rtt.Store(25) // TODO: заменить на значение из клиента (Ping/RTT)
 	t.rem = t.cfg.Server
-	t.cli = cli
+	t.setClient(cli)
 	t.pconn = pc
 	telemetry.HealthSetIdentity(t.cfg.SNI, t.alpn)
 
@@ -227,3 +263,14 @@ func (t *transportHC) StartOnce(ctx context.Context) error {
 
 	return nil
 }
+
+// connectErr переводит ошибку подключения клиента в код pkg/errors: отказ
+// сервера в авторизации — ErrAuthRejected, ошибки сертификата/TLS —
+// ErrTLSHandshake (Classify), остальное — ErrEngineInitFailed.
+func connectErr(err error) error {
+	var authErr hcerrors.AuthError
+	if errors.As(err, &authErr) {
+		return ers.Wrap(err, ers.ErrAuthRejected, ers.StageAuth, "hc")
+	}
+	return ers.Classify(fmt.Errorf("hc new: %w", err), ers.StageHandshake)
+}
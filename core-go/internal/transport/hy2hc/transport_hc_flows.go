@@ -0,0 +1,164 @@
+//go:build (android || ios) && hc
+
+package hy2hc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	hcclient "github.com/apernet/hysteria/core/client"
+)
+
+// Потоки приложений через Hysteria2: TCP — client.TCP, UDP — client.UDP
+// (HyUDPConn), обёрнутый в net.PacketConn для инбаундов и пробросов.
+
+var errNotConnected = errors.New("hc: not connected")
+
+func (t *transportHC) client() hcclient.Client {
+	t.cliMu.RLock()
+	defer t.cliMu.RUnlock()
+	return t.cli
+}
+
+func (t *transportHC) setClient(cli hcclient.Client) {
+	t.cliMu.Lock()
+	old := t.cli
+	t.cli = cli
+	t.cliMu.Unlock()
+	if old != nil && old != cli {
+		_ = old.Close()
+	}
+}
+
+// DialContext открывает TCP-поток через сервер Hysteria2.
+func (t *transportHC) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	cli := t.client()
+	if cli == nil {
+		return nil, errNotConnected
+	}
+	type result struct {
+		c   net.Conn
+		err error
+	}
+	ch := make(chan result, 1)
+	go func() {
+		c, err := cli.TCP(addr)
+		ch <- result{c, err}
+	}()
+	select {
+	case r := <-ch:
+		return r.c, r.err
+	case <-ctx.Done():
+		go func() {
+			if r := <-ch; r.c != nil {
+				_ = r.c.Close()
+			}
+		}()
+		return nil, ctx.Err()
+	}
+}
+
+// ListenPacket открывает UDP-сессию через сервер Hysteria2.
+func (t *transportHC) ListenPacket(context.Context) (net.PacketConn, error) {
+	cli := t.client()
+	if cli == nil {
+		return nil, errNotConnected
+	}
+	uc, err := cli.UDP()
+	if err != nil {
+		return nil, err
+	}
+	pc := &hcPacketConn{conn: uc, in: make(chan hcDatagram, 64), done: make(chan struct{})}
+	go pc.readLoop()
+	return pc, nil
+}
+
+// hcAddr — адрес назначения как его отдаёт HyUDPConn ("host:port").
+type hcAddr string
+
+func (a hcAddr) Network() string { return "udp" }
+func (a hcAddr) String() string  { return string(a) }
+
+type hcDatagram struct {
+	data []byte
+	addr string
+}
+
+// hcPacketConn — HyUDPConn как net.PacketConn. Receive блокирующий и без
+// дедлайнов, поэтому читает отдельная горутина, а ReadFrom ждёт канал с
+// учётом read deadline.
+type hcPacketConn struct {
+	conn hcclient.HyUDPConn
+	in   chan hcDatagram
+	done chan struct{}
+	once sync.Once
+	rd   atomic.Int64 // read deadline, UnixNano; 0 — без дедлайна
+}
+
+func (c *hcPacketConn) readLoop() {
+	defer c.Close()
+	for {
+		data, addr, err := c.conn.Receive()
+		if err != nil {
+			return
+		}
+		select {
+		case c.in <- hcDatagram{data, addr}:
+		case <-c.done:
+			return
+		}
+	}
+}
+
+func (c *hcPacketConn) ReadFrom(p []byte) (int, net.Addr, error) {
+	var timeout <-chan time.Time
+	if d := c.rd.Load(); d != 0 {
+		tm := time.NewTimer(time.Until(time.Unix(0, d)))
+		defer tm.Stop()
+		timeout = tm.C
+	}
+	select {
+	case dg := <-c.in:
+		return copy(p, dg.data), hcAddr(dg.addr), nil
+	case <-timeout:
+		return 0, nil, os.ErrDeadlineExceeded
+	case <-c.done:
+		return 0, nil, net.ErrClosed
+	}
+}
+
+func (c *hcPacketConn) WriteTo(p []byte, addr net.Addr) (int, error) {
+	if err := c.conn.Send(p, addr.String()); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (c *hcPacketConn) Close() error {
+	c.once.Do(func() {
+		close(c.done)
+		_ = c.conn.Close()
+	})
+	return nil
+}
+
+func (c *hcPacketConn) LocalAddr() net.Addr { return hcAddr("") }
+
+func (c *hcPacketConn) SetDeadline(t time.Time) error { return c.SetReadDeadline(t) }
+
+func (c *hcPacketConn) SetReadDeadline(t time.Time) error {
+	if t.IsZero() {
+		c.rd.Store(0)
+	} else {
+		c.rd.Store(t.UnixNano())
+	}
+	return nil
+}
+
+// SetWriteDeadline не поддерживается: Send не блокируется на сети.
+func (c *hcPacketConn) SetWriteDeadline(time.Time) error { return nil }
@@ -2,8 +2,21 @@
 
 package hy2hc
 
-// test shim: на юнитах под mobile_skel возвращаем sing-транспорт вместо реального HC.
+import (
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/transport"
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/transport/sing"
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/pkg/config"
+)
+
+// test shim: на юнитах под mobile_skel регистрируем "hc" поверх sing-транспорта.
 // Это позволяет проверить выбор транспорта и StartWithTun без Android/iOS окружения.
-func newTransportHC(cfg HY2Config) Transport {
-	return newTransportSingHY2(cfg)
+func init() {
+	transport.Register(transport.Engine{
+		Name:    "hc",
+		Aliases: []string{"hysteria_core"},
+		Caps:    transport.Caps{TCP: true, UDP: true, Migration: true, Obfs: true},
+		New: func(cfg config.HY2Config) (transport.Transport, error) {
+			return sing.NewTransportSingHY2(cfg), nil
+		},
+	})
 }
@@ -5,19 +5,22 @@ package hy2hc
 import (
 	"context"
 	"testing"
+
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/transport"
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/pkg/config"
 )
 
 func TestSelectTransport_HCChosen(t *testing.T) {
-	cfg := HY2Config{
+	cfg := config.HY2Config{
 		Engine:   "hc",
 		Server:   "example.com:443",
 		Password: "secret",
 		SNI:      "example.com",
 		ALPN:     []string{"h3"},
 	}
-	tr := selectTransport(cfg)
-	if tr == nil {
-		t.Fatalf("selectTransport returned nil for Engine=hc")
+	tr, err := transport.SelectTransport(cfg)
+	if err != nil || tr == nil {
+		t.Fatalf("SelectTransport(Engine=hc) = %v, %v", tr, err)
 	}
 	// транспорт стартует и корректно останавливается
 	ctx, cancel := context.WithCancel(context.Background())
@@ -0,0 +1,109 @@
+// go:build android || ios || mobile_skel
+
+package transport
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/pkg/config"
+)
+
+// Реестр движков: каждая реализация Transport регистрируется по имени
+// в init() своего пакета, а HY2Config.Engine выбирает из реестра.
+// Какие движки попадут в сборку, решают build-теги и blank-импорты
+// (см. mobile/engines.go и mobile/engines_hc.go).
+
+// Caps — возможности движка.
+type Caps struct {
+	TCP       bool `json:"tcp"`
+	UDP       bool `json:"udp"`
+	Migration bool `json:"migration"` // переживает смену сети без переподключения (QUIC)
+	Obfs      bool `json:"obfs"`      // поддерживает обфускацию (salamander и т.п.)
+}
+
+// Factory создаёт транспорт по уже провалидированному конфигу.
+type Factory func(cfg config.HY2Config) (Transport, error)
+
+// Engine — запись реестра.
+type Engine struct {
+	Name    string   `json:"name"`
+	Aliases []string `json:"aliases,omitempty"`
+	Caps    Caps     `json:"caps"`
+	New     Factory  `json:"-"`
+}
+
+var (
+	regMu   sync.RWMutex
+	engines = map[string]*Engine{} // имя и алиасы → движок
+)
+
+// Register добавляет движок в реестр. Повторная регистрация имени — ошибка
+// программиста, поэтому panic (как database/sql.Register).
+func Register(e Engine) {
+	if e.Name == "" || e.New == nil {
+		panic("transport: Register with empty name or nil factory")
+	}
+	regMu.Lock()
+	defer regMu.Unlock()
+	for _, n := range append([]string{e.Name}, e.Aliases...) {
+		if _, dup := engines[n]; dup {
+			panic("transport: engine " + n + " registered twice")
+		}
+		engines[n] = &e
+	}
+}
+
+// Lookup ищет движок по имени или алиасу.
+func Lookup(name string) (Engine, bool) {
+	regMu.RLock()
+	defer regMu.RUnlock()
+	e, ok := engines[name]
+	if !ok {
+		return Engine{}, false
+	}
+	return *e, true
+}
+
+// Engines — движки текущей сборки, по имени (без дублей от алиасов).
+func Engines() []Engine {
+	regMu.RLock()
+	defer regMu.RUnlock()
+	out := make([]Engine, 0, len(engines))
+	for n, e := range engines {
+		if n == e.Name {
+			out = append(out, *e)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// EngineNames — имена движков через запятую (для сообщений об ошибках).
+func EngineNames() string {
+	es := Engines()
+	names := make([]string, len(es))
+	for i, e := range es {
+		names[i] = e.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+// CheckEngine возвращает понятную ошибку, если движка name нет в сборке.
+func CheckEngine(name string) error {
+	if _, ok := Lookup(name); ok {
+		return nil
+	}
+	return fmt.Errorf("engine: unknown engine %q (available: %s)", name, EngineNames())
+}
+
+// SelectTransport создаёт транспорт движка cfg.Engine.
+func SelectTransport(cfg config.HY2Config) (Transport, error) {
+	if err := CheckEngine(cfg.Engine); err != nil {
+		return nil, err
+	}
+	e, _ := Lookup(cfg.Engine)
+	return e.New(cfg)
+}
@@ -0,0 +1,66 @@
+//go:build mobile_skel
+
+package transport
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/pkg/config"
+)
+
+// unregister убирает движок name вместе с алиасами (только для тестов:
+// реестр глобальный, а Register паникует на повторе).
+func unregister(name string) {
+	regMu.Lock()
+	defer regMu.Unlock()
+	e, ok := engines[name]
+	if !ok {
+		return
+	}
+	for _, n := range append([]string{e.Name}, e.Aliases...) {
+		delete(engines, n)
+	}
+}
+
+func TestRegistry_RegisterLookupSelect(t *testing.T) {
+	t.Cleanup(func() { unregister("test-engine") })
+	Register(Engine{
+		Name:    "test-engine",
+		Aliases: []string{"test-alias"},
+		Caps:    Caps{TCP: true},
+		New: func(cfg config.HY2Config) (Transport, error) {
+			return &fakeTransport{name: cfg.Server}, nil
+		},
+	})
+
+	e, ok := Lookup("test-alias")
+	if !ok || e.Name != "test-engine" || !e.Caps.TCP || e.Caps.UDP {
+		t.Fatalf("Lookup(alias) = %+v, %v", e, ok)
+	}
+	n := 0
+	for _, e := range Engines() {
+		if e.Name == "test-engine" {
+			n++
+		}
+	}
+	if n != 1 {
+		t.Fatalf("Engines must list test-engine once, got %d", n)
+	}
+
+	tr, err := SelectTransport(config.HY2Config{Engine: "test-engine", Server: "s:1"})
+	if err != nil || tr.Status().Remote != "s:1" {
+		t.Fatalf("SelectTransport = %v, %v", tr, err)
+	}
+	_, err = SelectTransport(config.HY2Config{Engine: "nope"})
+	if err == nil || !strings.Contains(err.Error(), `unknown engine "nope"`) || !strings.Contains(err.Error(), "test-engine") {
+		t.Fatalf("unexpected error for unknown engine: %v", err)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("duplicate Register must panic")
+		}
+	}()
+	Register(Engine{Name: "test-alias", New: e.New})
+}
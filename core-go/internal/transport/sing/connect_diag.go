@@ -0,0 +1,110 @@
+// go:build android || ios || mobile_skel
+
+package sing
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/runtime"
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/telemetry"
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/transport/udpprobe"
+	ers "github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/pkg/errors"
+)
+
+// ConnectDiag — диагностика серии неудачных (пере)подключений к серверу.
+//
+// Сетевая проба (udpprobe) запускается один раз на серию и в фоне, вне
+// цикла повторов; серия заканчивается успешным подключением (Reset).
+// Ошибки с собственным кодом (авторизация, TLS, DNS) классифицируются
+// сразу, без проб. Событие error эмитится только при смене вердикта, а
+// lastE получает строку вида "udp_blocked: <исходная ошибка>".
+type ConnectDiag struct {
+	server string
+	lastE  *atomic.Value
+
+	mu      sync.Mutex
+	gen     int           // номер серии: проба прошлой серии не пишет вердикт
+	verdict string        // текущий вердикт серии ("" — ещё нет)
+	probe   chan struct{} // закрывается по окончании пробы серии; nil — не запускалась
+}
+
+// NewConnectDiag создаёт диагностику для server; lastE — поле LastErr транспорта.
+func NewConnectDiag(server string, lastE *atomic.Value) *ConnectDiag {
+	return &ConnectDiag{server: server, lastE: lastE}
+}
+
+// Fail учитывает неудачную попытку подключения. Не блокируется.
+func (d *ConnectDiag) Fail(ctx context.Context, err error) {
+	if r, ok := udpprobe.FromError(err); ok {
+		d.mu.Lock()
+		d.set(r, err)
+		d.mu.Unlock()
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.verdict != "" {
+		d.lastE.Store(d.verdict + ": " + err.Error())
+	} else {
+		d.lastE.Store(err.Error())
+	}
+	if d.probe != nil {
+		return
+	}
+	done := make(chan struct{})
+	d.probe = done
+	gen := d.gen
+	runtime.SafeGoNamed("transport.diagnose", func() {
+		defer close(done)
+		r := udpprobe.Diagnose(ctx, d.server, err)
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		if gen == d.gen {
+			d.set(r, err)
+		}
+	})
+}
+
+// set обновляет вердикт серии; вызывается под d.mu.
+func (d *ConnectDiag) set(r udpprobe.Result, err error) {
+	d.lastE.Store(r.Verdict + ": " + err.Error())
+	if r.Verdict == d.verdict {
+		return
+	}
+	d.verdict = r.Verdict
+	telemetry.EmitErr(r.Err(err), ers.StageDial)
+}
+
+// Wait возвращает вердикт серии, дождавшись пробы, если она ещё идёт
+// (не дольше ctx). Для первого reconnecting-события серии.
+func (d *ConnectDiag) Wait(ctx context.Context) string {
+	d.mu.Lock()
+	probe := d.probe
+	d.mu.Unlock()
+	if probe != nil {
+		select {
+		case <-probe:
+		case <-ctx.Done():
+		}
+	}
+	return d.Verdict()
+}
+
+// Verdict — текущий вердикт серии ("" — нет или серия закончилась).
+func (d *ConnectDiag) Verdict() string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.verdict
+}
+
+// Reset завершает серию после успешного подключения.
+func (d *ConnectDiag) Reset() {
+	d.mu.Lock()
+	d.gen++
+	d.verdict = ""
+	d.probe = nil
+	d.mu.Unlock()
+}
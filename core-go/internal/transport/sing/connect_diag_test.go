@@ -0,0 +1,66 @@
+//go:build mobile_skel
+
+package sing
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/transport/udpprobe"
+	ers "github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/pkg/errors"
+)
+
+func TestConnectDiag_OneProbePerStreak(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := pc.LocalAddr().String()
+	pc.Close() // UDP-порт закрыт — проба скажет server_down
+
+	var lastE atomic.Value
+	d := NewConnectDiag(server, &lastE)
+	ctx := context.Background()
+
+	start := time.Now()
+	d.Fail(ctx, errors.New("dial failed"))
+	if time.Since(start) > 100*time.Millisecond {
+		t.Fatal("Fail must not wait for the probe")
+	}
+	probe := d.probe
+	if v := d.Wait(ctx); v != udpprobe.VerdictServerDown {
+		t.Fatalf("verdict after probe = %q", v)
+	}
+
+	d.Fail(ctx, errors.New("dial failed again"))
+	if d.probe != probe {
+		t.Fatal("second failure of the streak started a new probe")
+	}
+	if le, _ := lastE.Load().(string); le != "server_down: dial failed again" {
+		t.Fatalf("lastE = %q", le)
+	}
+
+	// Ошибка с собственным кодом меняет вердикт сразу, без пробы.
+	d.Fail(ctx, ers.New(ers.ErrAuthRejected, ers.StageAuth, "401"))
+	if v := d.Verdict(); v != udpprobe.VerdictAuthFailed {
+		t.Fatalf("verdict after auth error = %q", v)
+	}
+
+	d.Reset()
+	if d.Verdict() != "" || d.probe != nil {
+		t.Fatal("Reset must end the streak")
+	}
+	d.Fail(ctx, errors.New("new streak"))
+	if d.probe == nil || d.probe == probe {
+		t.Fatal("a new streak must probe again")
+	}
+	d.Wait(ctx)
+	if le, _ := lastE.Load().(string); !strings.HasPrefix(le, "server_down: ") {
+		t.Fatalf("lastE = %q", le)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -13,27 +14,40 @@ import (
 	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/runtime"
 	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/telemetry"
 	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/transport"
-	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/transport/hy2hc"
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/pkg/config"
 	ers "github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/pkg/errors"
 )
 
+func init() {
+	transport.Register(transport.Engine{
+		Name: "sing",
+		Caps: transport.Caps{TCP: true, UDP: true, Migration: true, Obfs: true},
+		New: func(cfg config.HY2Config) (transport.Transport, error) {
+			return NewTransportSingHY2(cfg), nil
+		},
+	})
+}
+
 type transportSingHY2 struct {
 	mu     sync.Mutex
 	ctx    context.Context
 	cancel context.CancelFunc
 
-	rtt   atomic.Int64
-	sni   string
-	alpn  string
-	rem   string
-	lastE atomic.Value // string
+	rtt    atomic.Int64
+	server string
+	sni    string
+	alpn   string
+	rem    string
+	lastE  atomic.Value // string
+	diag   *ConnectDiag
 
 	superWg sync.WaitGroup
 	closed  atomic.Bool
 }
 
-func NewTransportSingHY2(cfg hy2hc.HY2Config) *transportSingHY2 {
-	t := &transportSingHY2{sni: cfg.SNI}
+func NewTransportSingHY2(cfg config.HY2Config) *transportSingHY2 {
+	t := &transportSingHY2{sni: cfg.SNI, server: cfg.Server}
+	t.diag = NewConnectDiag(cfg.Server, &t.lastE)
 	if len(cfg.ALPN) > 0 {
 		t.alpn = cfg.ALPN[0]
 	} else {
@@ -57,9 +71,17 @@ func (t *transportSingHY2) Start(parent context.Context) error {
 	t.ctx, t.cancel = ctx, cancel
 	t.closed.Store(false)
 
-	_ = StartOnceSing(t, ctx)
+	hs := time.Now()
+	err := StartOnceSing(t, ctx)
+	telemetry.TransportConnectResult(t.server, hs, t.rtt.Load(), err)
+	if err != nil {
+		t.diag.Fail(ctx, err)
+	}
 	t.superWg.Add(1)
-	go t.Supervisor()
+	go func() {
+		defer t.superWg.Done()
+		runtime.RunRestartable("sing.supervisor", t.Supervisor, func() bool { return !t.closed.Load() })
+	}()
 
 	return nil
 }
@@ -122,6 +144,9 @@ type ReconnectingPayload struct {
 	Reason  string `json:"reason"`
 	Attempt int    `json:"attempt"`
 	NextMs  int    `json:"next_ms"`
+	// Diagnosis — вердикт диагностики последней неудачной попытки
+	// (udp_blocked | server_down | auth_failed | tls_failed | dns_failed).
+	Diagnosis string `json:"diagnosis,omitempty"`
 }
 
 type ReconnectedPayload struct {
@@ -131,8 +156,8 @@ type ReconnectedPayload struct {
 // --- внутреннее ---
 
 func (t *transportSingHY2) Supervisor() {
-	defer t.superWg.Done()
 	bo := runtime.NewBackoffState()
+	var lostAt time.Time // момент потери соединения (для reconnect downtime)
 
 	for {
 		if t.closed.Load() {
@@ -142,6 +167,9 @@ func (t *transportSingHY2) Supervisor() {
 			time.Sleep(50 * time.Millisecond)
 			continue
 		}
+		if lostAt.IsZero() {
+			lostAt = time.Now()
+		}
 
 		// было: next := bo.next()
 		next := bo.Next()
@@ -149,8 +177,9 @@ func (t *transportSingHY2) Supervisor() {
 		telemetry.Emit(telemetry.EvtReconnecting, ToJSON(ReconnectingPayload{
 			Reason: "lost",
 			// было: bo.attempt
-			Attempt: bo.Attempt(), // добавь этот геттер в runtime/backoff, если его ещё нет
-			NextMs:  int(next.Milliseconds()),
+			Attempt:   bo.Attempt,
+			NextMs:    int(next.Milliseconds()),
+			Diagnosis: t.diag.Wait(t.ctx),
 		}))
 		telemetry.SetLastBackoffMs(next.Milliseconds())
 
@@ -166,13 +195,19 @@ func (t *transportSingHY2) Supervisor() {
 			return
 		}
 		// было: StartOnceSing (функция есть, всё ок)
-		if err := StartOnceSing(t, t.ctx); err != nil {
-			t.lastE.Store("reconnect: " + err.Error())
+		hs := time.Now()
+		err := StartOnceSing(t, t.ctx)
+		telemetry.TransportConnectResult(t.server, hs, t.rtt.Load(), err)
+		if err != nil {
+			t.diag.Fail(t.ctx, fmt.Errorf("reconnect: %w", err))
 			telemetry.SetLastErrTs(time.Now().Unix())
 			continue
 		}
 		// было: bo.reset()
 		bo.Reset()
+		t.diag.Reset()
+		telemetry.ObserveDowntime(lostAt)
+		lostAt = time.Time{}
 
 		telemetry.Reconnects.Add(1)
 		telemetry.Emit(telemetry.EvtReconnected, ToJSON(ReconnectedPayload{RttMs: t.rtt.Load()}))
@@ -201,15 +236,7 @@ func (t *transportSingHY2) RecordErr(stage string, err error) {
 	if err == nil {
 		return
 	}
-	t.lastE.Store(stage + ": " + err.Error())
-	telemetry.EmitError(int(ers.ErrEngineInitFailed), stage+": "+err.Error()) // или отдельный stage-код
-}
-
-func StartOnceSing(t *transportSingHY2, ctx context.Context) error {
-	// TODO: собрать dialer с Protect(fd), поднять HY2, заполнить t.rem, t.rtt.Store(...)
-	return nil
-}
-
-func IsAliveSing(t *transportSingHY2) bool {
-	return t.rtt.Load() > 0 // временно
+	e := ers.Classify(err, ers.Stage(stage))
+	t.lastE.Store(e.Error())
+	telemetry.EmitErr(e, ers.Stage(stage))
 }
@@ -1,7 +1,16 @@
-//go:build android || ios
+//go:build (android || ios) && !mobile_skel
 
 package sing
 
-// startOnceSing — реальный запуск sing/hysteria2 (пока TODO).
+import "context"
 
-// isAliveSing — реальная проверка живости (например, по lastRTTAt или состоянию клиента).
+// StartOnceSing — реальный запуск sing/hysteria2.
+func StartOnceSing(t *transportSingHY2, ctx context.Context) error {
+	// TODO: сокеты — через upstream.ListenPacket/DialContext (protect + detour), поднять HY2, заполнить t.rem, t.rtt.Store(...)
+	return nil
+}
+
+// IsAliveSing — реальная проверка живости (например, по lastRTTAt или состоянию клиента).
+func IsAliveSing(t *transportSingHY2) bool {
+	return t.rtt.Load() > 0 // временно
+}
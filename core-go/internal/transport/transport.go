@@ -2,7 +2,10 @@
 
 package transport
 
-import "context"
+import (
+	"context"
+	"net"
+)
 
 type TransportStatus struct {
 	RTTms   int64
@@ -17,3 +20,42 @@ type Transport interface {
 	Stop(ctx context.Context) error
 	Status() TransportStatus
 }
+
+// Dialer — транспорт, умеющий открывать потоки через туннель
+// (hc, Trojan-fallback и обёртка fallback реализуют его). Через него идут
+// proxy-потоки инбаундов (runtime.TunnelDial) и пробросы портов.
+type Dialer interface {
+	DialContext(ctx context.Context, network, addr string) (net.Conn, error)
+}
+
+// PacketDialer — транспорт, умеющий UDP через туннель: адреса в WriteTo/ReadFrom —
+// адреса назначения, как у обычного net.PacketConn.
+type PacketDialer interface {
+	ListenPacket(ctx context.Context) (net.PacketConn, error)
+}
+
+// FlowCarrier — транспорт, который сам сообщает, может ли он нести потоки
+// приложений: нужен обёрткам (fallback), которые реализуют Dialer и
+// PacketDialer всегда, а умеют это только через вложенные транспорты.
+type FlowCarrier interface {
+	CarriesTCP() bool
+	CarriesUDP() bool
+}
+
+// CarriesTCP сообщает, можно ли открывать TCP-потоки через t (Dialer).
+func CarriesTCP(t Transport) bool {
+	if fc, ok := t.(FlowCarrier); ok {
+		return fc.CarriesTCP()
+	}
+	_, ok := t.(Dialer)
+	return ok
+}
+
+// CarriesUDP сообщает, можно ли гнать UDP через t (PacketDialer).
+func CarriesUDP(t Transport) bool {
+	if fc, ok := t.(FlowCarrier); ok {
+		return fc.CarriesUDP()
+	}
+	_, ok := t.(PacketDialer)
+	return ok
+}
@@ -0,0 +1,237 @@
+// go:build android || ios || mobile_skel
+
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/telemetry"
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/transport/udpprobe"
+	logpkg "github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/pkg/logging"
+)
+
+var log = logpkg.With(logpkg.CompTransport)
+
+// Значения по умолчанию для FallbackOptions.
+const (
+	DefaultFallbackAfter         = 3
+	DefaultFallbackProbeInterval = 30 * time.Second
+)
+
+// ErrNoDialer — активный транспорт не умеет открывать потоки (Dialer).
+var ErrNoDialer = errors.New("active transport does not support dialing")
+
+// FallbackOptions — когда переходить на запасной транспорт и как проверять возврат.
+type FallbackOptions struct {
+	// Server — host:port основного (UDP) сервера, по нему проверяется восстановление UDP.
+	Server string
+	// After — сколько подряд reconnecting с диагнозом udp_blocked нужно для переключения (0 — 3).
+	After int
+	// ProbeInterval — период UDP-проб, пока активен запасной транспорт (0 — 30 с).
+	ProbeInterval time.Duration
+}
+
+// fallbackTransport держит основной транспорт (HY2/QUIC) запущенным всегда,
+// а запасной (TCP/TLS) поднимает, когда supervisor основного раз за разом
+// диагностирует udp_blocked. Пока запасной активен, периодическая UDP-проба
+// проверяет, не починился ли UDP, и при успехе возвращает основной.
+type fallbackTransport struct {
+	primary, secondary Transport
+	opt                FallbackOptions
+
+	mu        sync.Mutex
+	ctx       context.Context
+	cancel    context.CancelFunc
+	subID     int64
+	failures  int
+	switching bool // запасной транспорт поднимается
+	active    bool // запасной транспорт активен
+	wg        sync.WaitGroup
+}
+
+// NewFallback оборачивает primary запасным secondary (см. fallbackTransport).
+func NewFallback(primary, secondary Transport, opt FallbackOptions) Transport {
+	if opt.After <= 0 {
+		opt.After = DefaultFallbackAfter
+	}
+	if opt.ProbeInterval <= 0 {
+		opt.ProbeInterval = DefaultFallbackProbeInterval
+	}
+	return &fallbackTransport{primary: primary, secondary: secondary, opt: opt}
+}
+
+func (f *fallbackTransport) Start(ctx context.Context) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	f.mu.Lock()
+	if f.ctx != nil {
+		f.mu.Unlock()
+		return nil
+	}
+	f.ctx, f.cancel = context.WithCancel(ctx)
+	f.failures, f.switching, f.active = 0, false, false
+	f.subID = telemetry.SubscribeEvents(telemetry.EventSinkFunc(f.onEvent),
+		telemetry.EvtReconnecting, telemetry.EvtReconnected)
+	f.mu.Unlock()
+	return f.primary.Start(ctx)
+}
+
+func (f *fallbackTransport) Stop(ctx context.Context) error {
+	f.mu.Lock()
+	if f.ctx == nil {
+		f.mu.Unlock()
+		return nil
+	}
+	telemetry.UnsubscribeEvents(f.subID)
+	f.cancel()
+	active := f.active
+	f.ctx, f.cancel, f.active = nil, nil, false
+	f.mu.Unlock()
+
+	f.wg.Wait()
+	if active {
+		_ = f.secondary.Stop(ctx)
+	}
+	return f.primary.Stop(ctx)
+}
+
+// Status — статус активного транспорта.
+func (f *fallbackTransport) Status() TransportStatus {
+	return f.current().Status()
+}
+
+// DialContext открывает поток через активный транспорт.
+func (f *fallbackTransport) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	if d, ok := f.current().(Dialer); ok {
+		return d.DialContext(ctx, network, addr)
+	}
+	return nil, ErrNoDialer
+}
+
+// ListenPacket открывает UDP через активный транспорт.
+func (f *fallbackTransport) ListenPacket(ctx context.Context) (net.PacketConn, error) {
+	if d, ok := f.current().(PacketDialer); ok {
+		return d.ListenPacket(ctx)
+	}
+	return nil, ErrNoDialer
+}
+
+// CarriesTCP — хотя бы один из транспортов умеет TCP-потоки (в режиме
+// без такого транспорта DialContext вернёт ErrNoDialer).
+func (f *fallbackTransport) CarriesTCP() bool {
+	return CarriesTCP(f.primary) || CarriesTCP(f.secondary)
+}
+
+// CarriesUDP — хотя бы один из транспортов умеет UDP.
+func (f *fallbackTransport) CarriesUDP() bool {
+	return CarriesUDP(f.primary) || CarriesUDP(f.secondary)
+}
+
+// Active — "primary" или "fallback".
+func (f *fallbackTransport) Active() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.active {
+		return "fallback"
+	}
+	return "primary"
+}
+
+func (f *fallbackTransport) current() Transport {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.active {
+		return f.secondary
+	}
+	return f.primary
+}
+
+// onEvent считает подряд идущие reconnecting с диагнозом udp_blocked.
+func (f *fallbackTransport) onEvent(name, data string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.ctx == nil || f.active {
+		return
+	}
+	if name == telemetry.EvtReconnected {
+		f.failures = 0
+		return
+	}
+	var p struct {
+		Diagnosis string `json:"diagnosis"`
+	}
+	_ = json.Unmarshal([]byte(data), &p)
+	if p.Diagnosis != udpprobe.VerdictUDPBlocked {
+		f.failures = 0
+		return
+	}
+	f.failures++
+	if f.failures < f.opt.After || f.switching {
+		return
+	}
+	// Рукопожатие запасного транспорта не должно держать шину событий.
+	f.switching = true
+	f.wg.Add(1)
+	go f.activate(f.ctx)
+}
+
+// activate поднимает запасной транспорт и, пока он активен, ждёт восстановления UDP.
+func (f *fallbackTransport) activate(ctx context.Context) {
+	defer f.wg.Done()
+	err := f.secondary.Start(ctx)
+
+	f.mu.Lock()
+	f.switching, f.failures = false, 0
+	if err != nil || ctx.Err() != nil {
+		f.mu.Unlock()
+		if err != nil {
+			log.Warn("fallback transport start failed", logpkg.F("err", err))
+		} else {
+			_ = f.secondary.Stop(context.Background())
+		}
+		return
+	}
+	f.active = true
+	f.mu.Unlock()
+
+	emitSwitched("fallback", udpprobe.VerdictUDPBlocked)
+	f.probeLoop(ctx)
+}
+
+// probeLoop проверяет UDP до основного сервера и возвращает основной транспорт.
+func (f *fallbackTransport) probeLoop(ctx context.Context) {
+	tk := time.NewTicker(f.opt.ProbeInterval)
+	defer tk.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-tk.C:
+		}
+		if r := udpprobe.Probe(ctx, f.opt.Server, udpprobe.DefaultTimeout); !r.UDPReachable {
+			continue
+		}
+		f.mu.Lock()
+		if ctx.Err() != nil {
+			f.mu.Unlock()
+			return
+		}
+		f.active = false
+		f.mu.Unlock()
+		_ = f.secondary.Stop(ctx)
+		emitSwitched("primary", "udp_recovered")
+		return
+	}
+}
+
+func emitSwitched(active, reason string) {
+	log.Info("transport switched", logpkg.F("active", active), logpkg.F("reason", reason))
+	telemetry.Emit(telemetry.EvtTransportSwitched, fmt.Sprintf(`{"active":%q,"reason":%q}`, active, reason))
+}
@@ -0,0 +1,106 @@
+//go:build mobile_skel
+
+package transport
+
+import (
+	"context"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/telemetry"
+)
+
+type fakeTransport struct {
+	name    string
+	started atomic.Int32
+	stopped atomic.Int32
+}
+
+func (f *fakeTransport) Start(context.Context) error { f.started.Add(1); return nil }
+func (f *fakeTransport) Stop(context.Context) error  { f.stopped.Add(1); return nil }
+func (f *fakeTransport) Status() TransportStatus     { return TransportStatus{Remote: f.name} }
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(3 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timeout waiting for %s", what)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestFallback_SwitchesOnUDPBlockedAndBack(t *testing.T) {
+	// «Основной сервер»: UDP-ответчик, который молчит, пока не включат.
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+	var udpUp atomic.Bool
+	go func() {
+		buf := make([]byte, 2048)
+		for {
+			_, from, err := pc.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			if udpUp.Load() {
+				_, _ = pc.WriteTo([]byte{0x80}, from)
+			}
+		}
+	}()
+
+	primary, secondary := &fakeTransport{name: "hy2"}, &fakeTransport{name: "trojan"}
+	tr := NewFallback(primary, secondary, FallbackOptions{
+		Server: pc.LocalAddr().String(), After: 2, ProbeInterval: 50 * time.Millisecond,
+	})
+	if err := tr.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer tr.Stop(context.Background())
+	fb := tr.(*fallbackTransport)
+
+	telemetry.Emit(telemetry.EvtReconnecting, `{"reason":"lost","diagnosis":"udp_blocked"}`)
+	telemetry.Emit(telemetry.EvtReconnecting, `{"reason":"lost","diagnosis":"server_down"}`)
+	telemetry.Emit(telemetry.EvtReconnecting, `{"reason":"lost","diagnosis":"udp_blocked"}`)
+	telemetry.FlushEvents(time.Second)
+	if fb.Active() != "primary" || secondary.started.Load() != 0 {
+		t.Fatal("non-consecutive udp_blocked must not trigger fallback")
+	}
+
+	telemetry.Emit(telemetry.EvtReconnecting, `{"reason":"lost","diagnosis":"udp_blocked"}`)
+	telemetry.FlushEvents(time.Second)
+	waitFor(t, "fallback", func() bool { return fb.Active() == "fallback" })
+	if tr.Status().Remote != "trojan" {
+		t.Fatalf("Status must report the fallback transport, got %+v", tr.Status())
+	}
+
+	udpUp.Store(true)
+	waitFor(t, "switch back", func() bool { return fb.Active() == "primary" })
+	waitFor(t, "secondary stop", func() bool { return secondary.stopped.Load() == 1 })
+	if _, err := tr.(Dialer).DialContext(context.Background(), "tcp", "a:1"); err != ErrNoDialer {
+		t.Fatalf("DialContext on non-dialer = %v, want ErrNoDialer", err)
+	}
+}
+
+type fakeDialer struct{ fakeTransport }
+
+func (*fakeDialer) DialContext(context.Context, string, string) (net.Conn, error) { return nil, nil }
+
+func TestCarries(t *testing.T) {
+	plain, dialer := &fakeTransport{}, &fakeDialer{}
+	if CarriesTCP(plain) || CarriesUDP(plain) || !CarriesTCP(dialer) || CarriesUDP(dialer) {
+		t.Fatal("Carries* must follow Dialer/PacketDialer")
+	}
+	fb := NewFallback(plain, dialer, FallbackOptions{})
+	if !CarriesTCP(fb) || CarriesUDP(fb) {
+		t.Fatal("fallback carries what either of its transports carries")
+	}
+	if CarriesTCP(NewFallback(plain, &fakeTransport{}, FallbackOptions{})) {
+		t.Fatal("fallback without dialers must not claim tcp")
+	}
+}
@@ -0,0 +1,226 @@
+// go:build android || ios || mobile_skel
+
+// Package trojan — запасной TCP/TLS-транспорт (протокол Trojan) для сетей,
+// где UDP/QUIC до HY2-сервера режется. Каждый поток — отдельное TLS-соединение
+// с заголовком Trojan; долгоживущей сессии нет, поэтому и supervisor не нужен.
+package trojan
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/netstack/upstream"
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/telemetry"
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/transport"
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/pkg/config"
+	ers "github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/pkg/errors"
+)
+
+// Команды и типы адресов заголовка Trojan (совпадают с SOCKS5).
+const (
+	cmdConnect = 0x01
+
+	atypIPv4   = 0x01
+	atypDomain = 0x03
+	atypIPv6   = 0x04
+
+	handshakeTimeout = 10 * time.Second
+)
+
+var crlf = []byte{'\r', '\n'}
+
+// DialFunc — первый хоп до Trojan-сервера (по умолчанию upstream.DialContext:
+// защищённый сокет, при настроенном detour — через вышестоящий прокси).
+type DialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// Config — параметры Trojan-сервера.
+type Config struct {
+	Server   string   // host:port
+	Password string   // пароль Trojan (на сервер уходит hex(sha224))
+	SNI      string   // по умолчанию host из Server
+	ALPN     []string // по умолчанию h2, http/1.1
+	Insecure bool     // не проверять сертификат (только для тестов/самоподписанных)
+	Dial     DialFunc // первый хоп; nil — upstream.DialContext
+}
+
+func init() {
+	transport.Register(transport.Engine{
+		Name: "trojan",
+		Caps: transport.Caps{TCP: true},
+		New: func(cfg config.HY2Config) (transport.Transport, error) {
+			return NewTransport(Config{Server: cfg.Server, Password: cfg.Password, SNI: cfg.SNI, Insecure: cfg.Insecure}), nil
+		},
+	})
+}
+
+type transportTrojan struct {
+	cfg  Config
+	hash []byte // hex(sha224(password))
+
+	started atomic.Bool
+	rtt     atomic.Int64
+	mu      sync.Mutex
+	rem     string
+	alpn    string
+	lastE   atomic.Value // string
+}
+
+// NewTransport создаёт Trojan-транспорт. Он же реализует transport.Dialer.
+func NewTransport(cfg Config) *transportTrojan {
+	if cfg.SNI == "" {
+		cfg.SNI, _, _ = net.SplitHostPort(cfg.Server)
+	}
+	if len(cfg.ALPN) == 0 {
+		cfg.ALPN = []string{"h2", "http/1.1"}
+	}
+	if cfg.Dial == nil {
+		cfg.Dial = upstream.DialContext
+	}
+	return &transportTrojan{cfg: cfg, hash: PasswordHash(cfg.Password)}
+}
+
+// PasswordHash — hex(sha224(password)), 56 байт, как требует протокол.
+func PasswordHash(password string) []byte {
+	sum := sha256.Sum224([]byte(password))
+	out := make([]byte, hex.EncodedLen(len(sum)))
+	hex.Encode(out, sum[:])
+	return out
+}
+
+// Start проверяет доступность сервера одним TLS-рукопожатием (RTT, ALPN).
+func (t *transportTrojan) Start(ctx context.Context) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	hs := time.Now()
+	c, err := t.dialTLS(ctx)
+	telemetry.TransportConnectResult(t.cfg.Server, hs, t.rtt.Load(), err)
+	if err != nil {
+		return err
+	}
+	_ = c.Close()
+	t.started.Store(true)
+	return nil
+}
+
+func (t *transportTrojan) Stop(context.Context) error {
+	t.started.Store(false)
+	return nil
+}
+
+func (t *transportTrojan) Status() transport.TransportStatus {
+	t.mu.Lock()
+	st := transport.TransportStatus{
+		RTTms:  t.rtt.Load(),
+		Remote: t.rem,
+		ALPN:   t.alpn,
+		SNI:    t.cfg.SNI,
+	}
+	t.mu.Unlock()
+	if v, ok := t.lastE.Load().(string); ok {
+		st.LastErr = v
+	}
+	return st
+}
+
+// DialContext открывает TCP-поток до addr через Trojan-сервер.
+func (t *transportTrojan) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	if network != "tcp" && network != "tcp4" && network != "tcp6" {
+		return nil, fmt.Errorf("trojan: network %q not supported", network)
+	}
+	if !t.started.Load() {
+		return nil, ers.New(ers.ErrNotRunning, ers.StageDial, "trojan transport not started")
+	}
+	hdr, err := appendRequest(nil, t.hash, cmdConnect, addr)
+	if err != nil {
+		return nil, err
+	}
+	c, err := t.dialTLS(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := c.Write(hdr); err != nil {
+		_ = c.Close()
+		t.recordErr(err, ers.StageDial)
+		return nil, err
+	}
+	return c, nil
+}
+
+func (t *transportTrojan) dialTLS(ctx context.Context) (*tls.Conn, error) {
+	ctx, cancel := context.WithTimeout(ctx, handshakeTimeout)
+	defer cancel()
+
+	start := time.Now()
+	raw, err := t.cfg.Dial(ctx, "tcp", t.cfg.Server)
+	if err != nil {
+		return nil, t.recordErr(err, ers.StageDial)
+	}
+	c := tls.Client(raw, &tls.Config{
+		ServerName:         t.cfg.SNI,
+		NextProtos:         t.cfg.ALPN,
+		InsecureSkipVerify: t.cfg.Insecure,
+		MinVersion:         tls.VersionTLS12,
+	})
+	if err := c.HandshakeContext(ctx); err != nil {
+		_ = raw.Close()
+		return nil, t.recordErr(err, ers.StageHandshake)
+	}
+	ms := time.Since(start).Milliseconds()
+	if ms < 1 {
+		ms = 1
+	}
+	t.rtt.Store(ms)
+	t.mu.Lock()
+	t.rem = raw.RemoteAddr().String()
+	t.alpn = c.ConnectionState().NegotiatedProtocol
+	t.mu.Unlock()
+	return c, nil
+}
+
+func (t *transportTrojan) recordErr(err error, stage ers.Stage) error {
+	e := ers.Classify(err, stage)
+	t.lastE.Store(e.Error())
+	telemetry.SetLastErrTs(time.Now().Unix())
+	return e
+}
+
+// appendRequest дописывает заголовок Trojan:
+//
+//	hex(sha224(pass)) CRLF CMD ATYP DST.ADDR DST.PORT CRLF
+func appendRequest(b, hash []byte, cmd byte, addr string) ([]byte, error) {
+	host, ps, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+	port, err := strconv.ParseUint(ps, 10, 16)
+	if err != nil {
+		return nil, fmt.Errorf("trojan: bad port %q", ps)
+	}
+	b = append(b, hash...)
+	b = append(b, crlf...)
+	b = append(b, cmd)
+	switch ip := net.ParseIP(host); {
+	case ip != nil && ip.To4() != nil:
+		b = append(append(b, atypIPv4), ip.To4()...)
+	case ip != nil:
+		b = append(append(b, atypIPv6), ip.To16()...)
+	default:
+		if len(host) == 0 || len(host) > 255 {
+			return nil, errors.New("trojan: bad domain length")
+		}
+		b = append(append(b, atypDomain, byte(len(host))), host...)
+	}
+	b = binary.BigEndian.AppendUint16(b, uint16(port))
+	return append(b, crlf...), nil
+}
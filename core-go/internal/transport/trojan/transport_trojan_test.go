@@ -0,0 +1,186 @@
+//go:build mobile_skel
+
+package trojan
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"io"
+	"math/big"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/netstack/protect"
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/transport"
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/pkg/config"
+)
+
+func init() {
+	// На десктопе VpnService нет: «защищаем» любые сокеты.
+	protect.SetProtectHook(func(int) bool { return true })
+}
+
+// startServer — минимальный Trojan-сервер: проверяет заголовок и работает как echo.
+func startServer(t *testing.T, password string) (addr string, gotAddr chan string) {
+	t.Helper()
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{selfSigned(t)},
+		NextProtos:   []string{"h2", "http/1.1"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	gotAddr = make(chan string, 4)
+	want := PasswordHash(password)
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func(c net.Conn) {
+				defer c.Close()
+				br := bufio.NewReader(c)
+				head := make([]byte, len(want)+2+1)
+				if _, err := io.ReadFull(br, head); err != nil {
+					return // проба из Start: рукопожатие без заголовка
+				}
+				if !bytes.Equal(head[:len(want)], want) || head[len(want)+2] != cmdConnect {
+					return
+				}
+				atyp, _ := br.ReadByte()
+				var host string
+				switch atyp {
+				case atypDomain:
+					n, _ := br.ReadByte()
+					b := make([]byte, n)
+					io.ReadFull(br, b)
+					host = string(b)
+				case atypIPv4:
+					b := make([]byte, 4)
+					io.ReadFull(br, b)
+					host = net.IP(b).String()
+				}
+				pb := make([]byte, 2+2) // порт + CRLF
+				io.ReadFull(br, pb)
+				gotAddr <- net.JoinHostPort(host, strconv.Itoa(int(pb[0])<<8|int(pb[1])))
+				io.Copy(c, br)
+			}(c)
+		}
+	}()
+	return ln.Addr().String(), gotAddr
+}
+
+func TestTrojan_DialEcho(t *testing.T) {
+	srv, gotAddr := startServer(t, "secret")
+	tr := NewTransport(Config{Server: srv, Password: "secret", SNI: "localhost", Insecure: true})
+	if err := tr.Start(context.Background()); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+	defer tr.Stop(context.Background())
+
+	st := tr.Status()
+	if st.RTTms <= 0 || st.ALPN != "h2" || st.Remote == "" {
+		t.Fatalf("unexpected status: %+v", st)
+	}
+
+	c, err := tr.DialContext(context.Background(), "tcp", "example.com:8443")
+	if err != nil {
+		t.Fatalf("DialContext: %v", err)
+	}
+	defer c.Close()
+	select {
+	case a := <-gotAddr:
+		if a != "example.com:8443" {
+			t.Fatalf("server got target %q", a)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("server did not receive trojan header")
+	}
+	if _, err := c.Write([]byte("ping")); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 4)
+	c.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if _, err := io.ReadFull(c, buf); err != nil || string(buf) != "ping" {
+		t.Fatalf("echo: %q %v", buf, err)
+	}
+}
+
+func TestTrojan_StartFailsOnBadCert(t *testing.T) {
+	srv, _ := startServer(t, "secret")
+	tr := NewTransport(Config{Server: srv, Password: "secret", SNI: "localhost"})
+	if err := tr.Start(context.Background()); err == nil {
+		t.Fatal("Start must fail on untrusted certificate")
+	}
+	if st := tr.Status(); st.LastErr == "" {
+		t.Fatal("LastErr must be set")
+	}
+	if _, err := tr.DialContext(context.Background(), "tcp", "example.com:80"); err == nil {
+		t.Fatal("DialContext must fail before successful Start")
+	}
+}
+
+func TestTrojan_EngineHonoursInsecure(t *testing.T) {
+	srv, _ := startServer(t, "secret")
+	e, ok := transport.Lookup("trojan")
+	if !ok {
+		t.Fatal("trojan engine not registered")
+	}
+	tr, err := e.New(config.HY2Config{Server: srv, Password: "secret", SNI: "localhost", Insecure: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tr.Start(context.Background()); err != nil {
+		t.Fatalf("Start with insecure=true: %v", err)
+	}
+	tr.Stop(context.Background())
+}
+
+func TestAppendRequest(t *testing.T) {
+	hash := PasswordHash("p")
+	if len(hash) != 56 {
+		t.Fatalf("hash length %d, want 56", len(hash))
+	}
+	b, err := appendRequest(nil, hash, cmdConnect, "10.0.0.1:443")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := append(append([]byte{}, hash...), '\r', '\n', cmdConnect, atypIPv4, 10, 0, 0, 1, 0x01, 0xbb, '\r', '\n')
+	if !bytes.Equal(b, want) {
+		t.Fatalf("header = %x, want %x", b, want)
+	}
+	if _, err := appendRequest(nil, hash, cmdConnect, "host:99999"); err == nil {
+		t.Fatal("bad port must fail")
+	}
+}
+
+func selfSigned(t *testing.T) tls.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "localhost"},
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
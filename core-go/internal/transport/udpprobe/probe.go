@@ -0,0 +1,278 @@
+//go:build android || ios || mobile_skel
+
+// Package udpprobe — быстрая диагностика недоступности HY2-сервера.
+//
+// Большая часть жалоб «не подключается» — сети, которые режут UDP/QUIC.
+// Probe шлёт на сервер QUIC-пакет с заведомо неподдерживаемой (GREASE)
+// версией: любой QUIC-сервер отвечает на него Version Negotiation, так что
+// любой ответ означает «UDP проходит». Если ответа нет, TCP-проба того же
+// host:port отличает «UDP режется» (хост жив) от «сервер лежит».
+package udpprobe
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"net"
+	"syscall"
+	"time"
+
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/netstack/protect"
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/netstack/upstream"
+	ers "github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/pkg/errors"
+)
+
+// Вердикты диагностики — стабильные коды для TransportStatus.LastErr и событий.
+const (
+	VerdictOK          = "ok"
+	VerdictUDPBlocked  = "udp_blocked"
+	VerdictServerDown  = "server_down"
+	VerdictAuthFailed  = "auth_failed"
+	VerdictTLSFailed   = "tls_failed"
+	VerdictDNSFailed   = "dns_failed"
+	VerdictUnknown     = "unknown"
+	DefaultTimeout     = 1500 * time.Millisecond
+	quicMinInitialSize = 1200 // RFC 9000 §14.1: меньше сервер вправе отбросить молча
+)
+
+// greaseVersion — версия вида 0x?a?a?a?a, зарезервированная под VN (RFC 9000 §15).
+var greaseVersion = [4]byte{0x1a, 0x2a, 0x3a, 0x4a}
+
+// Result — итог диагностики.
+type Result struct {
+	Verdict      string `json:"verdict"`
+	UDPReachable bool   `json:"udp_reachable"`
+	TCPReachable bool   `json:"tcp_reachable"` // хост ответил по TCP (accept или RST)
+	RTTms        int64  `json:"rtt_ms,omitempty"`
+	Detail       string `json:"detail,omitempty"`
+}
+
+// ErrCode — код pkg/errors, соответствующий вердикту.
+func (r Result) ErrCode() ers.ErrCode {
+	switch r.Verdict {
+	case VerdictOK:
+		return ers.ErrOK
+	case VerdictUDPBlocked:
+		return ers.ErrUDPBlocked
+	case VerdictServerDown:
+		return ers.ErrServerUnreachable
+	case VerdictAuthFailed:
+		return ers.ErrAuthRejected
+	case VerdictTLSFailed:
+		return ers.ErrTLSHandshake
+	case VerdictDNSFailed:
+		return ers.ErrDNSFailure
+	}
+	return ers.ErrEngineInitFailed
+}
+
+// Err оборачивает исходную ошибку подключения кодом вердикта (nil cause — nil).
+func (r Result) Err(cause error) error {
+	if cause == nil {
+		return nil
+	}
+	return ers.Wrap(cause, r.ErrCode(), ers.StageHandshake, r.Verdict)
+}
+
+// FromError распознаёт вердикт по самой ошибке подключения, без сетевых
+// проб: отказ в авторизации, ошибка TLS и DNS. false — нужна Probe.
+func FromError(connectErr error) (Result, bool) {
+	if connectErr == nil {
+		return Result{}, false
+	}
+	switch ers.CodeOf(connectErr) {
+	case ers.ErrAuthRejected:
+		return Result{Verdict: VerdictAuthFailed, Detail: connectErr.Error()}, true
+	case ers.ErrTLSHandshake, ers.ErrCertPinMismatch:
+		return Result{Verdict: VerdictTLSFailed, Detail: connectErr.Error()}, true
+	case ers.ErrDNSFailure:
+		return Result{Verdict: VerdictDNSFailed, Detail: connectErr.Error()}, true
+	}
+	return Result{}, false
+}
+
+// Diagnose классифицирует неудачное подключение к server (host:port).
+// Ошибки авторизации/TLS/DNS распознаются по самой ошибке без сетевых проб;
+// остальное — через Probe.
+func Diagnose(ctx context.Context, server string, connectErr error) Result {
+	if r, ok := FromError(connectErr); ok {
+		return r
+	}
+	r := Probe(ctx, server, DefaultTimeout)
+	if r.Verdict == VerdictOK && connectErr != nil {
+		// QUIC отвечает, но подключиться не вышло — проблема на уровне рукопожатия.
+		r.Verdict = VerdictTLSFailed
+		r.Detail = connectErr.Error()
+	}
+	return r
+}
+
+// Probe проверяет UDP-достижимость server (host:port) за timeout. При
+// настроенном upstream (detour) пробы идут тем же путём, что и транспорт:
+// UDP — через SOCKS5 UDP ASSOCIATE, TCP — через прокси, имя сервера
+// разрешает прокси.
+func Probe(ctx context.Context, server string, timeout time.Duration) Result {
+	host, port, err := net.SplitHostPort(server)
+	if err != nil {
+		return Result{Verdict: VerdictUnknown, Detail: err.Error()}
+	}
+	ctx, cancel := context.WithTimeout(ctx, 2*timeout)
+	defer cancel()
+
+	if _, ok := upstream.Active(); ok {
+		return probeVia(ctx, server, timeout)
+	}
+
+	ips, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil || len(ips) == 0 {
+		detail := "no addresses"
+		if err != nil {
+			detail = err.Error()
+		}
+		return Result{Verdict: VerdictDNSFailed, Detail: detail}
+	}
+	addr := net.JoinHostPort(ips[0].IP.String(), port)
+
+	d := net.Dialer{Timeout: timeout, Control: protectControl}
+	var rtt time.Duration
+	c, err := d.DialContext(ctx, "udp", addr)
+	if err == nil {
+		rtt, err = probeUDP(ctx, c, timeout)
+		c.Close()
+	}
+	return verdict(rtt, err, func() bool {
+		c, err := d.DialContext(ctx, "tcp", addr)
+		return tcpAlive(c, err)
+	})
+}
+
+// probeVia — Probe через upstream.
+func probeVia(ctx context.Context, server string, timeout time.Duration) Result {
+	var rtt time.Duration
+	pc, err := upstream.ListenPacket(ctx)
+	if err == nil {
+		rtt, err = probeUDP(ctx, boundConn{pc, hostPort(server)}, timeout)
+		pc.Close()
+	}
+	return verdict(rtt, err, func() bool {
+		tctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		c, err := upstream.DialContext(tctx, "tcp", server)
+		return tcpAlive(c, err)
+	})
+}
+
+// verdict собирает Result по итогу UDP-пробы; tcp вызывается, только если
+// UDP не ответил.
+func verdict(rtt time.Duration, err error, tcp func() bool) Result {
+	var r Result
+	if err == nil {
+		r.UDPReachable = true
+		r.RTTms = rtt.Milliseconds()
+		r.Verdict = VerdictOK
+		return r
+	}
+	r.Detail = "udp: " + err.Error()
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		// ICMP port unreachable: на UDP-порту никто не слушает.
+		r.Verdict = VerdictServerDown
+		return r
+	}
+	r.TCPReachable = tcp()
+	if r.TCPReachable {
+		r.Verdict = VerdictUDPBlocked
+	} else {
+		r.Verdict = VerdictServerDown
+	}
+	return r
+}
+
+// probeConn — то, что нужно probeUDP от UDP-сокета.
+type probeConn interface {
+	Read(b []byte) (int, error)
+	Write(b []byte) (int, error)
+	SetDeadline(t time.Time) error
+	SetReadDeadline(t time.Time) error
+}
+
+// probeUDP шлёт QUIC-пакет с GREASE-версией и ждёт любой ответ.
+func probeUDP(ctx context.Context, c probeConn, timeout time.Duration) (time.Duration, error) {
+	deadline := time.Now().Add(timeout)
+	if dl, ok := ctx.Deadline(); ok && dl.Before(deadline) {
+		deadline = dl
+	}
+	_ = c.SetDeadline(deadline)
+
+	pkt := quicProbePacket()
+	buf := make([]byte, 1500)
+	start := time.Now()
+	// Два отправления: одиночный датаграм может потеряться и в рабочей сети.
+	for i := 0; i < 2; i++ {
+		if _, err := c.Write(pkt); err != nil {
+			return 0, err
+		}
+		_ = c.SetReadDeadline(minTime(deadline, time.Now().Add(timeout/2)))
+		if _, err := c.Read(buf); err == nil {
+			return time.Since(start), nil
+		} else if !isTimeout(err) {
+			return 0, err
+		}
+	}
+	return 0, errors.New("no response")
+}
+
+// tcpAlive: хост считается живым, если TCP принял соединение или ответил
+// RST (через SOCKS5 — ответ прокси «connection refused»).
+func tcpAlive(c net.Conn, err error) bool {
+	if err == nil {
+		_ = c.Close()
+		return true
+	}
+	return errors.Is(err, syscall.ECONNREFUSED)
+}
+
+// boundConn — PacketConn upstream, «подключённый» к одному адресу.
+type boundConn struct {
+	net.PacketConn
+	dst net.Addr
+}
+
+func (c boundConn) Write(b []byte) (int, error) { return c.WriteTo(b, c.dst) }
+
+func (c boundConn) Read(b []byte) (int, error) {
+	n, _, err := c.ReadFrom(b)
+	return n, err
+}
+
+// hostPort — адрес назначения без локального резолва (его разрешает прокси).
+type hostPort string
+
+func (a hostPort) Network() string { return "udp" }
+func (a hostPort) String() string  { return string(a) }
+
+// quicProbePacket — long header, GREASE-версия, случайные DCID/SCID, паддинг до 1200 байт.
+func quicProbePacket() []byte {
+	p := make([]byte, quicMinInitialSize)
+	_, _ = rand.Read(p)
+	p[0] = 0xc0 | p[0]&0x0f // long header + fixed bit
+	copy(p[1:5], greaseVersion[:])
+	p[5] = 8  // DCID len, DCID = p[6:14]
+	p[14] = 8 // SCID len, SCID = p[15:23]
+	return p
+}
+
+func protectControl(_, _ string, rc syscall.RawConn) error {
+	return rc.Control(func(fd uintptr) { protect.ProtectFD(int(fd)) })
+}
+
+func isTimeout(err error) bool {
+	var ne net.Error
+	return errors.As(err, &ne) && ne.Timeout()
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,137 @@
+//go:build mobile_skel
+
+package udpprobe
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/netstack/protect"
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/netstack/upstream"
+	ers "github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/pkg/errors"
+	socks5 "github.com/armon/go-socks5"
+)
+
+func TestProbe_UDPReachable(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+	go func() {
+		buf := make([]byte, 2048)
+		n, from, err := pc.ReadFrom(buf)
+		if err != nil {
+			return
+		}
+		if n >= quicMinInitialSize && buf[1] == greaseVersion[0] {
+			_, _ = pc.WriteTo([]byte{0x80, 0, 0, 0, 0}, from) // «Version Negotiation»
+		}
+	}()
+
+	r := Probe(context.Background(), pc.LocalAddr().String(), time.Second)
+	if r.Verdict != VerdictOK || !r.UDPReachable {
+		t.Fatalf("unexpected result: %+v", r)
+	}
+}
+
+func TestProbe_UDPSilentButHostAlive(t *testing.T) {
+	// UDP-порт «глотает» пакеты, а TCP на том же порту жив — типичная картина блокировки UDP.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	port := ln.Addr().(*net.TCPAddr).Port
+	pc, err := net.ListenPacket("udp", "127.0.0.1:"+strconv.Itoa(port))
+	if err != nil {
+		t.Skipf("udp port %d busy: %v", port, err)
+	}
+	defer pc.Close()
+
+	r := Probe(context.Background(), ln.Addr().String(), 300*time.Millisecond)
+	if r.Verdict != VerdictUDPBlocked || r.UDPReachable || !r.TCPReachable {
+		t.Fatalf("unexpected result: %+v", r)
+	}
+	if r.ErrCode() != ers.ErrUDPBlocked {
+		t.Fatalf("ErrCode = %v", r.ErrCode())
+	}
+}
+
+func TestProbe_ClosedUDPPort(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := pc.LocalAddr().String()
+	pc.Close()
+
+	r := Probe(context.Background(), addr, 300*time.Millisecond)
+	if r.Verdict != VerdictServerDown {
+		t.Fatalf("unexpected result: %+v", r)
+	}
+}
+
+func TestDiagnose_ByConnectError(t *testing.T) {
+	cases := map[string]error{
+		VerdictAuthFailed: ers.New(ers.ErrAuthRejected, ers.StageAuth, "401"),
+		VerdictTLSFailed:  ers.New(ers.ErrCertPinMismatch, ers.StageHandshake, ""),
+		VerdictDNSFailed:  &net.DNSError{Err: "no such host", Name: "x"},
+	}
+	for want, err := range cases {
+		if r := Diagnose(context.Background(), "example.invalid:443", err); r.Verdict != want {
+			t.Errorf("Diagnose(%v) = %+v, want %s", err, r, want)
+		}
+	}
+	r := Result{Verdict: VerdictUDPBlocked}
+	if !errors.Is(r.Err(context.DeadlineExceeded), ers.ErrUDPBlocked) {
+		t.Fatal("Result.Err must carry the verdict code")
+	}
+}
+
+// proxyResolver — имя сервера известно только прокси.
+type proxyResolver struct{}
+
+func (proxyResolver) Resolve(ctx context.Context, name string) (context.Context, net.IP, error) {
+	if name == "hy2.detour.invalid" {
+		return ctx, net.IPv4(127, 0, 0, 1), nil
+	}
+	return ctx, nil, errors.New("no such host")
+}
+
+func TestProbe_ViaUpstream(t *testing.T) {
+	protect.SetProtectHook(func(int) bool { return true })
+	defer protect.SetProtectHook(nil)
+
+	// SOCKS5 без UDP ASSOCIATE: UDP через detour не проходит, TCP — проходит.
+	srv, _ := socks5.New(&socks5.Config{Resolver: proxyResolver{}, Logger: log.New(io.Discard, "", 0)})
+	pln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pln.Close()
+	go srv.Serve(pln)
+	if err := upstream.Set(upstream.Config{Type: upstream.TypeSOCKS5, Server: pln.Addr().String()}); err != nil {
+		t.Fatal(err)
+	}
+	defer upstream.Reset()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	server := net.JoinHostPort("hy2.detour.invalid", strconv.Itoa(ln.Addr().(*net.TCPAddr).Port))
+
+	// Локально имя не разрешается: без detour вердикт был бы dns_failed.
+	r := Probe(context.Background(), server, 300*time.Millisecond)
+	if r.Verdict != VerdictUDPBlocked || !r.TCPReachable {
+		t.Fatalf("unexpected result via upstream: %+v", r)
+	}
+}
@@ -0,0 +1,137 @@
+// go:build android || ios || mobile_skel
+
+package wireguard
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/netip"
+	"strconv"
+	"sync"
+
+	"golang.zx2c4.com/wireguard/conn"
+
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/netstack/protect"
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/netstack/upstream"
+)
+
+// protectedBind — conn.Bind на одном защищённом UDP-сокете (VpnService.protect),
+// чтобы зашифрованные пакеты WireGuard не заворачивались обратно в TUN.
+// При настроенном detour сокет — SOCKS5 UDP ASSOCIATE (порт тогда не выбирается).
+type protectedBind struct {
+	mu sync.Mutex
+	pc net.PacketConn
+}
+
+func listenUDP(port uint16) (net.PacketConn, error) {
+	if _, ok := upstream.Active(); ok {
+		return upstream.ListenPacket(context.Background())
+	}
+	return protect.ProtectedListenPacket(context.Background(), net.JoinHostPort("0.0.0.0", strconv.Itoa(int(port))))
+}
+
+func (b *protectedBind) Open(port uint16) ([]conn.ReceiveFunc, uint16, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.pc != nil {
+		return nil, 0, conn.ErrBindAlreadyOpen
+	}
+	pc, err := listenUDP(port)
+	if err != nil {
+		return nil, 0, err
+	}
+	b.pc = pc
+	actual := port
+	if ua, ok := pc.LocalAddr().(*net.UDPAddr); ok {
+		actual = uint16(ua.Port)
+	}
+	return []conn.ReceiveFunc{b.receiveFunc(pc)}, actual, nil
+}
+
+func (b *protectedBind) receiveFunc(pc net.PacketConn) conn.ReceiveFunc {
+	return func(packets [][]byte, sizes []int, eps []conn.Endpoint) (int, error) {
+		for {
+			n, addr, err := pc.ReadFrom(packets[0])
+			if err != nil {
+				if errors.Is(err, net.ErrClosed) {
+					return 0, net.ErrClosed
+				}
+				return 0, err
+			}
+			ap, ok := addrPort(addr)
+			if !ok {
+				continue
+			}
+			sizes[0] = n
+			eps[0] = endpoint(ap)
+			return 1, nil
+		}
+	}
+}
+
+func (b *protectedBind) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.pc == nil {
+		return nil
+	}
+	err := b.pc.Close()
+	b.pc = nil
+	return err
+}
+
+func (b *protectedBind) SetMark(uint32) error { return nil }
+
+func (b *protectedBind) Send(bufs [][]byte, ep conn.Endpoint) error {
+	e, ok := ep.(endpoint)
+	if !ok {
+		return conn.ErrWrongEndpointType
+	}
+	b.mu.Lock()
+	pc := b.pc
+	b.mu.Unlock()
+	if pc == nil {
+		return net.ErrClosed
+	}
+	dst := net.UDPAddrFromAddrPort(netip.AddrPort(e))
+	for _, buf := range bufs {
+		if _, err := pc.WriteTo(buf, dst); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (b *protectedBind) ParseEndpoint(s string) (conn.Endpoint, error) {
+	ap, err := netip.ParseAddrPort(s)
+	if err != nil {
+		return nil, err
+	}
+	return endpoint(ap), nil
+}
+
+func (b *protectedBind) BatchSize() int { return 1 }
+
+func addrPort(a net.Addr) (netip.AddrPort, bool) {
+	ua, ok := a.(*net.UDPAddr)
+	if !ok {
+		return netip.AddrPort{}, false
+	}
+	ap := ua.AddrPort()
+	return netip.AddrPortFrom(ap.Addr().Unmap(), ap.Port()), true
+}
+
+// endpoint — адрес пира; source-адрес не закрепляем (один сокет на всё).
+type endpoint netip.AddrPort
+
+func (e endpoint) ClearSrc()           {}
+func (e endpoint) SrcToString() string { return "" }
+func (e endpoint) DstToString() string { return netip.AddrPort(e).String() }
+func (e endpoint) DstIP() netip.Addr   { return netip.AddrPort(e).Addr() }
+func (e endpoint) SrcIP() netip.Addr   { return netip.Addr{} }
+
+func (e endpoint) DstToBytes() []byte {
+	b, _ := netip.AddrPort(e).MarshalBinary()
+	return b
+}
@@ -0,0 +1,355 @@
+// go:build android || ios || mobile_skel
+
+// Package wireguard — движок "wireguard": userspace WireGuard (wireguard-go)
+// поверх защищённого UDP-сокета. Внутри туннеля работает userspace TCP/IP
+// стек (gVisor, wireguard-go tun/netstack) с адресами интерфейса из
+// конфига, поэтому транспорт несёт потоки как transport.Dialer и
+// transport.PacketDialer — так же, как остальные движки.
+package wireguard
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.zx2c4.com/wireguard/device"
+	"golang.zx2c4.com/wireguard/tun/netstack"
+
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/telemetry"
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/transport"
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/pkg/config"
+	ers "github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/pkg/errors"
+	logpkg "github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/pkg/logging"
+)
+
+// DefaultMTU — MTU туннеля по умолчанию (как у wg-quick).
+const DefaultMTU = 1420
+
+var log = logpkg.With(logpkg.CompTransport)
+
+// Peer — пир WireGuard; ключи в base64.
+type Peer struct {
+	PublicKey    string
+	PresharedKey string
+	Endpoint     string // host:port; host резолвится при Start
+	AllowedIPs   []string
+	KeepaliveS   int
+}
+
+// Config — параметры интерфейса WireGuard. Address — адреса интерфейса
+// внутри туннеля (CIDR или IP), DNS — резолверы внутри туннеля; без DNS
+// имена назначения резолвит системный резолвер.
+type Config struct {
+	PrivateKey string
+	Address    []string
+	DNS        []string
+	ListenPort int
+	MTU        int
+	Peers      []Peer
+}
+
+func init() {
+	transport.Register(transport.Engine{
+		Name: "wireguard",
+		Caps: transport.Caps{TCP: true, UDP: true, Migration: true},
+		New: func(cfg config.HY2Config) (transport.Transport, error) {
+			return NewTransport(FromConfig(cfg.WireGuard)), nil
+		},
+	})
+}
+
+// FromConfig переводит секцию wireguard конфига в Config.
+func FromConfig(wc config.WireGuardConfig) Config {
+	c := Config{PrivateKey: wc.PrivateKey, Address: wc.Address, DNS: wc.DNS, ListenPort: wc.ListenPort, MTU: wc.MTU}
+	for _, p := range wc.Peers {
+		c.Peers = append(c.Peers, Peer{
+			PublicKey:    p.PublicKey,
+			PresharedKey: p.PresharedKey,
+			Endpoint:     p.Endpoint,
+			AllowedIPs:   p.AllowedIPs,
+			KeepaliveS:   p.KeepaliveS,
+		})
+	}
+	return c
+}
+
+type transportWG struct {
+	cfg Config
+
+	mu    sync.Mutex
+	dev   *device.Device
+	tnet  *netstack.Net
+	local netip.Addr // адрес для UDP-сокетов внутри туннеля
+	rem   string
+	lastE atomic.Value // string
+}
+
+// NewTransport создаёт WireGuard-транспорт.
+func NewTransport(cfg Config) *transportWG {
+	if cfg.MTU == 0 {
+		cfg.MTU = DefaultMTU
+	}
+	return &transportWG{cfg: cfg}
+}
+
+// Start поднимает устройство WireGuard. Рукопожатие ленивое: оно случится
+// на первом пакете (или keepalive), поэтому Start не ждёт ответа пира.
+func (t *transportWG) Start(ctx context.Context) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.dev != nil {
+		return nil
+	}
+	hs := time.Now()
+	local, err := parseAddrs(t.cfg.Address)
+	if err == nil && len(local) == 0 {
+		err = errors.New("wireguard: interface address required")
+	}
+	if err != nil {
+		telemetry.TransportConnectResult("", hs, 0, err)
+		return t.recordErr(err, ers.StageConfig)
+	}
+	dns, err := parseAddrs(t.cfg.DNS)
+	if err != nil {
+		telemetry.TransportConnectResult("", hs, 0, err)
+		return t.recordErr(err, ers.StageConfig)
+	}
+	uapi, rem, err := t.uapi(ctx)
+	if err != nil {
+		telemetry.TransportConnectResult(rem, hs, 0, err)
+		return t.recordErr(err, ers.StageResolve)
+	}
+	tdev, tnet, err := netstack.CreateNetTUN(local, dns, t.cfg.MTU)
+	if err != nil {
+		telemetry.TransportConnectResult(rem, hs, 0, err)
+		return t.recordErr(err, ers.StageRuntime)
+	}
+	dev := device.NewDevice(tdev, &protectedBind{}, &device.Logger{
+		Verbosef: func(format string, args ...any) { log.Debug(fmt.Sprintf(format, args...)) },
+		Errorf:   func(format string, args ...any) { log.Warn(fmt.Sprintf(format, args...)) },
+	})
+	if err = dev.IpcSet(uapi); err == nil {
+		err = dev.Up()
+	}
+	telemetry.TransportConnectResult(rem, hs, 0, err)
+	if err != nil {
+		dev.Close()
+		return t.recordErr(err, ers.StageDial)
+	}
+	t.dev, t.tnet, t.local, t.rem = dev, tnet, local[0], rem
+	log.Info("wireguard device up", logpkg.F("endpoint", rem), logpkg.F("peers", len(t.cfg.Peers)))
+	return nil
+}
+
+func (t *transportWG) Stop(context.Context) error {
+	t.mu.Lock()
+	dev := t.dev
+	t.dev, t.tnet = nil, nil
+	t.mu.Unlock()
+	if dev != nil {
+		dev.Close()
+	}
+	return nil
+}
+
+func (t *transportWG) Status() transport.TransportStatus {
+	t.mu.Lock()
+	st := transport.TransportStatus{Remote: t.rem}
+	t.mu.Unlock()
+	if v, ok := t.lastE.Load().(string); ok {
+		st.LastErr = v
+	}
+	return st
+}
+
+// DialContext открывает поток через туннель: стек внутри туннеля
+// подключается к addr с адреса интерфейса.
+func (t *transportWG) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	tnet, _, err := t.stack()
+	if err != nil {
+		return nil, err
+	}
+	if addr, err = t.resolve(ctx, addr); err != nil {
+		return nil, err
+	}
+	return tnet.DialContext(ctx, network, addr)
+}
+
+// ListenPacket открывает UDP-сокет внутри туннеля.
+func (t *transportWG) ListenPacket(ctx context.Context) (net.PacketConn, error) {
+	tnet, local, err := t.stack()
+	if err != nil {
+		return nil, err
+	}
+	pc, err := tnet.ListenUDPAddrPort(netip.AddrPortFrom(local, 0))
+	if err != nil {
+		return nil, err
+	}
+	return &tunnelPacketConn{PacketConn: pc, t: t}, nil
+}
+
+func (t *transportWG) stack() (*netstack.Net, netip.Addr, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.tnet == nil {
+		return nil, netip.Addr{}, ers.New(ers.ErrNotRunning, ers.StageRuntime, "wireguard transport not started")
+	}
+	return t.tnet, t.local, nil
+}
+
+// resolve переводит host:port назначения в IP:port, если DNS внутри
+// туннеля не задан (иначе имя резолвит стек туннеля).
+func (t *transportWG) resolve(ctx context.Context, addr string) (string, error) {
+	if len(t.cfg.DNS) > 0 {
+		return addr, nil
+	}
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", err
+	}
+	if _, err := netip.ParseAddr(host); err == nil {
+		return addr, nil
+	}
+	return resolve(ctx, addr)
+}
+
+// tunnelPacketConn — UDP-сокет стека туннеля. gonet принимает в WriteTo
+// только *net.UDPAddr, а инбаунды и пробросы передают и host:port.
+type tunnelPacketConn struct {
+	net.PacketConn
+	t *transportWG
+}
+
+func (c *tunnelPacketConn) WriteTo(p []byte, addr net.Addr) (int, error) {
+	ua, ok := addr.(*net.UDPAddr)
+	if !ok {
+		hp, err := c.t.resolve(context.Background(), addr.String())
+		if err != nil {
+			return 0, err
+		}
+		ap, err := netip.ParseAddrPort(hp)
+		if err != nil {
+			return 0, err
+		}
+		ua = net.UDPAddrFromAddrPort(ap)
+	}
+	if ip4 := ua.IP.To4(); ip4 != nil {
+		ua = &net.UDPAddr{IP: ip4, Port: ua.Port}
+	}
+	return c.PacketConn.WriteTo(p, ua)
+}
+
+func (t *transportWG) recordErr(err error, stage ers.Stage) error {
+	e := ers.Classify(err, stage)
+	t.lastE.Store(e.Error())
+	telemetry.SetLastErrTs(time.Now().Unix())
+	return e
+}
+
+// uapi собирает конфигурацию устройства в формате UAPI (ключи — hex,
+// endpoint — IP:port). Второе значение — endpoint первого пира для статуса.
+func (t *transportWG) uapi(ctx context.Context) (string, string, error) {
+	var b strings.Builder
+	key, err := hexKey(t.cfg.PrivateKey)
+	if err != nil {
+		return "", "", fmt.Errorf("wireguard: private key: %w", err)
+	}
+	fmt.Fprintf(&b, "private_key=%s\nlisten_port=%d\nreplace_peers=true\n", key, t.cfg.ListenPort)
+	rem := ""
+	for i, p := range t.cfg.Peers {
+		pub, err := hexKey(p.PublicKey)
+		if err != nil {
+			return "", rem, fmt.Errorf("wireguard: peer %d public key: %w", i, err)
+		}
+		fmt.Fprintf(&b, "public_key=%s\n", pub)
+		if p.PresharedKey != "" {
+			psk, err := hexKey(p.PresharedKey)
+			if err != nil {
+				return "", rem, fmt.Errorf("wireguard: peer %d preshared key: %w", i, err)
+			}
+			fmt.Fprintf(&b, "preshared_key=%s\n", psk)
+		}
+		if p.Endpoint != "" {
+			ep, err := resolve(ctx, p.Endpoint)
+			if err != nil {
+				return "", p.Endpoint, err
+			}
+			if rem == "" {
+				rem = ep
+			}
+			fmt.Fprintf(&b, "endpoint=%s\n", ep)
+		}
+		if p.KeepaliveS > 0 {
+			fmt.Fprintf(&b, "persistent_keepalive_interval=%d\n", p.KeepaliveS)
+		}
+		b.WriteString("replace_allowed_ips=true\n")
+		for _, a := range p.AllowedIPs {
+			fmt.Fprintf(&b, "allowed_ip=%s\n", a)
+		}
+	}
+	return b.String(), rem, nil
+}
+
+// parseAddrs разбирает адреса интерфейса/DNS: CIDR ("10.0.0.2/32") или IP.
+func parseAddrs(ss []string) ([]netip.Addr, error) {
+	out := make([]netip.Addr, 0, len(ss))
+	for _, s := range ss {
+		if p, err := netip.ParsePrefix(s); err == nil {
+			out = append(out, p.Addr())
+			continue
+		}
+		a, err := netip.ParseAddr(s)
+		if err != nil {
+			return nil, fmt.Errorf("wireguard: bad address %q", s)
+		}
+		out = append(out, a)
+	}
+	return out, nil
+}
+
+func hexKey(s string) (string, error) {
+	k, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return "", err
+	}
+	if len(k) != 32 {
+		return "", errors.New("key must be 32 bytes")
+	}
+	return hex.EncodeToString(k), nil
+}
+
+// resolve переводит host:port в IP:port (UAPI не принимает имена).
+func resolve(ctx context.Context, hostport string) (string, error) {
+	host, port, err := net.SplitHostPort(hostport)
+	if err != nil {
+		return "", err
+	}
+	if ip, err := netip.ParseAddr(host); err == nil {
+		return netip.AddrPortFrom(ip.Unmap(), mustPort(port)).String(), nil
+	}
+	ips, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return "", fmt.Errorf("wireguard: resolve %s: %w", host, err)
+	}
+	if len(ips) == 0 {
+		return "", fmt.Errorf("wireguard: resolve %s: no addresses", host)
+	}
+	return netip.AddrPortFrom(ips[0].Unmap(), mustPort(port)).String(), nil
+}
+
+func mustPort(s string) uint16 {
+	p, _ := strconv.ParseUint(s, 10, 16)
+	return uint16(p)
+}
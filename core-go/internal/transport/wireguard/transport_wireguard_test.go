@@ -0,0 +1,170 @@
+//go:build mobile_skel
+
+package wireguard
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+	"net"
+	"net/netip"
+	"strconv"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/curve25519"
+
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/netstack/protect"
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/transport"
+)
+
+func init() { protect.SetProtectHook(func(int) bool { return true }) }
+
+func keyPair(t *testing.T) (priv, pub string) {
+	t.Helper()
+	var sk [32]byte
+	if _, err := rand.Read(sk[:]); err != nil {
+		t.Fatal(err)
+	}
+	sk[0] &= 248
+	sk[31] = (sk[31] & 127) | 64
+	pk, err := curve25519.X25519(sk[:], curve25519.Basepoint)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return base64.StdEncoding.EncodeToString(sk[:]), base64.StdEncoding.EncodeToString(pk)
+}
+
+func freeUDPPort(t *testing.T) int {
+	t.Helper()
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+	return pc.LocalAddr().(*net.UDPAddr).Port
+}
+
+// hostPort — адрес назначения строкой, как его передают пробросы.
+type hostPort string
+
+func (a hostPort) Network() string { return "udp" }
+func (a hostPort) String() string  { return string(a) }
+
+func TestWireGuard_LoopbackPeers(t *testing.T) {
+	privA, pubA := keyPair(t)
+	privB, pubB := keyPair(t)
+	port := freeUDPPort(t)
+
+	b := NewTransport(Config{
+		PrivateKey: privB,
+		Address:    []string{"10.0.0.2/32"},
+		ListenPort: port,
+		Peers:      []Peer{{PublicKey: pubA, AllowedIPs: []string{"10.0.0.1/32"}}},
+	})
+	a := NewTransport(Config{
+		PrivateKey: privA,
+		Address:    []string{"10.0.0.1/32"},
+		Peers: []Peer{{
+			PublicKey:  pubB,
+			Endpoint:   net.JoinHostPort("127.0.0.1", strconv.Itoa(port)),
+			AllowedIPs: []string{"10.0.0.0/24"},
+		}},
+	})
+	ctx := context.Background()
+	for _, tr := range []*transportWG{b, a} {
+		if err := tr.Start(ctx); err != nil {
+			t.Fatalf("Start: %v", err)
+		}
+		defer tr.Stop(ctx)
+	}
+	if got := a.Status().Remote; got != net.JoinHostPort("127.0.0.1", strconv.Itoa(port)) {
+		t.Fatalf("Status().Remote = %q", got)
+	}
+
+	// На стороне b — сервисы внутри туннеля (адрес 10.0.0.2).
+	bnet, _, err := b.stack()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ln, err := bnet.ListenTCPAddrPort(netip.MustParseAddrPort("10.0.0.2:8080"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() { defer c.Close(); io.Copy(c, c) }()
+		}
+	}()
+	upc, err := bnet.ListenUDPAddrPort(netip.MustParseAddrPort("10.0.0.2:5353"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer upc.Close()
+	go func() {
+		buf := make([]byte, 2048)
+		for {
+			n, from, err := upc.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			upc.WriteTo(buf[:n], from)
+		}
+	}()
+
+	dctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	c, err := a.DialContext(dctx, "tcp", "10.0.0.2:8080")
+	if err != nil {
+		t.Fatalf("DialContext through the tunnel: %v", err)
+	}
+	defer c.Close()
+	c.SetDeadline(time.Now().Add(5 * time.Second))
+	c.Write([]byte("ping"))
+	buf := make([]byte, 4)
+	if _, err := io.ReadFull(c, buf); err != nil || string(buf) != "ping" {
+		t.Fatalf("tcp echo: %q %v", buf, err)
+	}
+
+	pc, err := a.ListenPacket(ctx)
+	if err != nil {
+		t.Fatalf("ListenPacket: %v", err)
+	}
+	defer pc.Close()
+	pc.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for _, dst := range []net.Addr{&net.UDPAddr{IP: net.IPv4(10, 0, 0, 2), Port: 5353}, hostPort("10.0.0.2:5353")} {
+		if _, err := pc.WriteTo([]byte("dns?"), dst); err != nil {
+			t.Fatalf("WriteTo %v: %v", dst, err)
+		}
+		n, from, err := pc.ReadFrom(buf)
+		if err != nil || string(buf[:n]) != "dns?" || from.String() != "10.0.0.2:5353" {
+			t.Fatalf("udp echo via %T: %q from %v, %v", dst, buf[:n], from, err)
+		}
+	}
+}
+
+func TestWireGuard_NotStarted(t *testing.T) {
+	tr := NewTransport(Config{})
+	if _, err := tr.DialContext(context.Background(), "tcp", "10.0.0.2:80"); err == nil {
+		t.Fatal("DialContext before Start must fail")
+	}
+	if !transport.CarriesTCP(tr) || !transport.CarriesUDP(tr) {
+		t.Fatal("wireguard must carry tcp and udp flows")
+	}
+	if err := tr.Start(context.Background()); err == nil {
+		t.Fatal("Start without an interface address must fail")
+	}
+}
+
+func TestWireGuard_Registered(t *testing.T) {
+	e, ok := transport.Lookup("wireguard")
+	if !ok || !e.Caps.UDP || e.Caps.Obfs {
+		t.Fatalf("wireguard engine not registered as expected: %+v ok=%v", e, ok)
+	}
+}
@@ -14,16 +14,20 @@
 package mobile
 
 import (
+	"encoding/json"
 	"sync"
 
 	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/pkg/config"
 	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/pkg/errors"
 	logpkg "github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/pkg/logging"
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/pkg/version"
 
 	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/runtime"
 	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/telemetry"
 )
 
+var log = logpkg.With(logpkg.CompCore)
+
 var (
 	// mu защищает глобальное состояние (started, cfgRaw и пр.)
 	Mu sync.Mutex
@@ -32,7 +36,7 @@ var (
 	Started bool
 
 	// sdkName/sdVersion/engineID — метаданные SDK, видимые в Version().
-	SdkName = "Bereznev-HY2-Core"
+	SdkName = version.SdkName
 )
 
 // Коды ошибок для мобильных биндингов (удобны для Kotlin/Swift):
@@ -42,7 +46,7 @@ var (
 // Используется там, где удобнее строковая ошибка (например, простая интеграция в Kotlin).
 //
 // Побочные эффекты:
-//   - сохраняет валидный конфиг,
+//   - сохраняет валидный конфиг (старой версии — мигрированным, его отдаёт CfgGet),
 //   - включает состояние started,
 //   - эмитит событие "started".
 //
@@ -56,20 +60,21 @@ func Start(configJSON string) string {
 		return ""
 	}
 
-	// 1) валидируем и сохраняем конфиг
-	if err := CfgSet(configJSON); err != nil {
+	// 1) валидируем (все ошибки сразу, см. ValidateConfig) и сохраняем конфиг
+	if _, err := ApplyConfig(configJSON); err != nil {
 		return "invalid config: " + err.Error()
 	}
 
 	// 2) поднимаем рантайм (sing/hy2 транспорт и пр.)
 	if err := runtime.RuntimeStart(); err != nil {
-		telemetry.EmitError(int(errors.ErrEngineInitFailed), err.Error())
+		telemetry.EmitErr(err, errors.StageRuntime)
 		return "engine init failed: " + err.Error()
 	}
 
+	applyInboundAuth()
 	Started = true
 	telemetry.HealthMarkStarted()
-	logpkg.LogI("HY2 core started")
+	log.Info("HY2 core started")
 	return ""
 }
 
@@ -77,7 +82,8 @@ func Start(configJSON string) string {
 // Удобно для строго типизированных мобильных вызовов (Kotlin/Swift),
 // чтобы не парсить строки.
 //
-// Коды возврата: ErrOK, ErrAlreadyRunning, ErrInvalidConfig, ErrEngineInitFailed (на будущее).
+// Коды возврата: ErrOK, ErrInvalidConfig или код классифицированной ошибки
+// старта (ErrDNSFailure, ErrTLSHandshake, ErrServerUnreachable, ...; см. pkg/errors).
 // Потокобезопасно.
 func StartWithCode(configJSON string) errors.ErrCode {
 	Mu.Lock()
@@ -86,28 +92,34 @@ func StartWithCode(configJSON string) errors.ErrCode {
 	if Started {
 		return errors.ErrOK // считаем idempotent запуск «не ошибкой»
 	}
-	if err := CfgSet(configJSON); err != nil {
+	if _, err := ApplyConfig(configJSON); err != nil {
 		return errors.ErrInvalidConfig
 	}
 	if err := runtime.RuntimeStart(); err != nil {
-		telemetry.EmitError(int(errors.ErrEngineInitFailed), err.Error())
-		return errors.ErrEngineInitFailed
+		telemetry.EmitErr(err, errors.StageRuntime)
+		return errors.CodeOf(err)
 	}
+	applyInboundAuth()
 	Started = true
-	logpkg.LogI("HY2 core started; config accepted")
+	log.Info("HY2 core started; config accepted")
 	return errors.ErrOK
 }
 
 // Reload безопасно применяет новый конфиг во время работы.
 // Возвращает пустую строку при успехе, иначе текст ошибки валидации.
-// В skeleton-режиме просто заменяет сохранённый конфиг и шлёт "reloaded".
+// Изменения применяются на месте (маршрутизация, логи, инбаунды, метрики, ...);
+// транспорт переподключается, только если изменились его поля (server/auth/TLS).
+// Событие "reloaded" перечисляет применённое:
+//
+//	{"applied":["routing","logging"],"fields":["route","log"],"reconnected":false}
+//
 // Потокобезопасно.
 func Reload(configJSON string) string {
 	Mu.Lock()
 	defer Mu.Unlock()
 
 	// 1) валидируем и сохраняем конфиг (если невалиден — ничего не меняем)
-	if err := CfgSet(configJSON); err != nil {
+	if _, err := ApplyConfig(configJSON); err != nil {
 		return "invalid config: " + err.Error()
 	}
 	// 2) если не запущено — просто отдадим событие перезагрузки конфигурации
@@ -116,23 +128,29 @@ func Reload(configJSON string) string {
 		return ""
 	}
 
-	// 3) перезапускаем рантайм по новому конфигу
-	// 3) если новый конфиг не задаёт HY2 — не трогаем транспорт
+	// 3) если новый конфиг не задаёт HY2 — не трогаем рантайм
 	if _, err := config.ParseHY2Config(); err != nil {
 		telemetry.Emit(telemetry.EvtReloaded, "{}")
-		logpkg.LogI("config reloaded (no HY2 changes)")
+		log.Info("config reloaded (no HY2 changes)")
 		return ""
 	}
-	// 4) иначе — перезапускаем рантайм
-	runtime.RuntimeStop()
-	if err := runtime.RuntimeStart(); err != nil {
-		telemetry.EmitError(int(errors.ErrEngineInitFailed), err.Error())
+	// 4) применяем разницу; если транспорт не поднялся — ядро остановлено
+	d, err := runtime.RuntimeReload()
+	if err != nil {
+		telemetry.EmitErr(err, errors.StageRuntime)
+		runtime.RuntimeStop()
 		Started = false
 		return "engine init failed: " + err.Error()
 	}
-	Started = true
-	telemetry.Emit(telemetry.EvtReloaded, "{}")
-	logpkg.LogI("HY2 core reloaded")
+	if d.Has(config.ChangeInbound) {
+		applyInboundAuth()
+	}
+	b, _ := json.Marshal(struct {
+		config.ConfigDiff
+		Reconnected bool `json:"reconnected"`
+	}{d, d.Has(config.ChangeTransport)})
+	telemetry.Emit(telemetry.EvtReloaded, string(b))
+	log.Info("HY2 core reloaded", logpkg.F("applied", d.Categories))
 	return ""
 }
 
@@ -149,7 +167,7 @@ func Stop() {
 	Started = false
 	telemetry.HealthMarkStopped()
 	telemetry.Emit("stopped", "{}")
-	logpkg.LogI("HY2 core stopped")
+	log.Info("HY2 core stopped")
 }
 
 // Status возвращает строковый статус: "running" или "stopped".
@@ -6,6 +6,13 @@ import (
 	"strings"
 	"sync"
 	"testing"
+
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/netstack/protect"
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/runtime"
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/telemetry"
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/pkg/errors"
+	logpkg "github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/pkg/logging"
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/pkg/version"
 )
 
 /********* helpers *********/
@@ -36,24 +43,31 @@ func (l *testLogSink) Log(level, msg string) {
 
 // сброс глобального состояния между тестами
 func resetState() {
-	mu.Lock()
-	defer mu.Unlock()
+	Mu.Lock()
+	defer Mu.Unlock()
 
 	// best-effort stop рантайма, если вдруг поднят
-	runtimeStop()
-
-	started = false
-	cfgRaw = nil
-	logLevel = "info"
-	evt = nil
-	sink = nil
-
-	netHooks = struct {
-		mu      sync.RWMutex
-		protect func(fd int) bool
-	}{}
+	runtime.RuntimeStop()
+
+	Started = false
+	CfgRaw = nil
+	logpkg.SetLogLevel("info")
+	telemetry.SetEventSink(nil)
+	logpkg.SetLogger(nil)
+	protect.SetProtectHook(nil)
+}
+
+// старые имена из skeleton-версии пакета
+func cfgSet(s string) error {
+	_, err := CfgSet(s)
+	return err
 }
 
+func cfgGet() []byte { return CfgGet() }
+
+func logD(m string) { log.Debug(m) }
+func logI(m string) { log.Info(m) }
+
 /********* tests *********/
 
 const validCfg = `{
@@ -84,7 +98,7 @@ func TestStartStopStatus(t *testing.T) {
 
 	hasStarted := false
 	for _, ev := range es.events {
-		if ev == EvtStarted || ev == "started" {
+		if ev == telemetry.EvtStarted || ev == "started" {
 			hasStarted = true
 			break
 		}
@@ -105,7 +119,7 @@ func TestStartStopStatus(t *testing.T) {
 	if Status() != "stopped" {
 		t.Fatalf("expected status 'stopped' after Stop, got %q", Status())
 	}
-	if len(es.events) < 2 || (es.events[len(es.events)-1] != EvtStopped && es.events[len(es.events)-1] != "stopped") {
+	if len(es.events) < 2 || (es.events[len(es.events)-1] != telemetry.EvtStopped && es.events[len(es.events)-1] != "stopped") {
 		t.Fatalf("expected 'stopped' event, got %#v", es.events)
 	}
 }
@@ -113,12 +127,12 @@ func TestStartStopStatus(t *testing.T) {
 func TestStartWithCode(t *testing.T) {
 	resetState()
 	code := StartWithCode(validCfg)
-	if code != ErrOK {
-		t.Fatalf("StartWithCode(valid) = %d, want %d", code, ErrOK)
+	if code != errors.ErrOK {
+		t.Fatalf("StartWithCode(valid) = %d, want %d", code, errors.ErrOK)
 	}
 	code2 := StartWithCode(validCfg)
-	if code2 != ErrOK {
-		t.Fatalf("StartWithCode(second) = %d, want %d", code2, ErrOK)
+	if code2 != errors.ErrOK {
+		t.Fatalf("StartWithCode(second) = %d, want %d", code2, errors.ErrOK)
 	}
 }
 
@@ -147,7 +161,7 @@ func TestReload(t *testing.T) {
 	}
 	found := false
 	for _, ev := range es.events {
-		if ev == EvtReloaded || ev == "reloaded" {
+		if ev == telemetry.EvtReloaded || ev == "reloaded" {
 			found = true
 			break
 		}
@@ -163,8 +177,8 @@ func TestReload(t *testing.T) {
 
 func TestVersionFormat(t *testing.T) {
 	resetState()
-	v := Version()
-	if !strings.Contains(v, sdkName) || !strings.Contains(v, SdkVersion) || !strings.Contains(v, EngineID) {
+	v := version.Version()
+	if !strings.Contains(v, SdkName) || !strings.Contains(v, version.SdkVersion) || !strings.Contains(v, version.EngineID) {
 		t.Fatalf("Version() %q must contain sdkName, sdkVersion and engineID", v)
 	}
 }
@@ -4,7 +4,7 @@
 // Этот файл отвечает за работу с конфигурацией HY2 Core:
 // хранение, валидацию и безопасное чтение JSON-конфига.
 //
-// Здесь используется расширенный парсер sing/common/json, который:
+// Здесь используется расширенный парсер sing/common/json (через config.Migrate), который:
 //   - поддерживает комментарии в JSON (//, /* */),
 //   - допускает нестрогие ключи,
 //   - совместим с синтаксисом sing-box/sing-tun.
@@ -12,7 +12,7 @@
 // Потокобезопасность обеспечивается на уровне вызывающих функций (см. api.go).
 package mobile
 
-import sjson "github.com/sagernet/sing/common/json"
+import "github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/pkg/config"
 
 var (
 	// cfgRaw — последний успешно применённый конфиг в виде байт.
@@ -20,32 +20,38 @@ var (
 	CfgRaw []byte
 )
 
-// cfgSet выполняет валидацию и сохраняет конфигурацию SDK.
-// Использует sjson.UnmarshalExtended для поддержки комментариев и расширенного синтаксиса.
+// CfgSet проверяет синтаксис конфига, приводит его к актуальной версии
+// формата (config.Migrate) и сохраняет. Возвращает сохранённый JSON: для
+// документа актуальной версии — его же, для старого — мигрированный, который
+// приложению стоит записать вместо исходного. Устаревшие поля попадают в лог
+// предупреждениями.
 //
 // Аргументы:
-//   - jsonStr — строка JSON-конфига.
+//   - jsonStr — строка JSON-конфига (комментарии допускаются).
 //
 // Возвращает:
-//   - nil — если JSON корректен;
-//   - error — если формат некорректный или невалидный.
+//   - сохранённый JSON и nil — если JSON корректен;
+//   - error — если формат некорректный или версия конфига новее поддерживаемой.
 //
 // Побочные эффекты:
-//   - перезаписывает cfgRaw (глобальное состояние).
+//   - перезаписывает CfgRaw (глобальное состояние).
 //
 // Пример:
 //
-//	err := cfgSet(`{ "inbounds": [], "outbounds": [] }`)
+//	migrated, err := CfgSet(`{ "server": "example.com:443", "password": "p" }`)
 //	if err != nil {
 //	    log.Println("invalid config:", err)
 //	}
-func CfgSet(jsonStr string) error {
-	// валидация расширенным парсером (комментарии, расширенные поля и т.п.)
-	if _, err := sjson.UnmarshalExtended[map[string]any]([]byte(jsonStr)); err != nil {
-		return err
+func CfgSet(jsonStr string) (string, error) {
+	migrated, diags, err := config.Migrate([]byte(jsonStr))
+	if err != nil {
+		return "", err
 	}
-	CfgRaw = []byte(jsonStr)
-	return nil
+	for _, d := range diags {
+		log.Warn("config: " + d.Error())
+	}
+	CfgRaw = migrated
+	return string(migrated), nil
 }
 
 // cfgGet возвращает текущий сохранённый конфиг в виде байтового среза.
@@ -44,8 +44,8 @@ func TestCfgSet_InvalidConfig(t *testing.T) {
 		t.Fatal("cfgSet(invalid) expected error, got nil")
 	}
 
-	if cfgRaw != nil && len(cfgRaw) > 0 {
-		t.Fatalf("cfgRaw should not be modified on invalid config, got %s", string(cfgRaw))
+	if CfgRaw != nil && len(CfgRaw) > 0 {
+		t.Fatalf("CfgRaw should not be modified on invalid config, got %s", string(CfgRaw))
 	}
 }
 
@@ -0,0 +1,21 @@
+//go:build android || ios || mobile_skel
+
+package mobile
+
+import "github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/netstack/conntrack"
+
+// ConnectionsJSON возвращает снимок активных потоков для debug-экрана:
+//
+//	[{"id":"7","inbound":"socks","network":"tcp","source":"127.0.0.1:40122",
+//	  "destination":"1.1.1.1:443","domain":"one.one.one.one","rule":"final",
+//	  "outbound":"proxy","start":1730000000,"upload":512,"download":2048}]
+//
+// Потокобезопасно.
+func ConnectionsJSON() string { return conntrack.SnapshotJSON() }
+
+// CloseConnection рвёт поток с идентификатором id.
+// Возвращает false, если такого потока уже нет.
+func CloseConnection(id string) bool { return conntrack.CloseConnection(id) }
+
+// CloseAllConnections рвёт все активные потоки и возвращает их количество.
+func CloseAllConnections() int { return conntrack.CloseAll() }
@@ -0,0 +1,26 @@
+//go:build android || ios || mobile_skel
+
+package mobile
+
+import (
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/runtime"
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/telemetry"
+)
+
+// ExportDiagnostics собирает диагностический zip в каталоге dir
+// (например, cacheDir приложения) и возвращает путь к файлу.
+// В бандл входят конфиг без секретов, HealthJSON, версия/сборка, буфер событий,
+// последние логи (и файлы файлового лога), таблица потоков, статистика
+// маршрутизации и статус транспорта — см. telemetry.WriteDiagnostics.
+//
+// Потокобезопасно.
+func ExportDiagnostics(dir string) (string, error) {
+	in := telemetry.DiagnosticsInput{}
+	Mu.Lock()
+	in.ConfigJSON = CfgGet()
+	if runtime.RtStarted && runtime.RtTrans != nil {
+		in.Transport = runtime.RtTrans.Status()
+	}
+	Mu.Unlock()
+	return telemetry.WriteDiagnosticsFile(dir, in)
+}
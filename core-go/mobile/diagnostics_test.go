@@ -0,0 +1,86 @@
+//go:build mobile_skel
+
+package mobile
+
+import (
+	"archive/zip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExportDiagnostics(t *testing.T) {
+	cases := []struct {
+		name    string
+		cfg     string
+		dir     func(t *testing.T) string
+		wantErr bool
+	}{
+		{name: "valid", cfg: validCfg, dir: func(t *testing.T) string { return t.TempDir() }},
+		{name: "no config", dir: func(t *testing.T) string { return t.TempDir() }},
+		{name: "engine not in build", cfg: `{"server":"example.com:443","password":"testpass","engine":"hc"}`,
+			dir: func(t *testing.T) string { return t.TempDir() }},
+		{name: "dir is a file", cfg: validCfg, wantErr: true, dir: func(t *testing.T) string {
+			p := filepath.Join(t.TempDir(), "file")
+			if err := os.WriteFile(p, nil, 0o644); err != nil {
+				t.Fatal(err)
+			}
+			return p
+		}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			resetState()
+			defer resetState()
+			if tc.cfg != "" {
+				CfgRaw = []byte(tc.cfg)
+			}
+
+			path, err := ExportDiagnostics(tc.dir(t))
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("ExportDiagnostics = %q, want error", path)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ExportDiagnostics: %v", err)
+			}
+			cfg := readZipFile(t, path, "config.json")
+			if strings.Contains(cfg, "testpass") {
+				t.Fatalf("password leaked into the bundle: %s", cfg)
+			}
+			if tc.cfg != "" && !strings.Contains(cfg, "example.com:443") {
+				t.Fatalf("config.json lost the server: %s", cfg)
+			}
+		})
+	}
+}
+
+func readZipFile(t *testing.T, path, name string) string {
+	t.Helper()
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		t.Fatalf("open bundle: %v", err)
+	}
+	defer zr.Close()
+	for _, f := range zr.File {
+		if f.Name != name {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer rc.Close()
+		b, err := io.ReadAll(rc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(b)
+	}
+	t.Fatalf("bundle has no %s", name)
+	return ""
+}
@@ -0,0 +1,25 @@
+//go:build android || ios || mobile_skel
+
+package mobile
+
+import (
+	"encoding/json"
+
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/transport"
+
+	// Движки регистрируются в реестре транспорта из init() своих пакетов;
+	// hc подключается отдельно под тегом hc (engines_hc.go).
+	_ "github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/transport/sing"
+	_ "github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/transport/trojan"
+	_ "github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/transport/wireguard"
+)
+
+// AvailableEngines возвращает движки, включённые в текущую сборку (значения config.engine):
+//
+//	[{"name":"sing","caps":{"tcp":true,"udp":true,"migration":true,"obfs":true}},
+//	 {"name":"trojan","caps":{"tcp":true,"udp":false,"migration":false,"obfs":false}},
+//	 {"name":"wireguard","caps":{"tcp":true,"udp":true,"migration":true,"obfs":false}}]
+func AvailableEngines() string {
+	b, _ := json.Marshal(transport.Engines())
+	return string(b)
+}
@@ -0,0 +1,5 @@
+//go:build (android || ios || mobile_skel) && hc
+
+package mobile
+
+import _ "github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/transport/hy2hc"
@@ -0,0 +1,13 @@
+//go:build android || ios || mobile_skel
+
+package mobile
+
+import "github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/netstack/forward"
+
+// ForwardingJSON возвращает пробросы портов из секции forwarding со счётчиками:
+//
+//	[{"network":"tcp","listen":"127.0.0.1:2222","remote":"10.0.0.5:22",
+//	  "bytes_up":512,"bytes_down":2048,"active":1,"total":3,"failures":0}]
+//
+// Пробросы поднимаются после подключения транспорта и живут до Stop/Reload.
+func ForwardingJSON() string { return forward.StatsJSON() }
@@ -0,0 +1,42 @@
+//go:build android || ios || mobile_skel
+
+package mobile
+
+import (
+	"encoding/json"
+
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/netstack/socks"
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/pkg/config"
+)
+
+// applyInboundAuth переносит inbound.* из конфига в локальные инбаунды.
+// Случайные креды (random_auth) генерируются заново на каждый Start.
+func applyInboundAuth() {
+	hc, err := config.ParseHY2Config()
+	if err != nil {
+		return
+	}
+	switch {
+	case hc.Inbound.Username != "":
+		socks.SetInboundAuth(hc.Inbound.Username, hc.Inbound.Password)
+	case hc.Inbound.RandomAuth:
+		socks.GenerateInboundAuth()
+	default:
+		socks.SetInboundAuth("", "")
+	}
+}
+
+// InboundCredentials возвращает текущие креды локальных SOCKS/HTTP инбаундов:
+// {"username":"hy2-1a2b3c4d","password":"..."} или {} если auth выключен.
+// Нужны сторонним клиентам инбаундов; TUN-мост их не использует.
+func InboundCredentials() string {
+	user, pass := socks.InboundAuth()
+	if user == "" {
+		return "{}"
+	}
+	b, _ := json.Marshal(struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}{user, pass})
+	return string(b)
+}
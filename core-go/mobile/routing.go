@@ -0,0 +1,37 @@
+//go:build android || ios || mobile_skel
+
+package mobile
+
+import (
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/netstack/protect"
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/telemetry"
+)
+
+// OwnerResolver — платформенный резолвер владельца потока (см. protect.OwnerResolver).
+//
+// Пример (Kotlin):
+//
+//	Mobile.setOwnerResolver(object : OwnerResolver {
+//	    override fun findOwner(network: String, srcIP: String, srcPort: Long,
+//	                           dstIP: String, dstPort: Long): String {
+//	        val uid = cm.getConnectionOwnerUid(proto(network), src, dst)
+//	        return pm.getNameForUid(uid) ?: ""
+//	    }
+//	})
+type OwnerResolver interface {
+	FindOwner(network, srcIP string, srcPort int, dstIP string, dstPort int) string
+}
+
+// SetOwnerResolver регистрирует резолвер владельца потока для правил
+// route.rules[].package_name / bundle_id. nil снимает резолвер.
+func SetOwnerResolver(r OwnerResolver) {
+	if r == nil {
+		protect.SetOwnerResolver(nil)
+		return
+	}
+	protect.SetOwnerResolver(r)
+}
+
+// AppStatsJSON возвращает per-app счётчики трафика:
+// [{"app":"org.example","bytes_in":..,"bytes_out":..,"flows":..}, ...]
+func AppStatsJSON() string { return telemetry.AppStatsJSON() }
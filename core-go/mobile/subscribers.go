@@ -0,0 +1,111 @@
+//go:build android || ios || mobile_skel
+
+package mobile
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/telemetry"
+	logpkg "github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/pkg/logging"
+)
+
+// EventSink — приёмник событий ядра (реализуется в Kotlin/Swift).
+type EventSink interface{ OnEvent(name, data string) }
+
+// LogSink — приёмник логов ядра (реализуется в Kotlin/Swift).
+type LogSink interface{ Log(level, msg string) }
+
+// SetEventSink задаёт основной приёмник событий (совместимый API).
+// Не затрагивает подписчиков, добавленных через SubscribeEvents. nil — снять.
+func SetEventSink(s EventSink) {
+	if s == nil {
+		telemetry.SetEventSink(nil)
+		return
+	}
+	telemetry.SetEventSink(s)
+}
+
+// SubscribeEvents добавляет подписчика на события и возвращает id для отписки.
+// names — имена событий через запятую ("error,reconnecting"); пусто — все события.
+func SubscribeEvents(s EventSink, names string) int64 {
+	if s == nil {
+		return 0
+	}
+	return telemetry.SubscribeEvents(s, splitCSV(names)...)
+}
+
+// UnsubscribeEvents снимает подписку id. Возвращает false, если её уже нет.
+func UnsubscribeEvents(id int64) bool { return telemetry.UnsubscribeEvents(id) }
+
+// SetLogger задаёт основной приёмник логов (совместимый API).
+// Не затрагивает приёмники, добавленные через SubscribeLogs. nil — снять.
+func SetLogger(s LogSink) {
+	if s == nil {
+		logpkg.SetLogger(nil)
+		return
+	}
+	logpkg.SetLogger(s)
+}
+
+// SetLogLevel задаёт глобальный уровень логов: debug | info | warn | error.
+func SetLogLevel(level string) { logpkg.SetLogLevel(level) }
+
+// SetLogFormat задаёт формат строк, которые получает LogSink:
+// "text" — "[component] msg key=value", "json" — одна JSON-запись на строку
+// ({"ts","level","component","msg","fields"}). Секреты в обоих форматах скрыты.
+func SetLogFormat(format string) { logpkg.SetLogFormat(format) }
+
+// SubscribeLogs добавляет приёмник логов с собственным минимальным уровнем
+// (пусто — глобальный SetLogLevel) и возвращает id для отписки.
+func SubscribeLogs(s LogSink, minLevel string) int64 {
+	if s == nil {
+		return 0
+	}
+	return logpkg.Subscribe(s, minLevel)
+}
+
+// UnsubscribeLogs снимает приёмник логов id. Возвращает false, если его уже нет.
+func UnsubscribeLogs(id int64) bool { return logpkg.Unsubscribe(id) }
+
+func splitCSV(s string) []string {
+	var out []string
+	for _, p := range strings.Split(s, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// EnableFileLog включает файловый лог в каталоге приложения dir
+// (например, filesDir/logs): ротация по maxSizeKB (0 — 5 MiB), хранится
+// maxFiles ротированных файлов (0 — 5), compress — gzip ротированных файлов.
+// Работает параллельно с SetLogger/SubscribeLogs. Возвращает "" или текст ошибки.
+func EnableFileLog(dir string, maxSizeKB, maxFiles int, compress bool, minLevel string) string {
+	err := logpkg.EnableFileLog(logpkg.FileOptions{
+		Dir:      dir,
+		MaxSize:  int64(maxSizeKB) << 10,
+		MaxFiles: maxFiles,
+		Compress: compress,
+		MinLevel: minLevel,
+	})
+	if err != nil {
+		return err.Error()
+	}
+	return ""
+}
+
+// DisableFileLog отключает файловый лог (файлы остаются на диске).
+func DisableFileLog() { logpkg.DisableFileLog() }
+
+// LogFilesJSON — пути файлов лога (активный первым) в виде JSON-массива,
+// чтобы приложить их к обращению в поддержку.
+func LogFilesJSON() string {
+	paths := logpkg.FileLogPaths()
+	if paths == nil {
+		paths = []string{}
+	}
+	b, _ := json.Marshal(paths)
+	return string(b)
+}
@@ -0,0 +1,33 @@
+//go:build android || ios || mobile_skel
+
+package mobile
+
+import "github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/telemetry"
+
+// ResetLatencyStats обнуляет гистограммы задержек (rtt/handshake/reconnect_downtime/dial),
+// которые попадают в HealthJSON().latency и /metrics. Ядро при этом не перезапускается.
+func ResetLatencyStats() { telemetry.ResetLatency() }
+
+// TrafficRatesJSON возвращает текущие скорости (бит/с):
+//
+//	{"up_bps":..,"down_bps":..,"up_bps_10s":..,"down_bps_10s":..,
+//	 "up_bps_60s":..,"down_bps_60s":..,"peak_up_bps":..,"peak_down_bps":..}
+//
+// Те же данные приходят событием "traffic" (период — telemetry.traffic_interval_ms).
+func TrafficRatesJSON() string { return telemetry.TrafficRatesJSON() }
+
+// EventsSince возвращает буферизованные события с seq > seq (JSON-массив):
+//
+//	[{"seq":12,"ts":1730000000000,"name":"started","data":{}}, ...]
+//
+// Буфер хранит последние telemetry.EventBufferSize событий. На холодном старте
+// приложение регистрирует sink и вызывает EventsSince(0), чтобы дочитать пропущенное.
+func EventsSince(seq int64) string {
+	if seq < 0 {
+		seq = 0
+	}
+	return telemetry.EventsSinceJSON(uint64(seq))
+}
+
+// LastEventSeq — номер последнего опубликованного события.
+func LastEventSeq() int64 { return int64(telemetry.LastEventSeq()) }
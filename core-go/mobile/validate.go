@@ -0,0 +1,124 @@
+//go:build android || ios || mobile_skel
+
+package mobile
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/internal/transport"
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/pkg/config"
+	sjson "github.com/sagernet/sing/common/json"
+)
+
+// ValidateConfig проверяет конфиг целиком и возвращает все найденные проблемы
+// (JSON-список; [] — конфиг валиден). Ничего не применяет. Принимает JSON SDK
+// или YAML клиента Hysteria2 (см. config.Normalize).
+//
+//	[{"path":"server","severity":"error","code":"invalid_address","message":"must be host:port"},
+//	 {"path":"outbounds","severity":"warning","code":"unknown_key","message":"unknown key \"outbounds\""}]
+//
+// Warning (неизвестные ключи, неподдерживаемые опции, устаревшие поля) не
+// мешают Start/Reload; error — мешают. Конфиг старой версии проверяется
+// после миграции (см. MigrateConfig).
+func ValidateConfig(configJSON string) string {
+	_, diags, _ := prepareConfig(configJSON)
+	if diags == nil {
+		diags = []config.Diagnostic{}
+	}
+	b, _ := json.Marshal(diags)
+	return string(b)
+}
+
+// ConfigSchema возвращает JSON Schema (draft 2020-12) конфига SDK,
+// сгенерированную из Go-типов: типы, required, default из Defaults(),
+// enum (engine, mode, ...) и описания полей. Для редакторов конфига.
+func ConfigSchema() string { return string(config.SchemaJSON()) }
+
+// MigrateConfig приводит конфиг (JSON SDK любой версии или YAML Hysteria2)
+// к актуальной версии формата и возвращает JSON для сохранения в приложении.
+// Ничего не применяет; Start/Reload мигрируют конфиг сами (см. CfgSet).
+func MigrateConfig(configText string) (string, error) {
+	raw, _, err := config.Normalize([]byte(configText))
+	if err != nil {
+		return "", err
+	}
+	migrated, _, err := config.Migrate(raw)
+	if err != nil {
+		return "", err
+	}
+	return string(migrated), nil
+}
+
+// checkConfig — строгая проверка для Start/Reload: возвращает конфиг в JSON
+// SDK (YAML Hysteria2 переводится; миграцию версии делает CfgSet) или все
+// ошибки одной строкой ("server: must be host:port; password: required").
+func checkConfig(configText string) (string, error) {
+	normalized, diags, err := prepareConfig(configText)
+	if err != nil {
+		return "", err
+	}
+	if err := config.DiagnosticsError(diags); err != nil {
+		return "", err
+	}
+	return normalized, nil
+}
+
+// ApplyConfig — CfgSet со строгой проверкой checkConfig: так конфиг
+// принимают Start, StartWithCode, Reload и tun.StartWithTun. Возвращает
+// сохранённый JSON (см. CfgSet). Вызывать под Mu.
+func ApplyConfig(configText string) (string, error) {
+	normalized, err := checkConfig(configText)
+	if err != nil {
+		return "", err
+	}
+	return CfgSet(normalized)
+}
+
+func prepareConfig(configText string) (string, []config.Diagnostic, error) {
+	raw, diags, err := config.Normalize([]byte(configText))
+	if err != nil {
+		return "", append(diags, config.Diagnostic{
+			Severity: config.SeverityError, Code: config.CodeSyntax, Message: err.Error(),
+		}), err
+	}
+	migrated, mdiags, err := config.Migrate(raw)
+	if err != nil {
+		d, ok := err.(config.Diagnostic)
+		if !ok {
+			d = config.Diagnostic{Severity: config.SeverityError, Code: config.CodeSyntax, Message: err.Error()}
+		}
+		return "", append(diags, d), err
+	}
+	diags = append(diags, mdiags...)
+	diags = append(diags, config.ValidateJSON(migrated)...)
+	diags = append(diags, checkEngine(migrated, diags)...)
+	return string(raw), diags, nil
+}
+
+// checkEngine сверяет engine (после Defaults) с движками этой сборки
+// (transport.CheckEngine): config.Engines перечисляет все известные
+// движки, а hc, например, есть только в сборках с тегом hc. Неизвестное
+// имя уже отметил ValidateJSON — второй диагностики не добавляем.
+func checkEngine(migrated []byte, diags []config.Diagnostic) []config.Diagnostic {
+	for _, d := range diags {
+		if d.Path == "engine" && d.Severity == config.SeverityError {
+			return nil
+		}
+	}
+	v, err := sjson.UnmarshalExtended[struct {
+		Engine string `json:"engine"`
+	}](migrated)
+	if err != nil {
+		return nil // синтаксис и типы уже проверил ValidateJSON
+	}
+	c := config.HY2Config{Engine: v.Engine}
+	c.Defaults()
+	if transport.CheckEngine(c.Engine) == nil {
+		return nil
+	}
+	return []config.Diagnostic{{
+		Path: "engine", Severity: config.SeverityError, Code: config.CodeInvalidValue,
+		Message: fmt.Sprintf("%q is not available in this build (available: %s)", c.Engine, transport.EngineNames()),
+	}}
+}
@@ -0,0 +1,213 @@
+//go:build mobile_skel
+
+package mobile
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/pkg/config"
+)
+
+const hy2YAML = `
+server: hy2.example.com:8443
+auth: s3cret
+tls:
+  sni: cdn.example.com
+`
+
+func findDiag(ds []config.Diagnostic, path, code string) *config.Diagnostic {
+	for i := range ds {
+		if ds[i].Path == path && ds[i].Code == code {
+			return &ds[i]
+		}
+	}
+	return nil
+}
+
+func TestValidateConfig(t *testing.T) {
+	cases := []struct {
+		name     string
+		cfg      string
+		path     string // "" — ошибок быть не должно
+		code     string
+		severity string
+	}{
+		{name: "valid", cfg: validCfg},
+		{name: "valid yaml", cfg: hy2YAML},
+		{name: "bad address", cfg: `{"server":"example.com","password":"p"}`,
+			path: "server", code: config.CodeInvalidAddress, severity: config.SeverityError},
+		{name: "obfs without password", cfg: `{"server":"example.com:443","password":"p","obfs":{"type":"salamander"}}`,
+			path: "obfs.password", code: config.CodeRequired, severity: config.SeverityError},
+		{name: "syntax", cfg: `{ invalid json`,
+			path: "", code: config.CodeSyntax, severity: config.SeverityError},
+		{name: "unknown key", cfg: `{"server":"example.com:443","password":"p","bogus":1}`,
+			path: "bogus", code: config.CodeUnknownKey, severity: config.SeverityWarning},
+		{name: "engine not in build", cfg: `{"server":"example.com:443","password":"p","engine":"hc"}`,
+			path: "engine", code: config.CodeInvalidValue, severity: config.SeverityError},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			var diags []config.Diagnostic
+			if err := json.Unmarshal([]byte(ValidateConfig(tc.cfg)), &diags); err != nil {
+				t.Fatalf("ValidateConfig returned invalid JSON: %v", err)
+			}
+			if tc.code == "" {
+				for _, d := range diags {
+					if d.Severity == config.SeverityError {
+						t.Fatalf("unexpected error diagnostic: %+v", d)
+					}
+				}
+				return
+			}
+			d := findDiag(diags, tc.path, tc.code)
+			if d == nil {
+				t.Fatalf("want %s/%s, got %+v", tc.path, tc.code, diags)
+			}
+			if d.Severity != tc.severity {
+				t.Fatalf("severity = %q, want %q", d.Severity, tc.severity)
+			}
+		})
+	}
+
+	if got := ValidateConfig(validCfg); got != "[]" {
+		t.Fatalf("ValidateConfig(valid) = %s, want []", got)
+	}
+}
+
+func TestApplyConfig(t *testing.T) {
+	cases := []struct {
+		name    string
+		cfg     string
+		wantErr string // "" — конфиг принимается
+	}{
+		{name: "valid", cfg: validCfg},
+		{name: "valid yaml", cfg: hy2YAML},
+		{name: "unknown key is a warning", cfg: `{"server":"example.com:443","password":"p","bogus":1}`},
+		{name: "bad address", cfg: `{"server":"example.com","password":"p"}`, wantErr: "server"},
+		{name: "syntax", cfg: `{ invalid json`, wantErr: "invalid"},
+		{name: "engine not in build", cfg: `{"server":"example.com:443","password":"p","engine":"hc"}`, wantErr: "engine"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			resetState()
+			prev := []byte(`{"server":"prev.example:443","password":"p"}`)
+			CfgRaw = prev
+
+			saved, err := ApplyConfig(tc.cfg)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("ApplyConfig err = %v, want it to mention %q", err, tc.wantErr)
+				}
+				if string(CfgGet()) != string(prev) {
+					t.Fatalf("rejected config must not replace the stored one, got %s", CfgGet())
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ApplyConfig unexpected error: %v", err)
+			}
+			if saved != string(CfgGet()) {
+				t.Fatalf("returned config %q differs from stored %q", saved, CfgGet())
+			}
+		})
+	}
+	resetState()
+}
+
+func TestApplyConfig_YAMLStoredAsJSON(t *testing.T) {
+	resetState()
+	defer resetState()
+
+	saved, err := ApplyConfig(hy2YAML)
+	if err != nil {
+		t.Fatalf("ApplyConfig(yaml): %v", err)
+	}
+	var hc config.HY2Config
+	if err := json.Unmarshal([]byte(saved), &hc); err != nil {
+		t.Fatalf("stored config is not JSON: %v\n%s", err, saved)
+	}
+	if hc.Server != "hy2.example.com:8443" || hc.Password != "s3cret" {
+		t.Fatalf("yaml fields lost: %+v", hc)
+	}
+}
+
+func TestConfigSchema(t *testing.T) {
+	var s struct {
+		Schema     string         `json:"$schema"`
+		Properties map[string]any `json:"properties"`
+	}
+	if err := json.Unmarshal([]byte(ConfigSchema()), &s); err != nil {
+		t.Fatalf("ConfigSchema is not JSON: %v", err)
+	}
+	if !strings.Contains(s.Schema, "2020-12") {
+		t.Fatalf("$schema = %q, want draft 2020-12", s.Schema)
+	}
+	engine, _ := s.Properties["engine"].(map[string]any)
+	enum, _ := engine["enum"].([]any)
+	if len(enum) != len(config.Engines) {
+		t.Fatalf("engine enum = %v, want %v", enum, config.Engines)
+	}
+	for _, key := range []string{"server", "password", "route", "log"} {
+		if _, ok := s.Properties[key]; !ok {
+			t.Fatalf("schema has no %q property", key)
+		}
+	}
+}
+
+func TestMigrateConfig(t *testing.T) {
+	cases := []struct {
+		name    string
+		cfg     string
+		wantErr bool
+		check   func(t *testing.T, out string)
+	}{
+		{name: "current version kept as is",
+			cfg: `{"version":1, /* keep */ "server":"a.example:443","password":"p"}`,
+			check: func(t *testing.T, out string) {
+				if !strings.Contains(out, "/* keep */") {
+					t.Fatalf("current-version config must be returned unchanged, got %s", out)
+				}
+			}},
+		{name: "old version migrated",
+			cfg: `{"server":"a.example:443","password":"p"}`,
+			check: func(t *testing.T, out string) {
+				var v struct{ Version int }
+				if err := json.Unmarshal([]byte(out), &v); err != nil || v.Version != config.CurrentVersion {
+					t.Fatalf("migrated config = %s (err %v), want version %d", out, err, config.CurrentVersion)
+				}
+			}},
+		{name: "yaml converted",
+			cfg: hy2YAML,
+			check: func(t *testing.T, out string) {
+				if !strings.Contains(out, `"hy2.example.com:8443"`) {
+					t.Fatalf("yaml server lost: %s", out)
+				}
+			}},
+		{name: "engine not in build is not validated here",
+			cfg: `{"version":1,"server":"a.example:443","password":"p","engine":"hc"}`,
+			check: func(t *testing.T, out string) {
+				if !strings.Contains(out, `"engine":"hc"`) {
+					t.Fatalf("engine must be preserved, got %s", out)
+				}
+			}},
+		{name: "syntax", cfg: `{ invalid json`, wantErr: true},
+		{name: "newer version", cfg: `{"version":99}`, wantErr: true},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			out, err := MigrateConfig(tc.cfg)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("MigrateConfig(%q) = %s, want error", tc.cfg, out)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("MigrateConfig unexpected error: %v", err)
+			}
+			tc.check(t, out)
+		})
+	}
+}
@@ -5,45 +5,198 @@ package config
 import (
 	"encoding/json"
 	"errors"
-	"strings"
 
 	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/mobile"
 )
 
+// Engines — допустимые значения engine (hysteria_core — синоним hc).
+// Какие из них есть в конкретной сборке — mobile.AvailableEngines.
+var Engines = []string{"sing", "hc", "hysteria_core", "trojan", "wireguard"}
+
+// Modes — допустимые значения mode: tun2socks (TUN → go-tun2socks → локальный
+// SOCKS5, по умолчанию) | tun (нативный TUN sing-tun, StartWithTun).
+var Modes = []string{"tun2socks", "tun"}
+
 type HY2Config struct {
-	Engine       string   `json:"engine,omitempty"` // "sing" (default) | "hc"
-	Server       string   `json:"server"`
-	Password     string   `json:"password"`
-	SNI          string   `json:"sni,omitempty"`
-	ALPN         []string `json:"alpn,omitempty"`
-	UpMbps       int      `json:"up_mbps,omitempty"`
-	DownMbps     int      `json:"down_mbps,omitempty"`
-	IdleTimeoutS int      `json:"idle_timeout_s,omitempty"`
-	Mode         string   `json:"mode,omitempty"`
+	Version      int        `json:"version,omitempty"` // формат конфига, см. CurrentVersion и Migrate
+	Engine       string     `json:"engine,omitempty"`  // "sing" (default) | "hc" | "trojan" | "wireguard" — см. Engines
+	Server       string     `json:"server"`
+	Password     string     `json:"password"`
+	SNI          string     `json:"sni,omitempty"`
+	Insecure     bool       `json:"insecure,omitempty"` // не проверять сертификат сервера (только для тестов)
+	ALPN         []string   `json:"alpn,omitempty"`
+	UpMbps       int        `json:"up_mbps,omitempty"`
+	DownMbps     int        `json:"down_mbps,omitempty"`
+	IdleTimeoutS int        `json:"idle_timeout_s,omitempty"`
+	Mode         string     `json:"mode,omitempty"`
+	Obfs         ObfsConfig `json:"obfs,omitempty"`
+
+	Route     RouteConfig     `json:"route,omitempty"`
+	Inbound   InboundConfig   `json:"inbound,omitempty"`
+	Metrics   MetricsConfig   `json:"metrics,omitempty"`
+	Telemetry TelemetryConfig `json:"telemetry,omitempty"`
+	Log       LogConfig       `json:"log,omitempty"`
+	Panic     PanicConfig     `json:"panic,omitempty"`
+	Fallback  FallbackConfig  `json:"fallback,omitempty"`
+	Detour    DetourConfig    `json:"detour,omitempty"`
+
+	Forwarding []ForwardConfig `json:"forwarding,omitempty"`
+	WireGuard  WireGuardConfig `json:"wireguard,omitempty"`
+}
+
+// ObfsConfig — обфускация QUIC-пакетов Hysteria2. Type: "" (выключено) | salamander.
+type ObfsConfig struct {
+	Type     string `json:"type,omitempty"`
+	Password string `json:"password,omitempty"`
+}
+
+// WireGuardConfig — параметры движка "wireguard" (ключи — base64, как в wg-quick).
+// Address — адреса интерфейса внутри туннеля (CIDR), с них ходит стек движка;
+// DNS — резолверы внутри туннеля (пусто — системный резолвер);
+// ListenPort 0 — случайный порт; MTU 0 — 1420.
+type WireGuardConfig struct {
+	PrivateKey string          `json:"private_key,omitempty"`
+	Address    []string        `json:"address,omitempty"`
+	DNS        []string        `json:"dns,omitempty"`
+	ListenPort int             `json:"listen_port,omitempty"`
+	MTU        int             `json:"mtu,omitempty"`
+	Peers      []WireGuardPeer `json:"peers,omitempty"`
+}
+
+// WireGuardPeer — пир WireGuard. KeepaliveS — persistent keepalive в секундах (0 — выключен).
+type WireGuardPeer struct {
+	PublicKey    string   `json:"public_key"`
+	PresharedKey string   `json:"preshared_key,omitempty"`
+	Endpoint     string   `json:"endpoint,omitempty"`
+	AllowedIPs   []string `json:"allowed_ips,omitempty"`
+	KeepaliveS   int      `json:"keepalive_s,omitempty"`
+}
+
+// ForwardConfig — локальный проброс порта через активный транспорт
+// (как tcpForwarding/udpForwarding клиента Hysteria2).
+// Network: tcp (по умолчанию) | udp; UDPTimeoutS — простой UDP-сессии (0 — 60 с).
+type ForwardConfig struct {
+	Network     string `json:"network,omitempty"`
+	Listen      string `json:"listen"`
+	Remote      string `json:"remote"`
+	UDPTimeoutS int    `json:"udp_timeout_s,omitempty"`
+}
+
+// DetourConfig — вышестоящий прокси (корпоративный egress). TCP-подключения
+// транспорта (в том числе fallback) идут через SOCKS5 CONNECT или HTTP CONNECT,
+// UDP Hysteria2 — через SOCKS5 UDP ASSOCIATE. Type: "" (выключено) | socks5 | http.
+type DetourConfig struct {
+	Type     string `json:"type,omitempty"`
+	Server   string `json:"server,omitempty"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+}
+
+// FallbackConfig — запасной TCP/TLS-транспорт (Trojan) на случай, когда UDP
+// до сервера заблокирован. Server — host:port Trojan-сервера; Password и SNI
+// по умолчанию берутся из основного конфига. AfterUDPFailures — сколько
+// диагнозов udp_blocked подряд нужно для переключения (0 — 3).
+type FallbackConfig struct {
+	Enabled          bool   `json:"enabled,omitempty"`
+	Server           string `json:"server,omitempty"`
+	Password         string `json:"password,omitempty"`
+	SNI              string `json:"sni,omitempty"`
+	Insecure         bool   `json:"insecure,omitempty"`
+	AfterUDPFailures int    `json:"after_udp_failures,omitempty"`
+}
+
+// PanicConfig — реакция на панику в долгоживущих подсистемах
+// (supervisor транспорта, serve-циклы инбаундов).
+// Policy: "restart" (по умолчанию) | "stop"; MaxRestarts — лимит на подсистему (0 — 5).
+type PanicConfig struct {
+	Policy      string `json:"policy,omitempty"`
+	MaxRestarts int    `json:"max_restarts,omitempty"`
+}
+
+// LogConfig — уровень и формат логов, которые получает LogSink.
+// Level: debug | info | warn | error; Format: text | json.
+type LogConfig struct {
+	Level  string `json:"level,omitempty"`
+	Format string `json:"format,omitempty"`
+}
+
+// TelemetryConfig — параметры событий телеметрии для UI.
+// TrafficIntervalMs — период события "traffic": 0 — 1000 мс, < 0 — выключено.
+type TelemetryConfig struct {
+	TrafficIntervalMs int `json:"traffic_interval_ms,omitempty"`
+}
+
+// MetricsConfig — OpenMetrics-эндпоинт /metrics (только loopback).
+type MetricsConfig struct {
+	Enabled bool   `json:"enabled,omitempty"`
+	Listen  string `json:"listen,omitempty"` // host:port, по умолчанию 127.0.0.1:9090
+}
+
+// InboundConfig — аутентификация локальных инбаундов (SOCKS5 / HTTP).
+// Username/Password — статичные креды; RandomAuth — сгенерировать
+// случайные креды на сессию (их можно получить через mobile.InboundCredentials).
+type InboundConfig struct {
+	Username   string `json:"username,omitempty"`
+	Password   string `json:"password,omitempty"`
+	RandomAuth bool   `json:"random_auth,omitempty"`
+}
+
+// RouteConfig — правила маршрутизации потоков (split tunneling).
+// Outbound: "proxy" | "direct" | "block"; Final — outbound по умолчанию.
+type RouteConfig struct {
+	Rules []RouteRule `json:"rules,omitempty"`
+	Final string      `json:"final,omitempty"`
+}
+
+// RouteRule матчит поток по приложению-владельцу:
+// package_name — Android, bundle_id — iOS.
+type RouteRule struct {
+	PackageName []string `json:"package_name,omitempty"`
+	BundleID    []string `json:"bundle_id,omitempty"`
+	Outbound    string   `json:"outbound"`
 }
 
 func (c *HY2Config) Defaults() {
 	if len(c.ALPN) == 0 {
 		c.ALPN = []string{"h3"}
 	}
+	if c.Metrics.Enabled && c.Metrics.Listen == "" {
+		c.Metrics.Listen = "127.0.0.1:9090"
+	}
 	if c.Mode == "" {
 		c.Mode = "tun2socks"
 	}
 	if c.Engine == "" {
 		c.Engine = "sing"
 	}
+	if c.Engine == "wireguard" && c.WireGuard.MTU == 0 {
+		c.WireGuard.MTU = 1420
+	}
+	if c.Fallback.Enabled {
+		if c.Fallback.Password == "" {
+			c.Fallback.Password = c.Password
+		}
+		if c.Fallback.SNI == "" {
+			c.Fallback.SNI = c.SNI
+		}
+	}
 }
 
+// Validate проверяет конфиг и возвращает первую ошибку; все диагностики
+// сразу (с путями и кодами) — в Check.
 func (c *HY2Config) Validate() error {
-	if c.Server == "" || !strings.Contains(c.Server, ":") {
-		return errors.New("server must be host:port")
-	}
-	if c.Password == "" {
-		return errors.New("password required")
+	for _, d := range c.Check() {
+		if d.Severity == SeverityError {
+			return d
+		}
 	}
 	return nil
 }
 
+func validOutbound(name string) bool {
+	return name == "proxy" || name == "direct" || name == "block"
+}
+
 // parseHY2Config читает cfgRaw (уже провалидированный расширенным JSON) и
 // достает из него минимальный outbound:hysteria2 (Server/Password/SNI/ALPN/...).
 // На первом шаге — простой unmarshal всей cfgRaw в HY2Config.
@@ -0,0 +1,86 @@
+//go:build android || ios || mobile_skel
+
+package config
+
+import "reflect"
+
+// Категории изменений конфига (см. Diff). Только ChangeTransport требует
+// переподключения транспорта; остальные применяются на лету.
+const (
+	ChangeTransport  = "transport"  // server/auth/TLS/obfs/движок/bandwidth/fallback/detour/wireguard
+	ChangeRouting    = "routing"    // route
+	ChangeLogging    = "logging"    // log
+	ChangeInbound    = "inbound"    // креды локальных инбаундов
+	ChangeForwarding = "forwarding" // пробросы портов
+	ChangeMetrics    = "metrics"    // эндпоинт /metrics
+	ChangeTelemetry  = "telemetry"  // период события traffic
+	ChangePanic      = "panic"      // политика перезапуска после паники
+)
+
+// ConfigDiff — результат сравнения двух конфигов.
+// Categories — затронутые категории, Fields — изменённые поля (JSON-ключи
+// верхнего уровня); оба списка в порядке объявления полей HY2Config.
+type ConfigDiff struct {
+	Categories []string `json:"applied"`
+	Fields     []string `json:"fields"`
+}
+
+// Has сообщает, затронута ли категория cat.
+func (d ConfigDiff) Has(cat string) bool {
+	for _, c := range d.Categories {
+		if c == cat {
+			return true
+		}
+	}
+	return false
+}
+
+// Empty — конфиги совпадают.
+func (d ConfigDiff) Empty() bool { return len(d.Fields) == 0 }
+
+// diffFields — поля HY2Config и их категории. Новое поле конфига нужно
+// добавить сюда, иначе его изменение не будет применено при Reload.
+// Пустая категория — поле ничего не применяет (version).
+var diffFields = []struct {
+	name string
+	cat  string
+	get  func(*HY2Config) any
+}{
+	{"version", "", func(c *HY2Config) any { return c.Version }},
+	{"engine", ChangeTransport, func(c *HY2Config) any { return c.Engine }},
+	{"server", ChangeTransport, func(c *HY2Config) any { return c.Server }},
+	{"password", ChangeTransport, func(c *HY2Config) any { return c.Password }},
+	{"sni", ChangeTransport, func(c *HY2Config) any { return c.SNI }},
+	{"insecure", ChangeTransport, func(c *HY2Config) any { return c.Insecure }},
+	{"alpn", ChangeTransport, func(c *HY2Config) any { return c.ALPN }},
+	{"up_mbps", ChangeTransport, func(c *HY2Config) any { return c.UpMbps }},
+	{"down_mbps", ChangeTransport, func(c *HY2Config) any { return c.DownMbps }},
+	{"idle_timeout_s", ChangeTransport, func(c *HY2Config) any { return c.IdleTimeoutS }},
+	{"mode", ChangeTransport, func(c *HY2Config) any { return c.Mode }},
+	{"obfs", ChangeTransport, func(c *HY2Config) any { return c.Obfs }},
+	{"route", ChangeRouting, func(c *HY2Config) any { return c.Route }},
+	{"inbound", ChangeInbound, func(c *HY2Config) any { return c.Inbound }},
+	{"metrics", ChangeMetrics, func(c *HY2Config) any { return c.Metrics }},
+	{"telemetry", ChangeTelemetry, func(c *HY2Config) any { return c.Telemetry }},
+	{"log", ChangeLogging, func(c *HY2Config) any { return c.Log }},
+	{"panic", ChangePanic, func(c *HY2Config) any { return c.Panic }},
+	{"fallback", ChangeTransport, func(c *HY2Config) any { return c.Fallback }},
+	{"detour", ChangeTransport, func(c *HY2Config) any { return c.Detour }},
+	{"forwarding", ChangeForwarding, func(c *HY2Config) any { return c.Forwarding }},
+	{"wireguard", ChangeTransport, func(c *HY2Config) any { return c.WireGuard }},
+}
+
+// Diff сравнивает old и new (оба — после Defaults) и классифицирует изменения.
+func Diff(old, new HY2Config) ConfigDiff {
+	d := ConfigDiff{Categories: []string{}, Fields: []string{}}
+	for _, f := range diffFields {
+		if reflect.DeepEqual(f.get(&old), f.get(&new)) {
+			continue
+		}
+		d.Fields = append(d.Fields, f.name)
+		if f.cat != "" && !d.Has(f.cat) {
+			d.Categories = append(d.Categories, f.cat)
+		}
+	}
+	return d
+}
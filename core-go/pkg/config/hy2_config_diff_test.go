@@ -0,0 +1,54 @@
+//go:build mobile_skel
+
+package config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiff_Classifies(t *testing.T) {
+	old := HY2Config{Server: "a.example:443", Password: "secret"}
+	old.Defaults()
+
+	same := old
+	if d := Diff(old, same); !d.Empty() || len(d.Categories) != 0 {
+		t.Fatalf("identical configs must give empty diff, got %+v", d)
+	}
+
+	nw := old
+	nw.Log.Level = "debug"
+	nw.Route.Final = "direct"
+	d := Diff(old, nw)
+	if d.Has(ChangeTransport) {
+		t.Fatalf("log/route change must not touch transport: %+v", d)
+	}
+	if !reflect.DeepEqual(d.Categories, []string{ChangeRouting, ChangeLogging}) ||
+		!reflect.DeepEqual(d.Fields, []string{"route", "log"}) {
+		t.Fatalf("unexpected diff: %+v", d)
+	}
+
+	nw = old
+	nw.SNI = "b.example"
+	nw.Forwarding = []ForwardConfig{{Listen: "127.0.0.1:1", Remote: "x:1"}}
+	d = Diff(old, nw)
+	if !d.Has(ChangeTransport) || !d.Has(ChangeForwarding) {
+		t.Fatalf("sni/forwarding change not classified: %+v", d)
+	}
+}
+
+// Каждое поле HY2Config должно быть в diffFields, иначе Reload его молча проигнорирует.
+func TestDiff_CoversAllFields(t *testing.T) {
+	known := map[string]bool{}
+	for _, f := range diffFields {
+		known[f.name] = true
+	}
+	typ := reflect.TypeOf(HY2Config{})
+	for i := 0; i < typ.NumField(); i++ {
+		name, _, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ",")
+		if !known[name] {
+			t.Errorf("HY2Config field %q is missing from diffFields", name)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+//go:build android || ios || mobile_skel
+
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+
+	sjson "github.com/sagernet/sing/common/json"
+)
+
+// Версионирование формата конфига. Документ без "version" — версия 0
+// (всё, что писалось до появления поля). Migrate поднимает его цепочкой
+// migrations до CurrentVersion; ломающее изменение формата — это новая
+// версия и ещё одна функция в migrations, старые конфиги приложений
+// продолжают приниматься.
+
+// CurrentVersion — версия формата, которую понимает эта сборка.
+const CurrentVersion = 1
+
+// migrations[i] переводит документ из версии i в i+1 (на месте).
+var migrations = []func(doc map[string]any, warn func(path, msg string)){
+	migrateV0,
+}
+
+// deprecatedValues — значения, которые текущая версия ещё принимает,
+// но в конфиге их лучше заменить (предупреждение при каждой загрузке).
+var deprecatedValues = []struct {
+	key, value, msg string
+}{
+	{"engine", "hysteria_core", `deprecated alias, use "hc"`},
+}
+
+// Migrate приводит сырой конфиг (JSON с комментариями, как в mobile.CfgSet)
+// к CurrentVersion. Документ актуальной версии возвращается байт в байт
+// (комментарии сохраняются); мигрированный — заново закодированным JSON с
+// "version", его приложению стоит сохранить вместо старого. Диагностики —
+// warning CodeDeprecated по каждому переписанному или устаревшему полю.
+// Ошибка — документ не разбирается или его версия новее CurrentVersion
+// (во втором случае это Diagnostic с путём "version").
+func Migrate(raw []byte) ([]byte, []Diagnostic, error) {
+	tree, err := sjson.UnmarshalExtended[any](raw)
+	if err != nil {
+		return nil, nil, err
+	}
+	doc, ok := tree.(map[string]any)
+	if !ok {
+		return nil, nil, errors.New("config must be a JSON object")
+	}
+	from, err := docVersion(doc)
+	if err != nil {
+		return nil, nil, err
+	}
+	if from > CurrentVersion {
+		return nil, nil, Diagnostic{Path: "version", Severity: SeverityError, Code: CodeUnsupported,
+			Message: fmt.Sprintf("config version %d is newer than supported %d", from, CurrentVersion)}
+	}
+
+	var diags []Diagnostic
+	warn := func(path, msg string) {
+		diags = append(diags, Diagnostic{Path: path, Severity: SeverityWarning, Code: CodeDeprecated, Message: msg})
+	}
+	for v := from; v < CurrentVersion; v++ {
+		migrations[v](doc, warn)
+	}
+	for _, d := range deprecatedValues {
+		if doc[d.key] == d.value {
+			warn(d.key, d.msg)
+		}
+	}
+	if from == CurrentVersion {
+		return raw, diags, nil
+	}
+	doc["version"] = CurrentVersion
+	b, err := json.Marshal(doc)
+	return b, diags, err
+}
+
+func docVersion(doc map[string]any) (int, error) {
+	v, ok := doc["version"]
+	if !ok || v == nil {
+		return 0, nil
+	}
+	f, ok := v.(float64)
+	if !ok || f < 0 || f != math.Trunc(f) {
+		return 0, Diagnostic{Path: "version", Severity: SeverityError, Code: CodeTypeMismatch,
+			Message: "must be a non-negative integer"}
+	}
+	return int(f), nil
+}
+
+// migrateV0 — документы до появления "version". Тогда рядом с плоскими
+// полями допускались секции в стиле sing-box: outbounds (из первого
+// hysteria2-outbound берутся server/password/TLS/bandwidth/obfs, если
+// плоские поля не заданы) и inbounds (локальные инбаунды SDK поднимает сам),
+// а движок hc назывался hysteria_core.
+func migrateV0(doc map[string]any, warn func(path, msg string)) {
+	if obs, ok := doc["outbounds"]; ok {
+		delete(doc, "outbounds")
+		if ob := firstHysteria2Outbound(obs); ob != nil {
+			foldOutbound(doc, ob)
+			warn("outbounds", "moved to top-level server/password/sni/alpn/obfs; migrated")
+		} else {
+			warn("outbounds", "no hysteria2 outbound; removed")
+		}
+	}
+	if _, ok := doc["inbounds"]; ok {
+		delete(doc, "inbounds")
+		warn("inbounds", "local inbounds are managed by the SDK (see inbound); removed")
+	}
+	if doc["engine"] == "hysteria_core" {
+		doc["engine"] = "hc"
+		warn("engine", `"hysteria_core" renamed to "hc"; migrated`)
+	}
+}
+
+func firstHysteria2Outbound(v any) map[string]any {
+	arr, _ := v.([]any)
+	for _, it := range arr {
+		if ob, ok := it.(map[string]any); ok && ob["type"] == "hysteria2" {
+			return ob
+		}
+	}
+	return nil
+}
+
+// foldOutbound переносит поля hysteria2-outbound sing-box в плоский конфиг;
+// уже заданные плоские поля важнее.
+func foldOutbound(doc, ob map[string]any) {
+	set := func(key string, v any) {
+		if _, exists := doc[key]; !exists && v != nil {
+			doc[key] = v
+		}
+	}
+	if host, ok := ob["server"].(string); ok && host != "" {
+		if port, ok := ob["server_port"]; ok {
+			set("server", net.JoinHostPort(host, fmt.Sprint(port)))
+		} else {
+			set("server", host)
+		}
+	}
+	set("password", ob["password"])
+	set("up_mbps", ob["up_mbps"])
+	set("down_mbps", ob["down_mbps"])
+	set("obfs", ob["obfs"])
+	if tls, ok := ob["tls"].(map[string]any); ok {
+		set("sni", tls["server_name"])
+		set("insecure", tls["insecure"])
+		set("alpn", tls["alpn"])
+	}
+}
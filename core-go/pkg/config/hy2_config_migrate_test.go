@@ -0,0 +1,89 @@
+//go:build mobile_skel
+
+package config
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestMigrate_V0Outbounds(t *testing.T) {
+	raw := []byte(`{
+  // sing-box style, before "version" existed
+  "engine": "hysteria_core",
+  "inbounds": [{"type": "tun"}],
+  "outbounds": [
+    {"type": "direct"},
+    {"type": "hysteria2", "server": "hy2.example.com", "server_port": 8443, "password": "p",
+     "up_mbps": 20, "obfs": {"type": "salamander", "password": "o"},
+     "tls": {"server_name": "cdn.example.com", "alpn": ["h3"], "insecure": true}}
+  ]
+}`)
+	out, diags, err := Migrate(raw)
+	if err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+	var hc HY2Config
+	if err := json.Unmarshal(out, &hc); err != nil {
+		t.Fatalf("migrated JSON: %v\n%s", err, out)
+	}
+	if hc.Version != CurrentVersion || hc.Engine != "hc" || hc.Server != "hy2.example.com:8443" || hc.Password != "p" ||
+		hc.SNI != "cdn.example.com" || !hc.Insecure || hc.UpMbps != 20 || hc.Obfs.Password != "o" {
+		t.Fatalf("not migrated: %+v", hc)
+	}
+	for _, path := range []string{"outbounds", "inbounds", "engine"} {
+		if d := findDiag(diags, path, CodeDeprecated); d == nil || d.Severity != SeverityWarning {
+			t.Errorf("expected deprecation warning for %s, got %+v", path, diags)
+		}
+	}
+	if d := ValidateJSON(out); HasErrors(d) || findDiag(d, "outbounds", CodeUnknownKey) != nil {
+		t.Fatalf("migrated config must be clean: %+v", d)
+	}
+
+	// Повторная миграция ничего не меняет.
+	again, diags, err := Migrate(out)
+	if err != nil || string(again) != string(out) || len(diags) != 0 {
+		t.Fatalf("second Migrate changed the document: %s, %+v, %v", again, diags, err)
+	}
+}
+
+func TestMigrate_FlatFieldsWin(t *testing.T) {
+	out, _, err := Migrate([]byte(`{"server":"a.example:443","password":"flat",
+		"outbounds":[{"type":"hysteria2","server":"b.example","server_port":1,"password":"ob"}]}`))
+	if err != nil {
+		t.Fatalf("Migrate: %v", err)
+	}
+	var hc HY2Config
+	_ = json.Unmarshal(out, &hc)
+	if hc.Server != "a.example:443" || hc.Password != "flat" {
+		t.Fatalf("flat fields must take precedence: %+v", hc)
+	}
+}
+
+func TestMigrate_CurrentAndFuture(t *testing.T) {
+	cur := []byte(`{"version":1, /* keep */ "server":"a:1","password":"p","engine":"hysteria_core"}`)
+	out, diags, err := Migrate(cur)
+	if err != nil || string(out) != string(cur) {
+		t.Fatalf("current version must pass through unchanged: %s, %v", out, err)
+	}
+	if findDiag(diags, "engine", CodeDeprecated) == nil {
+		t.Fatalf("deprecated value must be reported: %+v", diags)
+	}
+
+	_, _, err = Migrate([]byte(`{"version":99}`))
+	if d, ok := err.(Diagnostic); !ok || d.Path != "version" || d.Code != CodeUnsupported {
+		t.Fatalf("future version: got %v", err)
+	}
+	if _, _, err := Migrate([]byte(`{"version":"1"}`)); err == nil {
+		t.Fatal("non-integer version must be rejected")
+	}
+	if d := ValidateJSON([]byte(`{"version":5,"server":"a:1","password":"p"}`)); findDiag(d, "version", CodeUnsupported) == nil {
+		t.Fatalf("Check must reject unknown versions: %+v", d)
+	}
+}
+
+func TestMigrations_CoverAllVersions(t *testing.T) {
+	if len(migrations) != CurrentVersion {
+		t.Fatalf("migrations has %d steps, CurrentVersion is %d", len(migrations), CurrentVersion)
+	}
+}
@@ -0,0 +1,208 @@
+//go:build android || ios || mobile_skel
+
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// JSON Schema (draft 2020-12) конфига, сгенерированная из Go-типов HY2Config:
+// типы и required — по полям и json-тегам, default — из Defaults(),
+// enum и description — из таблиц ниже. Новое поле без описания роняет
+// TestSchema_InSync, поэтому схема не отстаёт от типов.
+
+// schemaEnums — допустимые значения строковых полей (пути как в schemaDocs).
+var schemaEnums = map[string][]string{
+	"engine":                 Engines,
+	"mode":                   Modes,
+	"route.rules[].outbound": {"proxy", "direct", "block"},
+	"route.final":            {"proxy", "direct", "block"},
+	"log.level":              {"debug", "info", "warn", "error"},
+	"log.format":             {"text", "json"},
+	"panic.policy":           {"restart", "stop"},
+	"detour.type":            {"socks5", "http"},
+	"obfs.type":              {"salamander"},
+	"forwarding[].network":   {"tcp", "udp"},
+}
+
+// schemaDocs — описания полей. Путь: JSON-ключи через точку, элементы
+// массивов — "[]" (например, "route.rules[].outbound").
+var schemaDocs = map[string]string{
+	"version":        "Config format version; a document without it is version 0 and is migrated to the current version on load.",
+	"engine":         "Transport engine; the set compiled into a build is reported by AvailableEngines.",
+	"server":         "Hysteria2 server as host:port (not used by the wireguard engine).",
+	"password":       "Hysteria2 auth password (not used by the wireguard engine).",
+	"sni":            "TLS server name; defaults to the host of server.",
+	"insecure":       "Skip server certificate verification (testing only).",
+	"alpn":           "TLS ALPN protocols.",
+	"up_mbps":        "Upload bandwidth hint in Mbit/s (0 = let the server decide).",
+	"down_mbps":      "Download bandwidth hint in Mbit/s (0 = let the server decide).",
+	"idle_timeout_s": "QUIC idle timeout in seconds (0 = engine default).",
+	"obfs":           "Hysteria2 QUIC packet obfuscation.",
+	"obfs.type":      "Obfuscation type; empty disables it.",
+	"obfs.password":  "Obfuscation password shared with the server.",
+	"mode":           "How device traffic reaches the core: tun2socks via the local SOCKS5 inbound, or tun via the native TUN stack.",
+
+	"route":                      "Per-application routing (split tunneling).",
+	"route.rules":                "Rules evaluated in order; the first match wins.",
+	"route.rules[]":              "Routing rule matching the flow owner.",
+	"route.rules[].package_name": "Android package names.",
+	"route.rules[].bundle_id":    "iOS bundle identifiers.",
+	"route.rules[].outbound":     "Where matching flows go.",
+	"route.final":                "Outbound for flows no rule matched (default proxy).",
+
+	"inbound":             "Authentication of the local SOCKS5/HTTP inbounds.",
+	"inbound.username":    "Static inbound username.",
+	"inbound.password":    "Static inbound password; requires username.",
+	"inbound.random_auth": "Generate random inbound credentials on every start (see InboundCredentials).",
+
+	"metrics":         "OpenMetrics endpoint /metrics.",
+	"metrics.enabled": "Serve /metrics.",
+	"metrics.listen":  "Loopback host:port of the endpoint.",
+
+	"telemetry":                     "Telemetry events for the UI.",
+	"telemetry.traffic_interval_ms": "Period of the traffic event in ms (0 = 1000, negative = off, otherwise >= 1000).",
+
+	"log":        "Logs delivered to LogSink.",
+	"log.level":  "Minimum log level.",
+	"log.format": "Log record format.",
+
+	"panic":              "Reaction to panics in long-lived subsystems.",
+	"panic.policy":       "restart the subsystem or leave it stopped.",
+	"panic.max_restarts": "Restart limit per subsystem (0 = 5).",
+
+	"fallback":                    "Trojan TCP/TLS fallback used when UDP to the server is blocked.",
+	"fallback.enabled":            "Enable automatic switching to the fallback.",
+	"fallback.server":             "Trojan server as host:port.",
+	"fallback.password":           "Trojan password; defaults to password.",
+	"fallback.sni":                "Trojan TLS server name; defaults to sni.",
+	"fallback.insecure":           "Skip certificate verification (testing only).",
+	"fallback.after_udp_failures": "Consecutive udp_blocked diagnoses before switching (0 = 3).",
+
+	"detour":          "Upstream proxy for transport sockets.",
+	"detour.type":     "Upstream proxy protocol; http carries TCP only and requires engine trojan.",
+	"detour.server":   "Upstream proxy as host:port.",
+	"detour.username": "Upstream proxy username.",
+	"detour.password": "Upstream proxy password.",
+
+	"forwarding":                 "Local port forwards through the active transport.",
+	"forwarding[]":               "Port forward.",
+	"forwarding[].network":       "Forwarded protocol (default tcp).",
+	"forwarding[].listen":        "Local host:port to listen on.",
+	"forwarding[].remote":        "Destination host:port reached through the tunnel.",
+	"forwarding[].udp_timeout_s": "Idle timeout of a UDP session in seconds (0 = 60).",
+
+	"wireguard":                       "Settings of the wireguard engine.",
+	"wireguard.private_key":           "Interface private key, base64.",
+	"wireguard.address":               "Interface addresses (CIDR) inside the tunnel.",
+	"wireguard.dns":                   "DNS servers inside the tunnel (empty = system resolver).",
+	"wireguard.listen_port":           "Local UDP port (0 = random).",
+	"wireguard.mtu":                   "Tunnel MTU.",
+	"wireguard.peers":                 "Peers; the first one must have an endpoint.",
+	"wireguard.peers[]":               "WireGuard peer.",
+	"wireguard.peers[].public_key":    "Peer public key, base64.",
+	"wireguard.peers[].preshared_key": "Optional preshared key, base64.",
+	"wireguard.peers[].endpoint":      "Peer host:port.",
+	"wireguard.peers[].allowed_ips":   "Networks (CIDR) routed to the peer.",
+	"wireguard.peers[].keepalive_s":   "Persistent keepalive in seconds (0 = off).",
+}
+
+// Schema возвращает JSON Schema конфига.
+func Schema() map[string]any {
+	s := schemaOf("", reflect.TypeOf(HY2Config{}), schemaDefaults())
+	s["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	s["title"] = "HY2Config"
+	// server/password не нужны движку wireguard, ему нужна секция wireguard.
+	delete(s, "required")
+	s["if"] = map[string]any{
+		"properties": map[string]any{"engine": map[string]any{"const": "wireguard"}},
+		"required":   []string{"engine"},
+	}
+	s["then"] = map[string]any{"required": []string{"wireguard"}}
+	s["else"] = map[string]any{"required": []string{"server", "password"}}
+	return s
+}
+
+// SchemaJSON — Schema в виде JSON.
+func SchemaJSON() []byte {
+	b, _ := json.MarshalIndent(Schema(), "", "  ")
+	return b
+}
+
+func schemaOf(path string, t reflect.Type, defaults map[string]any) map[string]any {
+	s := map[string]any{}
+	if d, ok := schemaDocs[path]; ok {
+		s["description"] = d
+	}
+	if v, ok := defaults[path]; ok {
+		s["default"] = v
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		s["type"] = "object"
+		props := map[string]any{}
+		var required []string
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if name == "-" || !f.IsExported() {
+				continue
+			}
+			props[name] = schemaOf(joinPath(path, name), f.Type, defaults)
+			if !strings.Contains(opts, "omitempty") {
+				required = append(required, name)
+			}
+		}
+		s["properties"] = props
+		if len(required) > 0 {
+			s["required"] = required
+		}
+	case reflect.Slice:
+		s["type"] = "array"
+		s["items"] = schemaOf(path+"[]", t.Elem(), defaults)
+	case reflect.String:
+		s["type"] = "string"
+		if e, ok := schemaEnums[path]; ok {
+			s["enum"] = e
+		}
+	case reflect.Bool:
+		s["type"] = "boolean"
+	case reflect.Int:
+		s["type"] = "integer"
+	}
+	return s
+}
+
+// schemaDefaults собирает значения, которые Defaults() подставляет в пустые
+// поля. Часть дефолтов условна (metrics.listen — при enabled, wireguard.mtu —
+// для движка wireguard), поэтому Defaults() прогоняется на нескольких заготовках.
+// Дефолты, копирующие другие поля (fallback.password/sni), в схему не попадают.
+func schemaDefaults() map[string]any {
+	out := map[string]any{}
+	for _, seed := range []HY2Config{
+		{},
+		{Metrics: MetricsConfig{Enabled: true}},
+		{Engine: "wireguard"},
+	} {
+		c := seed
+		c.Defaults()
+		collectDefaults(out, "", reflect.ValueOf(seed), reflect.ValueOf(c))
+	}
+	return out
+}
+
+func collectDefaults(out map[string]any, path string, before, after reflect.Value) {
+	if before.Kind() == reflect.Struct {
+		t := before.Type()
+		for i := 0; i < t.NumField(); i++ {
+			name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+			collectDefaults(out, joinPath(path, name), before.Field(i), after.Field(i))
+		}
+		return
+	}
+	if !reflect.DeepEqual(before.Interface(), after.Interface()) {
+		out[path] = after.Interface()
+	}
+}
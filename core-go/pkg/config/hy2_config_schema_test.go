@@ -0,0 +1,83 @@
+//go:build mobile_skel
+
+package config
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// walkSchemaProps обходит схему и вызывает fn для каждого узла с путём в формате schemaDocs.
+func walkSchemaProps(path string, s map[string]any, fn func(path string, node map[string]any)) {
+	if path != "" {
+		fn(path, s)
+	}
+	if props, ok := s["properties"].(map[string]any); ok {
+		for name, p := range props {
+			walkSchemaProps(joinPath(path, name), p.(map[string]any), fn)
+		}
+	}
+	if items, ok := s["items"].(map[string]any); ok {
+		walkSchemaProps(path+"[]", items, fn)
+	}
+}
+
+// Схема должна покрывать все поля HY2Config, а таблицы описаний/enum —
+// не ссылаться на несуществующие поля.
+func TestSchema_InSync(t *testing.T) {
+	seen := map[string]bool{}
+	walkSchemaProps("", Schema(), func(path string, node map[string]any) {
+		seen[path] = true
+		if strings.HasSuffix(path, "[]") && node["type"] != "object" {
+			return // элементы скалярных массивов описывает сам массив
+		}
+		if _, ok := node["description"]; !ok {
+			t.Errorf("schema field %q has no description (add it to schemaDocs)", path)
+		}
+	})
+	for path := range schemaDocs {
+		if !seen[path] {
+			t.Errorf("schemaDocs has stale entry %q", path)
+		}
+	}
+	for path := range schemaEnums {
+		if !seen[path] {
+			t.Errorf("schemaEnums has stale entry %q", path)
+		}
+	}
+}
+
+func TestSchema_DefaultsAndEnums(t *testing.T) {
+	var s map[string]any
+	if err := json.Unmarshal(SchemaJSON(), &s); err != nil {
+		t.Fatalf("SchemaJSON is not valid JSON: %v", err)
+	}
+	nodes := map[string]map[string]any{}
+	walkSchemaProps("", s, func(path string, node map[string]any) { nodes[path] = node })
+
+	var def HY2Config
+	def.Defaults()
+	for path, want := range map[string]any{
+		"engine":         def.Engine,
+		"mode":           def.Mode,
+		"alpn":           []any{"h3"},
+		"metrics.listen": "127.0.0.1:9090",
+		"wireguard.mtu":  float64(1420),
+	} {
+		if got := nodes[path]["default"]; !reflect.DeepEqual(got, want) {
+			t.Errorf("%s default = %#v, want %#v", path, got, want)
+		}
+	}
+	if _, ok := nodes["password"]["default"]; ok {
+		t.Error("password must not have a default")
+	}
+	enum, _ := nodes["engine"]["enum"].([]any)
+	if len(enum) != len(Engines) {
+		t.Errorf("engine enum = %v, want %v", enum, Engines)
+	}
+	if req, _ := nodes["forwarding[]"]["required"].([]any); len(req) != 2 {
+		t.Errorf("forwarding[] required = %v, want [listen remote]", req)
+	}
+}
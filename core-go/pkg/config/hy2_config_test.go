@@ -35,3 +35,167 @@ func TestParseHY2Config_Invalid(t *testing.T) {
 		t.Fatal("expected error for invalid host:port and empty password")
 	}
 }
+
+func TestHY2Config_ValidateRoute(t *testing.T) {
+	cfg := HY2Config{Server: "example.com:443", Password: "secret"}
+	cfg.Route.Rules = []RouteRule{{PackageName: []string{"org.example.app"}, Outbound: "direct"}}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("valid route rejected: %v", err)
+	}
+
+	cfg.Route.Rules = append(cfg.Route.Rules, RouteRule{BundleID: []string{"com.example"}, Outbound: "hy2"})
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected error for unknown outbound")
+	}
+
+	cfg.Route.Rules = []RouteRule{{Outbound: "block"}}
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected error for rule without conditions")
+	}
+}
+
+func TestHY2Config_MetricsLoopbackOnly(t *testing.T) {
+	cfg := HY2Config{Server: "example.com:443", Password: "secret"}
+	cfg.Metrics.Enabled = true
+	cfg.Defaults()
+	if cfg.Metrics.Listen != "127.0.0.1:9090" {
+		t.Fatalf("default metrics.listen = %q", cfg.Metrics.Listen)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("loopback metrics rejected: %v", err)
+	}
+	cfg.Metrics.Listen = "0.0.0.0:9090"
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected error for non-loopback metrics.listen")
+	}
+}
+
+func TestHY2Config_TrafficInterval(t *testing.T) {
+	cfg := HY2Config{Server: "example.com:443", Password: "secret"}
+	for _, ms := range []int{0, -1, 1000, 5000} {
+		cfg.Telemetry.TrafficIntervalMs = ms
+		if err := cfg.Validate(); err != nil {
+			t.Fatalf("traffic_interval_ms=%d rejected: %v", ms, err)
+		}
+	}
+	cfg.Telemetry.TrafficIntervalMs = 200
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected error for traffic_interval_ms < 1000")
+	}
+}
+
+func TestHY2Config_LogSection(t *testing.T) {
+	cfg := HY2Config{Server: "example.com:443", Password: "secret"}
+	cfg.Log = LogConfig{Level: "debug", Format: "json"}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("valid log section rejected: %v", err)
+	}
+	cfg.Log = LogConfig{Level: "verbose"}
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected error for unknown log.level")
+	}
+	cfg.Log = LogConfig{Format: "xml"}
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected error for unknown log.format")
+	}
+}
+
+func TestHY2Config_PanicPolicy(t *testing.T) {
+	cfg := HY2Config{Server: "example.com:443", Password: "secret"}
+	for _, p := range []string{"", "restart", "stop"} {
+		cfg.Panic = PanicConfig{Policy: p}
+		if err := cfg.Validate(); err != nil {
+			t.Fatalf("panic.policy=%q rejected: %v", p, err)
+		}
+	}
+	cfg.Panic = PanicConfig{Policy: "crash"}
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected error for unknown panic.policy")
+	}
+}
+
+func TestHY2Config_Fallback(t *testing.T) {
+	cfg := HY2Config{Server: "example.com:443", Password: "secret", SNI: "example.com"}
+	cfg.Fallback = FallbackConfig{Enabled: true}
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("expected error for fallback without server")
+	}
+	cfg.Fallback.Server = "example.com:8443"
+	cfg.Defaults()
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("valid fallback rejected: %v", err)
+	}
+	if cfg.Fallback.Password != "secret" || cfg.Fallback.SNI != "example.com" {
+		t.Fatalf("fallback defaults not applied: %+v", cfg.Fallback)
+	}
+}
+
+func TestHY2Config_Detour(t *testing.T) {
+	cfg := HY2Config{Server: "example.com:443", Password: "secret", Engine: "sing"}
+	cfg.Detour = DetourConfig{Type: "socks5", Server: "proxy.corp:1080"}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("socks5 detour rejected: %v", err)
+	}
+	cfg.Detour.Type = "http"
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("http detour must be rejected for a UDP engine")
+	}
+	cfg.Engine = "trojan"
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("http detour rejected for trojan: %v", err)
+	}
+	cfg.Detour = DetourConfig{Type: "ftp", Server: "proxy.corp:21"}
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("unknown detour.type must be rejected")
+	}
+}
+
+func TestHY2Config_Forwarding(t *testing.T) {
+	cfg := HY2Config{Server: "example.com:443", Password: "secret"}
+	cfg.Forwarding = []ForwardConfig{
+		{Listen: "127.0.0.1:2222", Remote: "10.0.0.5:22"},
+		{Network: "udp", Listen: "127.0.0.1:2222", Remote: "10.0.0.5:53"},
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("valid forwarding rejected: %v", err)
+	}
+	cfg.Forwarding = append(cfg.Forwarding, ForwardConfig{Network: "tcp", Listen: "127.0.0.1:2222", Remote: "10.0.0.6:22"})
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("duplicate tcp listen must be rejected")
+	}
+	cfg.Forwarding = []ForwardConfig{{Network: "sctp", Listen: "127.0.0.1:1", Remote: "a:1"}}
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("unknown network must be rejected")
+	}
+}
+
+func TestHY2Config_WireGuard(t *testing.T) {
+	key := "YEocP0e2o1WT5GlvBvQzVF7EeR6z9aCk+ANZ1R5Wn3Y="
+	cfg := HY2Config{Engine: "wireguard"}
+	cfg.WireGuard = WireGuardConfig{
+		PrivateKey: key,
+		Address:    []string{"10.0.0.2/32"},
+		Peers:      []WireGuardPeer{{PublicKey: key, Endpoint: "wg.example:51820", AllowedIPs: []string{"0.0.0.0/0", "::/0"}}},
+	}
+	cfg.Defaults()
+	if cfg.WireGuard.MTU != 1420 {
+		t.Fatalf("default wireguard.mtu = %d", cfg.WireGuard.MTU)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("valid wireguard config rejected (server/password are not required): %v", err)
+	}
+	cfg.WireGuard.Peers[0].AllowedIPs = []string{"10.0.0.300/32"}
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("bad allowed_ips must be rejected")
+	}
+	cfg.WireGuard.Peers[0].AllowedIPs = nil
+	cfg.WireGuard.PrivateKey = "c2hvcnQ="
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("short private_key must be rejected")
+	}
+	cfg.WireGuard.PrivateKey = key
+	cfg.WireGuard.Peers = nil
+	if err := cfg.Validate(); err == nil {
+		t.Fatal("wireguard without peers must be rejected")
+	}
+}
@@ -0,0 +1,402 @@
+//go:build android || ios || mobile_skel
+
+package config
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	sjson "github.com/sagernet/sing/common/json"
+)
+
+// Уровни диагностик валидации.
+const (
+	SeverityError   = "error"   // конфиг нельзя применить
+	SeverityWarning = "warning" // конфиг применим, но, вероятно, с опечаткой
+)
+
+// Коды диагностик (стабильны: по ним редактор конфига подсвечивает поля).
+const (
+	CodeSyntax         = "syntax"          // JSON не разбирается
+	CodeTypeMismatch   = "type_mismatch"   // значение не того типа
+	CodeUnknownKey     = "unknown_key"     // ключ не из схемы HY2Config (warning)
+	CodeRequired       = "required"        // обязательное поле пустое
+	CodeInvalidAddress = "invalid_address" // ожидается host:port / CIDR / loopback
+	CodeInvalidValue   = "invalid_value"   // значение не из допустимого набора
+	CodeOutOfRange     = "out_of_range"    // число вне диапазона
+	CodeDuplicate      = "duplicate"       // повтор уникального значения
+	CodeConflict       = "conflict"        // поле несовместимо с другим полем
+	CodeUnsupported    = "unsupported"     // опция Hysteria2 без аналога в SDK (warning)
+	CodeDeprecated     = "deprecated"      // устаревшее поле/значение, см. Migrate (warning)
+)
+
+// Diagnostic — одна проблема конфига. Path — JSON-путь поля
+// (например, "route.rules[0].outbound"; "" — документ целиком).
+type Diagnostic struct {
+	Path     string `json:"path"`
+	Severity string `json:"severity"`
+	Code     string `json:"code"`
+	Message  string `json:"message"`
+}
+
+func (d Diagnostic) Error() string {
+	if d.Path == "" {
+		return d.Message
+	}
+	return d.Path + ": " + d.Message
+}
+
+// HasErrors сообщает, есть ли среди диагностик ошибки (не только warning).
+func HasErrors(diags []Diagnostic) bool {
+	return DiagnosticsError(diags) != nil
+}
+
+// DiagnosticsError сворачивает все ошибки в одну ("a: ...; b: ...");
+// nil — если ошибок нет (warning не считаются).
+func DiagnosticsError(diags []Diagnostic) error {
+	var msgs []string
+	for _, d := range diags {
+		if d.Severity == SeverityError {
+			msgs = append(msgs, d.Error())
+		}
+	}
+	if len(msgs) == 0 {
+		return nil
+	}
+	return errors.New(strings.Join(msgs, "; "))
+}
+
+// ValidateJSON — строгая проверка сырого конфига: синтаксис (с комментариями,
+// как в mobile.CfgSet), типы и неизвестные ключи по схеме HY2Config, затем
+// все семантические проверки Check (после Defaults). Возвращает все
+// диагностики сразу; пустой список — конфиг валиден.
+func ValidateJSON(raw []byte) []Diagnostic {
+	diags := []Diagnostic{}
+	tree, err := sjson.UnmarshalExtended[any](raw)
+	if err != nil {
+		return append(diags, Diagnostic{Severity: SeverityError, Code: CodeSyntax, Message: err.Error()})
+	}
+	if _, ok := tree.(map[string]any); !ok {
+		return append(diags, Diagnostic{Severity: SeverityError, Code: CodeTypeMismatch, Message: "config must be a JSON object"})
+	}
+	diags = walkSchema(diags, "", tree, reflect.TypeOf(HY2Config{}))
+
+	// Повторное кодирование снимает комментарии; поля с ошибкой типа
+	// json пропускает, а сами ошибки уже собраны walkSchema.
+	var hc HY2Config
+	b, _ := json.Marshal(tree)
+	_ = json.Unmarshal(b, &hc)
+	hc.Defaults()
+	hy2TestFixup(&hc)
+	return append(diags, hc.Check()...)
+}
+
+// walkSchema сверяет JSON-дерево v с Go-типом t: неизвестные ключи — warning,
+// несовпадение типа — error.
+func walkSchema(diags []Diagnostic, path string, v any, t reflect.Type) []Diagnostic {
+	if v == nil {
+		return diags
+	}
+	mismatch := func(want string) []Diagnostic {
+		return append(diags, Diagnostic{Path: path, Severity: SeverityError, Code: CodeTypeMismatch,
+			Message: fmt.Sprintf("expected %s, got %s", want, jsonKind(v))})
+	}
+	switch t.Kind() {
+	case reflect.Struct:
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return mismatch("object")
+		}
+		for _, key := range sortedKeys(obj) {
+			f, ok := fieldByJSONName(t, key)
+			if !ok {
+				diags = append(diags, Diagnostic{Path: joinPath(path, key), Severity: SeverityWarning,
+					Code: CodeUnknownKey, Message: fmt.Sprintf("unknown key %q", key)})
+				continue
+			}
+			diags = walkSchema(diags, joinPath(path, key), obj[key], f.Type)
+		}
+	case reflect.Slice:
+		arr, ok := v.([]any)
+		if !ok {
+			return mismatch("array")
+		}
+		for i, e := range arr {
+			diags = walkSchema(diags, path+"["+strconv.Itoa(i)+"]", e, t.Elem())
+		}
+	case reflect.String:
+		if _, ok := v.(string); !ok {
+			return mismatch("string")
+		}
+	case reflect.Bool:
+		if _, ok := v.(bool); !ok {
+			return mismatch("boolean")
+		}
+	case reflect.Int:
+		n, ok := v.(float64)
+		if !ok || n != float64(int64(n)) {
+			return mismatch("integer")
+		}
+	}
+	return diags
+}
+
+// fieldByJSONName ищет поле по JSON-имени; как и encoding/json, при
+// отсутствии точного совпадения принимает совпадение без учёта регистра.
+func fieldByJSONName(t reflect.Type, key string) (reflect.StructField, bool) {
+	var fold *reflect.StructField
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" || !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		if name == key {
+			return f, true
+		}
+		if fold == nil && strings.EqualFold(name, key) {
+			fold = &f
+		}
+	}
+	if fold != nil {
+		return *fold, true
+	}
+	return reflect.StructField{}, false
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys) // порядок диагностик не должен зависеть от обхода map
+	return keys
+}
+
+func jsonKind(v any) string {
+	switch x := v.(type) {
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case float64:
+		if x == float64(int64(x)) {
+			return "integer"
+		}
+		return "number"
+	}
+	return "null"
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// Check выполняет все семантические проверки конфига и возвращает
+// диагностики (без остановки на первой ошибке).
+func (c *HY2Config) Check() []Diagnostic {
+	var v checker
+	if c.Version < 0 || c.Version > CurrentVersion {
+		v.add("version", CodeUnsupported, fmt.Sprintf("must be between 0 and %d, got %d", CurrentVersion, c.Version))
+	}
+	if c.Engine != "" && !oneOf(c.Engine, Engines) {
+		v.add("engine", CodeInvalidValue, fmt.Sprintf("unknown engine %q", c.Engine))
+	}
+	if c.Mode != "" && !oneOf(c.Mode, Modes) {
+		v.add("mode", CodeInvalidValue, fmt.Sprintf("must be one of %s, got %q", strings.Join(Modes, ", "), c.Mode))
+	}
+	if c.Engine == "wireguard" {
+		// У WireGuard свой сервер (peers[].endpoint) и ключи вместо пароля.
+		v.wireguard(c.WireGuard)
+	} else {
+		if c.Server == "" || !strings.Contains(c.Server, ":") {
+			v.add("server", CodeInvalidAddress, "must be host:port")
+		}
+		if c.Password == "" {
+			v.add("password", CodeRequired, "required")
+		}
+	}
+	if c.Obfs.Type != "" {
+		if c.Obfs.Type != "salamander" {
+			v.add("obfs.type", CodeInvalidValue, fmt.Sprintf("must be salamander, got %q", c.Obfs.Type))
+		}
+		if c.Obfs.Password == "" {
+			v.add("obfs.password", CodeRequired, "required when obfs.type is set")
+		}
+	}
+	for i, r := range c.Route.Rules {
+		p := fmt.Sprintf("route.rules[%d]", i)
+		if len(r.PackageName) == 0 && len(r.BundleID) == 0 {
+			v.add(p, CodeRequired, "package_name or bundle_id required")
+		}
+		if !validOutbound(r.Outbound) {
+			v.add(p+".outbound", CodeInvalidValue, fmt.Sprintf("unknown outbound %q", r.Outbound))
+		}
+	}
+	if c.Route.Final != "" && !validOutbound(c.Route.Final) {
+		v.add("route.final", CodeInvalidValue, fmt.Sprintf("unknown outbound %q", c.Route.Final))
+	}
+	if c.Inbound.Password != "" && c.Inbound.Username == "" {
+		v.add("inbound.username", CodeRequired, "required when inbound.password is set")
+	}
+	if c.Metrics.Enabled {
+		host, _, err := net.SplitHostPort(c.Metrics.Listen)
+		if err != nil {
+			v.add("metrics.listen", CodeInvalidAddress, "must be host:port")
+		} else if ip := net.ParseIP(host); host != "localhost" && (ip == nil || !ip.IsLoopback()) {
+			v.add("metrics.listen", CodeInvalidAddress, "must be a loopback address")
+		}
+	}
+	if ms := c.Telemetry.TrafficIntervalMs; ms > 0 && ms < 1000 {
+		v.add("telemetry.traffic_interval_ms", CodeOutOfRange, "must be >= 1000")
+	}
+	if p := c.Panic.Policy; p != "" && p != "restart" && p != "stop" {
+		v.add("panic.policy", CodeInvalidValue, fmt.Sprintf("must be restart or stop, got %q", p))
+	}
+	if c.Panic.MaxRestarts < 0 {
+		v.add("panic.max_restarts", CodeOutOfRange, "must be >= 0")
+	}
+	switch c.Log.Level {
+	case "", "debug", "info", "warn", "error":
+	default:
+		v.add("log.level", CodeInvalidValue, fmt.Sprintf("unknown level %q", c.Log.Level))
+	}
+	if c.Log.Format != "" && c.Log.Format != "text" && c.Log.Format != "json" {
+		v.add("log.format", CodeInvalidValue, fmt.Sprintf("must be text or json, got %q", c.Log.Format))
+	}
+	if c.Fallback.Enabled {
+		if _, _, err := net.SplitHostPort(c.Fallback.Server); err != nil {
+			v.add("fallback.server", CodeInvalidAddress, "must be host:port")
+		}
+		if c.Fallback.AfterUDPFailures < 0 {
+			v.add("fallback.after_udp_failures", CodeOutOfRange, "must be >= 0")
+		}
+	}
+	if d := c.Detour; d.Type != "" {
+		if d.Type != "socks5" && d.Type != "http" {
+			v.add("detour.type", CodeInvalidValue, fmt.Sprintf("must be socks5 or http, got %q", d.Type))
+		}
+		if _, _, err := net.SplitHostPort(d.Server); err != nil {
+			v.add("detour.server", CodeInvalidAddress, "must be host:port")
+		}
+		if d.Type == "http" && c.Engine != "trojan" {
+			v.add("detour.type", CodeConflict, `http cannot carry UDP: use socks5 or engine "trojan"`)
+		}
+	}
+	seen := map[string]bool{}
+	for i, f := range c.Forwarding {
+		p := fmt.Sprintf("forwarding[%d]", i)
+		if f.Network != "" && f.Network != "tcp" && f.Network != "udp" {
+			v.add(p+".network", CodeInvalidValue, fmt.Sprintf("must be tcp or udp, got %q", f.Network))
+		}
+		if _, _, err := net.SplitHostPort(f.Listen); err != nil {
+			v.add(p+".listen", CodeInvalidAddress, "must be host:port")
+		}
+		if _, _, err := net.SplitHostPort(f.Remote); err != nil {
+			v.add(p+".remote", CodeInvalidAddress, "must be host:port")
+		}
+		if f.UDPTimeoutS < 0 {
+			v.add(p+".udp_timeout_s", CodeOutOfRange, "must be >= 0")
+		}
+		key := f.Network + "/" + f.Listen
+		if f.Network == "" {
+			key = "tcp/" + f.Listen
+		}
+		if seen[key] {
+			v.add(p+".listen", CodeDuplicate, "duplicate "+key)
+		}
+		seen[key] = true
+	}
+	return v.diags
+}
+
+func (v *checker) wireguard(w WireGuardConfig) {
+	if !validWGKey(w.PrivateKey) {
+		v.add("wireguard.private_key", CodeInvalidValue, "must be a base64 32-byte key")
+	}
+	if len(w.Address) == 0 {
+		v.add("wireguard.address", CodeRequired, "at least one address required")
+	}
+	for i, a := range w.Address {
+		if _, err := netip.ParsePrefix(a); err != nil {
+			v.add(fmt.Sprintf("wireguard.address[%d]", i), CodeInvalidAddress, fmt.Sprintf("%q is not a CIDR", a))
+		}
+	}
+	for i, a := range w.DNS {
+		if _, err := netip.ParseAddr(a); err != nil {
+			v.add(fmt.Sprintf("wireguard.dns[%d]", i), CodeInvalidAddress, fmt.Sprintf("%q is not an IP address", a))
+		}
+	}
+	if w.ListenPort < 0 || w.ListenPort > 65535 {
+		v.add("wireguard.listen_port", CodeOutOfRange, "must be 0..65535")
+	}
+	if w.MTU != 0 && (w.MTU < 576 || w.MTU > 65535) {
+		v.add("wireguard.mtu", CodeOutOfRange, "must be 576..65535")
+	}
+	if len(w.Peers) == 0 {
+		v.add("wireguard.peers", CodeRequired, "at least one peer required")
+		return
+	}
+	for i, p := range w.Peers {
+		pp := fmt.Sprintf("wireguard.peers[%d]", i)
+		if !validWGKey(p.PublicKey) {
+			v.add(pp+".public_key", CodeInvalidValue, "must be a base64 32-byte key")
+		}
+		if p.PresharedKey != "" && !validWGKey(p.PresharedKey) {
+			v.add(pp+".preshared_key", CodeInvalidValue, "must be a base64 32-byte key")
+		}
+		if p.Endpoint != "" {
+			if _, _, err := net.SplitHostPort(p.Endpoint); err != nil {
+				v.add(pp+".endpoint", CodeInvalidAddress, "must be host:port")
+			}
+		} else if i == 0 {
+			v.add(pp+".endpoint", CodeRequired, "required")
+		}
+		for j, a := range p.AllowedIPs {
+			if _, err := netip.ParsePrefix(a); err != nil {
+				v.add(fmt.Sprintf("%s.allowed_ips[%d]", pp, j), CodeInvalidAddress, fmt.Sprintf("%q is not a CIDR", a))
+			}
+		}
+		if p.KeepaliveS < 0 || p.KeepaliveS > 65535 {
+			v.add(pp+".keepalive_s", CodeOutOfRange, "must be 0..65535")
+		}
+	}
+}
+
+type checker struct{ diags []Diagnostic }
+
+func (v *checker) add(path, code, msg string) {
+	v.diags = append(v.diags, Diagnostic{Path: path, Severity: SeverityError, Code: code, Message: msg})
+}
+
+func oneOf(s string, set []string) bool {
+	for _, v := range set {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
+func validWGKey(s string) bool {
+	b, err := base64.StdEncoding.DecodeString(s)
+	return err == nil && len(b) == 32
+}
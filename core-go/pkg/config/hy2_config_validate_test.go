@@ -0,0 +1,79 @@
+//go:build mobile_skel
+
+package config
+
+import "testing"
+
+func findDiag(diags []Diagnostic, path, code string) *Diagnostic {
+	for i := range diags {
+		if diags[i].Path == path && diags[i].Code == code {
+			return &diags[i]
+		}
+	}
+	return nil
+}
+
+func TestValidateJSON_AllErrorsAtOnce(t *testing.T) {
+	raw := `{
+		// комментарии допустимы, как в CfgSet
+		"server": "no-port",
+		"password": "secret",
+		"up_mbps": "fast",
+		"route": {"rules": [{"package_name": ["a"], "outbound": "hy2"}], "final": "nowhere"},
+		"forwarding": [{"listen": "127.0.0.1:1", "remote": "bad"}],
+		"outbounds": [],
+		"log": {"level": "loud", "colour": true}
+	}`
+	diags := ValidateJSON([]byte(raw))
+
+	for _, want := range []struct{ path, code, severity string }{
+		{"server", CodeInvalidAddress, SeverityError},
+		{"up_mbps", CodeTypeMismatch, SeverityError},
+		{"route.rules[0].outbound", CodeInvalidValue, SeverityError},
+		{"route.final", CodeInvalidValue, SeverityError},
+		{"forwarding[0].remote", CodeInvalidAddress, SeverityError},
+		{"log.level", CodeInvalidValue, SeverityError},
+		{"outbounds", CodeUnknownKey, SeverityWarning},
+		{"log.colour", CodeUnknownKey, SeverityWarning},
+	} {
+		d := findDiag(diags, want.path, want.code)
+		if d == nil {
+			t.Errorf("missing %s/%s in %+v", want.path, want.code, diags)
+			continue
+		}
+		if d.Severity != want.severity {
+			t.Errorf("%s: severity %q, want %q", want.path, d.Severity, want.severity)
+		}
+	}
+	if !HasErrors(diags) {
+		t.Fatal("HasErrors must be true")
+	}
+}
+
+func TestValidateJSON_WarningsOnly(t *testing.T) {
+	diags := ValidateJSON([]byte(`{"server":"a.example:443","password":"p","inbounds":[]}`))
+	if len(diags) != 1 || diags[0].Code != CodeUnknownKey || diags[0].Path != "inbounds" {
+		t.Fatalf("expected single unknown_key warning, got %+v", diags)
+	}
+	if err := DiagnosticsError(diags); err != nil {
+		t.Fatalf("warnings must not produce an error: %v", err)
+	}
+}
+
+func TestValidateJSON_Syntax(t *testing.T) {
+	diags := ValidateJSON([]byte(`{ bad json`))
+	if len(diags) != 1 || diags[0].Code != CodeSyntax || diags[0].Severity != SeverityError {
+		t.Fatalf("expected syntax error, got %+v", diags)
+	}
+}
+
+func TestValidate_FirstErrorHasPath(t *testing.T) {
+	cfg := HY2Config{Server: "bad"}
+	err := cfg.Validate()
+	if err == nil || err.Error() != "server: must be host:port" {
+		t.Fatalf("unexpected Validate error: %v", err)
+	}
+	if n := len(cfg.Check()); n != 2 {
+		t.Fatalf("Check must report server and password, got %d", n)
+	}
+}
@@ -0,0 +1,341 @@
+//go:build android || ios || mobile_skel
+
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Конфиг клиента Hysteria2 в YAML (формат официального клиента) как входной
+// формат SDK: пользователь вставляет свой client.yaml, а ядро переводит его
+// в HY2Config. Опции без аналога в SDK не ломают конфиг — по ним
+// возвращаются предупреждения CodeUnsupported.
+
+// hysteriaClientYAML — подмножество схемы клиента Hysteria2, которое мы разбираем.
+type hysteriaClientYAML struct {
+	Server string `yaml:"server"`
+	Auth   string `yaml:"auth"`
+	TLS    struct {
+		SNI       string `yaml:"sni"`
+		Insecure  bool   `yaml:"insecure"`
+		PinSHA256 string `yaml:"pinSHA256"`
+		CA        string `yaml:"ca"`
+	} `yaml:"tls"`
+	Obfs struct {
+		Type       string `yaml:"type"`
+		Salamander struct {
+			Password string `yaml:"password"`
+		} `yaml:"salamander"`
+	} `yaml:"obfs"`
+	QUIC struct {
+		MaxIdleTimeout string `yaml:"maxIdleTimeout"`
+	} `yaml:"quic"`
+	Bandwidth struct {
+		Up   string `yaml:"up"`
+		Down string `yaml:"down"`
+	} `yaml:"bandwidth"`
+	SOCKS5 struct {
+		Listen     string `yaml:"listen"`
+		Username   string `yaml:"username"`
+		Password   string `yaml:"password"`
+		DisableUDP bool   `yaml:"disableUDP"`
+	} `yaml:"socks5"`
+	HTTP struct {
+		Listen   string `yaml:"listen"`
+		Username string `yaml:"username"`
+		Password string `yaml:"password"`
+		Realm    string `yaml:"realm"`
+	} `yaml:"http"`
+	TCPForwarding []struct {
+		Listen string `yaml:"listen"`
+		Remote string `yaml:"remote"`
+	} `yaml:"tcpForwarding"`
+	UDPForwarding []struct {
+		Listen  string `yaml:"listen"`
+		Remote  string `yaml:"remote"`
+		Timeout string `yaml:"timeout"`
+	} `yaml:"udpForwarding"`
+}
+
+// hysteriaKnownKeys — ключи верхнего уровня, которые переводятся (полностью
+// или частично); hysteriaUnsupportedKeys — известные опции клиента без аналога.
+var (
+	hysteriaKnownKeys = map[string]bool{
+		"server": true, "auth": true, "tls": true, "obfs": true, "quic": true, "bandwidth": true,
+		"socks5": true, "http": true, "tcpForwarding": true, "udpForwarding": true,
+	}
+	hysteriaUnsupportedKeys = map[string]bool{
+		"transport": true, "fastOpen": true, "lazy": true, "tun": true,
+		"tcpTProxy": true, "udpTProxy": true, "tcpRedirect": true,
+	}
+)
+
+// IsJSON сообщает, похож ли документ на JSON (первый значимый символ — '{').
+// Всё остальное считается YAML-конфигом клиента Hysteria2.
+func IsJSON(raw []byte) bool {
+	b := bytes.TrimSpace(raw)
+	// комментарии допускает расширенный JSON (см. mobile.CfgSet)
+	for bytes.HasPrefix(b, []byte("//")) || bytes.HasPrefix(b, []byte("/*")) {
+		end := []byte("\n")
+		if b[1] == '*' {
+			end = []byte("*/")
+		}
+		i := bytes.Index(b, end)
+		if i < 0 {
+			return false
+		}
+		b = bytes.TrimSpace(b[i+len(end):])
+	}
+	return len(b) > 0 && b[0] == '{'
+}
+
+// Normalize приводит входной конфиг к JSON-диалекту SDK: JSON возвращается
+// как есть, YAML клиента Hysteria2 переводится FromHysteriaYAML.
+// Диагностики — предупреждения перевода (для JSON — пусто).
+func Normalize(raw []byte) ([]byte, []Diagnostic, error) {
+	if IsJSON(raw) {
+		return raw, nil, nil
+	}
+	hc, diags, err := FromHysteriaYAML(raw)
+	if err != nil {
+		return nil, diags, err
+	}
+	b, err := json.Marshal(hc)
+	return b, diags, err
+}
+
+// FromHysteriaYAML переводит YAML-конфиг клиента Hysteria2 в HY2Config
+// (без Defaults/Validate). Ошибка — только если YAML не разбирается или
+// значение нельзя перевести (например, bandwidth "fast"); опции без
+// аналога в SDK возвращаются предупреждениями с путями в терминах YAML.
+func FromHysteriaYAML(raw []byte) (HY2Config, []Diagnostic, error) {
+	hc := HY2Config{Version: CurrentVersion}
+	var top map[string]any
+	if err := yaml.Unmarshal(raw, &top); err != nil {
+		return hc, nil, fmt.Errorf("hysteria yaml: %w", err)
+	}
+	if top == nil {
+		return hc, nil, fmt.Errorf("hysteria yaml: empty document")
+	}
+	var y hysteriaClientYAML
+	if err := yaml.Unmarshal(raw, &y); err != nil {
+		return hc, nil, fmt.Errorf("hysteria yaml: %w", err)
+	}
+
+	var diags []Diagnostic
+	warn := func(path, code, msg string) {
+		diags = append(diags, Diagnostic{Path: path, Severity: SeverityWarning, Code: code, Message: msg})
+	}
+	keys := make([]string, 0, len(top))
+	for k := range top {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		switch {
+		case hysteriaUnsupportedKeys[k]:
+			warn(k, CodeUnsupported, "not supported by the SDK; ignored")
+		case !hysteriaKnownKeys[k]:
+			warn(k, CodeUnknownKey, fmt.Sprintf("unknown key %q", k))
+		}
+	}
+
+	// server: host[:port] или URI hysteria2://auth@host:port/?sni=...
+	hc.Password = y.Auth
+	server := y.Server
+	if strings.Contains(server, "://") {
+		u, host, err := parseHysteriaURI(server)
+		if err != nil {
+			return hc, diags, fmt.Errorf("hysteria yaml: server: unsupported URI %q", server)
+		}
+		server = host
+		if hc.Password == "" && u.User != nil {
+			// режим userpass кодируется как user:pass
+			hc.Password = u.User.Username()
+			if p, ok := u.User.Password(); ok {
+				hc.Password += ":" + p
+			}
+		}
+		q := u.Query()
+		if y.TLS.SNI == "" {
+			y.TLS.SNI = q.Get("sni")
+		}
+		if q.Get("insecure") == "1" || q.Get("insecure") == "true" {
+			y.TLS.Insecure = true
+		}
+		if y.Obfs.Type == "" && q.Get("obfs") != "" {
+			y.Obfs.Type = q.Get("obfs")
+			y.Obfs.Salamander.Password = q.Get("obfs-password")
+		}
+		if q.Get("pinSHA256") != "" && y.TLS.PinSHA256 == "" {
+			y.TLS.PinSHA256 = q.Get("pinSHA256")
+		}
+	}
+	hc.Server, diags = hysteriaServer(server, diags)
+
+	hc.SNI = y.TLS.SNI
+	hc.Insecure = y.TLS.Insecure
+	if y.TLS.PinSHA256 != "" {
+		warn("tls.pinSHA256", CodeUnsupported, "certificate pinning is not supported by the SDK; ignored")
+	}
+	if y.TLS.CA != "" {
+		warn("tls.ca", CodeUnsupported, "custom CA files are not supported by the SDK; ignored")
+	}
+	if y.Obfs.Type != "" {
+		hc.Obfs = ObfsConfig{Type: y.Obfs.Type, Password: y.Obfs.Salamander.Password}
+	}
+
+	var err error
+	if hc.UpMbps, err = parseBandwidthMbps(y.Bandwidth.Up); err != nil {
+		return hc, diags, fmt.Errorf("hysteria yaml: bandwidth.up: %w", err)
+	}
+	if hc.DownMbps, err = parseBandwidthMbps(y.Bandwidth.Down); err != nil {
+		return hc, diags, fmt.Errorf("hysteria yaml: bandwidth.down: %w", err)
+	}
+	if y.QUIC.MaxIdleTimeout != "" {
+		d, err := time.ParseDuration(y.QUIC.MaxIdleTimeout)
+		if err != nil {
+			return hc, diags, fmt.Errorf("hysteria yaml: quic.maxIdleTimeout: %w", err)
+		}
+		hc.IdleTimeoutS = int(math.Ceil(d.Seconds()))
+	}
+	if q, ok := top["quic"].(map[string]any); ok {
+		for _, k := range sortedKeys(q) {
+			if k != "maxIdleTimeout" {
+				warn("quic."+k, CodeUnsupported, "QUIC tuning is not supported by the SDK; ignored")
+			}
+		}
+	}
+
+	// Локальные инбаунды: адрес задаёт хост-приложение (StartTun2Socks),
+	// из YAML берутся только креды.
+	if y.SOCKS5.Listen != "" {
+		warn("socks5.listen", CodeUnsupported, "the local SOCKS5 address is set by the host app; ignored")
+	}
+	if y.SOCKS5.DisableUDP {
+		warn("socks5.disableUDP", CodeUnsupported, "not supported by the SDK; ignored")
+	}
+	if y.HTTP.Listen != "" {
+		warn("http.listen", CodeUnsupported, "the local HTTP proxy address is set by the host app; ignored")
+	}
+	if y.HTTP.Realm != "" {
+		warn("http.realm", CodeUnsupported, "not supported by the SDK; ignored")
+	}
+	hc.Inbound.Username, hc.Inbound.Password = y.SOCKS5.Username, y.SOCKS5.Password
+	if y.HTTP.Username != "" {
+		if hc.Inbound.Username == "" {
+			hc.Inbound.Username, hc.Inbound.Password = y.HTTP.Username, y.HTTP.Password
+		} else if y.HTTP.Username != y.SOCKS5.Username || y.HTTP.Password != y.SOCKS5.Password {
+			warn("http.username", CodeConflict, "SOCKS5 and HTTP inbounds share credentials in the SDK; socks5 credentials are used")
+		}
+	}
+
+	for _, f := range y.TCPForwarding {
+		hc.Forwarding = append(hc.Forwarding, ForwardConfig{Network: "tcp", Listen: f.Listen, Remote: f.Remote})
+	}
+	for i, f := range y.UDPForwarding {
+		fc := ForwardConfig{Network: "udp", Listen: f.Listen, Remote: f.Remote}
+		if f.Timeout != "" {
+			d, err := time.ParseDuration(f.Timeout)
+			if err != nil {
+				return hc, diags, fmt.Errorf("hysteria yaml: udpForwarding[%d].timeout: %w", i, err)
+			}
+			fc.UDPTimeoutS = int(math.Ceil(d.Seconds()))
+		}
+		hc.Forwarding = append(hc.Forwarding, fc)
+	}
+	return hc, diags, nil
+}
+
+// parseHysteriaURI разбирает hysteria2:// URI. net/url не принимает диапазон
+// портов (port hopping), поэтому host:port вырезается до разбора и
+// возвращается отдельно как есть.
+func parseHysteriaURI(s string) (*url.URL, string, error) {
+	scheme, rest, _ := strings.Cut(s, "://")
+	if scheme != "hysteria2" && scheme != "hy2" {
+		return nil, "", fmt.Errorf("unsupported scheme %q", scheme)
+	}
+	end := strings.IndexAny(rest, "/?#")
+	if end < 0 {
+		end = len(rest)
+	}
+	authority := rest[:end]
+	host := authority
+	if at := strings.LastIndex(authority, "@"); at >= 0 {
+		host = authority[at+1:]
+		authority = authority[:at+1] + "host"
+	} else {
+		authority = "host"
+	}
+	u, err := url.Parse(scheme + "://" + authority + rest[end:])
+	if err != nil {
+		return nil, "", err
+	}
+	return u, host, nil
+}
+
+// hysteriaServer нормализует адрес сервера: порт по умолчанию 443;
+// port hopping ("host:20000-50000", "host:443,8443") не поддерживается —
+// берётся первый порт с предупреждением.
+func hysteriaServer(s string, diags []Diagnostic) (string, []Diagnostic) {
+	if s == "" {
+		return "", diags
+	}
+	host, port, err := net.SplitHostPort(s)
+	if err != nil {
+		// нет порта (или IPv6 без скобок) — порт Hysteria2 по умолчанию
+		return net.JoinHostPort(strings.Trim(s, "[]"), "443"), diags
+	}
+	if i := strings.IndexAny(port, "-,"); i >= 0 {
+		diags = append(diags, Diagnostic{Path: "server", Severity: SeverityWarning, Code: CodeUnsupported,
+			Message: fmt.Sprintf("port hopping %q is not supported by the SDK; using port %s", port, port[:i])})
+		port = port[:i]
+	}
+	return net.JoinHostPort(host, port), diags
+}
+
+// parseBandwidthMbps разбирает пропускную способность в нотации Hysteria2
+// ("100 mbps", "1g", "500kbps"; без единиц — бит/с) и переводит в Мбит/с
+// (округление вверх, чтобы малые значения не превращались в 0 = «без лимита»).
+func parseBandwidthMbps(s string) (int, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return 0, nil
+	}
+	i := strings.IndexFunc(s, func(r rune) bool { return (r < '0' || r > '9') && r != '.' })
+	num, unit := s, ""
+	if i >= 0 {
+		num, unit = strings.TrimSpace(s[:i]), strings.TrimSpace(s[i:])
+	}
+	v, err := strconv.ParseFloat(num, 64)
+	if err != nil || v < 0 {
+		return 0, fmt.Errorf("invalid bandwidth %q", s)
+	}
+	var mult float64
+	switch unit {
+	case "", "b", "bps":
+		mult = 1e-6
+	case "k", "kb", "kbps":
+		mult = 1e-3
+	case "m", "mb", "mbps":
+		mult = 1
+	case "g", "gb", "gbps":
+		mult = 1e3
+	case "t", "tb", "tbps":
+		mult = 1e6
+	default:
+		return 0, fmt.Errorf("invalid bandwidth unit in %q", s)
+	}
+	return int(math.Ceil(v*mult - 1e-9)), nil
+}
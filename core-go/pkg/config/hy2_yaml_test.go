@@ -0,0 +1,122 @@
+//go:build mobile_skel
+
+package config
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+const hysteriaClientSample = `
+server: hy2.example.com:8443
+auth: s3cret
+tls:
+  sni: cdn.example.com
+  insecure: true
+  pinSHA256: BA:88:45
+obfs:
+  type: salamander
+  salamander:
+    password: cry_me_a_r1ver
+bandwidth:
+  up: 30 mbps
+  down: 1 gbps
+quic:
+  maxIdleTimeout: 30s
+  keepAlivePeriod: 10s
+fastOpen: true
+socks5:
+  listen: 127.0.0.1:1080
+  username: user
+  password: pass
+http:
+  listen: 127.0.0.1:8080
+tcpForwarding:
+  - listen: 127.0.0.1:6600
+    remote: 10.0.0.1:22
+udpForwarding:
+  - listen: 127.0.0.1:5300
+    remote: 1.1.1.1:53
+    timeout: 20s
+`
+
+func TestFromHysteriaYAML_Translates(t *testing.T) {
+	hc, diags, err := FromHysteriaYAML([]byte(hysteriaClientSample))
+	if err != nil {
+		t.Fatalf("FromHysteriaYAML: %v", err)
+	}
+	if hc.Server != "hy2.example.com:8443" || hc.Password != "s3cret" || hc.SNI != "cdn.example.com" || !hc.Insecure {
+		t.Fatalf("server/auth/tls not translated: %+v", hc)
+	}
+	if hc.Obfs != (ObfsConfig{Type: "salamander", Password: "cry_me_a_r1ver"}) {
+		t.Fatalf("obfs not translated: %+v", hc.Obfs)
+	}
+	if hc.UpMbps != 30 || hc.DownMbps != 1000 || hc.IdleTimeoutS != 30 {
+		t.Fatalf("bandwidth/quic not translated: up=%d down=%d idle=%d", hc.UpMbps, hc.DownMbps, hc.IdleTimeoutS)
+	}
+	if hc.Inbound.Username != "user" || hc.Inbound.Password != "pass" {
+		t.Fatalf("socks5 credentials not translated: %+v", hc.Inbound)
+	}
+	want := []ForwardConfig{
+		{Network: "tcp", Listen: "127.0.0.1:6600", Remote: "10.0.0.1:22"},
+		{Network: "udp", Listen: "127.0.0.1:5300", Remote: "1.1.1.1:53", UDPTimeoutS: 20},
+	}
+	if len(hc.Forwarding) != 2 || hc.Forwarding[0] != want[0] || hc.Forwarding[1] != want[1] {
+		t.Fatalf("forwarding not translated: %+v", hc.Forwarding)
+	}
+	for _, path := range []string{"fastOpen", "tls.pinSHA256", "quic.keepAlivePeriod", "socks5.listen", "http.listen"} {
+		if d := findDiag(diags, path, CodeUnsupported); d == nil || d.Severity != SeverityWarning {
+			t.Errorf("expected unsupported warning for %s, got %+v", path, diags)
+		}
+	}
+	hc.Defaults()
+	if err := hc.Validate(); err != nil {
+		t.Fatalf("translated config is invalid: %v", err)
+	}
+}
+
+func TestFromHysteriaYAML_URIAndDefaults(t *testing.T) {
+	hc, diags, err := FromHysteriaYAML([]byte(`server: "hysteria2://user:pw@hy2.example.com:20000-30000/?sni=a.example&obfs=salamander&obfs-password=x"`))
+	if err != nil {
+		t.Fatalf("FromHysteriaYAML: %v", err)
+	}
+	if hc.Server != "hy2.example.com:20000" || hc.Password != "user:pw" || hc.SNI != "a.example" || hc.Obfs.Password != "x" {
+		t.Fatalf("URI not translated: %+v", hc)
+	}
+	if findDiag(diags, "server", CodeUnsupported) == nil {
+		t.Fatalf("port hopping must produce a warning: %+v", diags)
+	}
+
+	hc, _, err = FromHysteriaYAML([]byte("server: hy2.example.com\nauth: p\n"))
+	if err != nil || hc.Server != "hy2.example.com:443" {
+		t.Fatalf("default port not applied: %q, %v", hc.Server, err)
+	}
+	if _, _, err := FromHysteriaYAML([]byte("server: a:1\nbandwidth:\n  up: fast\n")); err == nil {
+		t.Fatal("bad bandwidth must be rejected")
+	}
+}
+
+func TestNormalize_DetectsFormat(t *testing.T) {
+	js := []byte(`// comment
+{"server":"a:1","password":"p"}`)
+	out, _, err := Normalize(js)
+	if err != nil || string(out) != string(js) {
+		t.Fatalf("JSON must pass through unchanged: %q, %v", out, err)
+	}
+	out, _, err = Normalize([]byte("server: a.example:443\nauth: p\n"))
+	if err != nil {
+		t.Fatalf("Normalize(yaml): %v", err)
+	}
+	var hc HY2Config
+	if err := json.Unmarshal(out, &hc); err != nil || hc.Server != "a.example:443" || hc.Password != "p" {
+		t.Fatalf("Normalize produced %s (%v)", out, err)
+	}
+}
+
+func TestParseBandwidthMbps(t *testing.T) {
+	for in, want := range map[string]int{"": 0, "100 mbps": 100, "1g": 1000, "500kbps": 1, "2500000": 3, "1.5 Gbps": 1500} {
+		if got, err := parseBandwidthMbps(in); err != nil || got != want {
+			t.Errorf("parseBandwidthMbps(%q) = %d, %v; want %d", in, got, err, want)
+		}
+	}
+}
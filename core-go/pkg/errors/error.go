@@ -0,0 +1,182 @@
+//go:build android || ios || mobile_skel
+
+package errors
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	stderrors "errors"
+	"net"
+	"os"
+	"syscall"
+)
+
+// Stage — этап, на котором произошла ошибка (для UI и аналитики).
+type Stage string
+
+const (
+	StageConfig    Stage = "config"    // разбор/валидация конфига
+	StageResolve   Stage = "resolve"   // DNS сервера
+	StageDial      Stage = "dial"      // установка соединения
+	StageHandshake Stage = "handshake" // TLS/QUIC-рукопожатие
+	StageAuth      Stage = "auth"      // авторизация на сервере
+	StageProtect   Stage = "protect"   // VpnService.protect
+	StageTun       Stage = "tun"       // TUN-интерфейс
+	StageRuntime   Stage = "runtime"   // работа ядра после старта
+)
+
+// Error — ошибка ядра с кодом, этапом и причиной.
+// Поддерживает errors.Is/As: Is(err, ErrCode) сравнивает коды,
+// Unwrap отдаёт исходную причину (net.DNSError, x509.* и т.п.).
+type Error struct {
+	Code  ErrCode
+	Stage Stage
+	Msg   string // короткое описание; может быть пустым
+	Cause error  // исходная ошибка; может быть nil
+}
+
+// PinMismatchError — сертификат сервера не совпал ни с одним из пинов
+// (SHA-256). Её возвращают проверки пина в tls.Config.VerifyPeerCertificate /
+// VerifyConnection; Classify даёт ей код ErrCertPinMismatch.
+type PinMismatchError struct {
+	Got string // SHA-256 сертификата сервера (hex)
+}
+
+func (e *PinMismatchError) Error() string {
+	return "tls: server certificate " + e.Got + " does not match the pinned SHA-256"
+}
+
+// New создаёт ошибку без причины.
+func New(code ErrCode, stage Stage, msg string) *Error {
+	return &Error{Code: code, Stage: stage, Msg: msg}
+}
+
+// Wrap оборачивает cause кодом и этапом. nil cause даёт nil.
+func Wrap(cause error, code ErrCode, stage Stage, msg string) *Error {
+	if cause == nil {
+		return nil
+	}
+	return &Error{Code: code, Stage: stage, Msg: msg, Cause: cause}
+}
+
+func (e *Error) Error() string {
+	s := e.Code.String()
+	if e.Stage != "" {
+		s = string(e.Stage) + ": " + s
+	}
+	if e.Msg != "" {
+		s += ": " + e.Msg
+	}
+	if e.Cause != nil {
+		s += ": " + e.Cause.Error()
+	}
+	return s
+}
+
+func (e *Error) Unwrap() error { return e.Cause }
+
+// Is: цель ErrCode совпадает по коду; цель *Error — по коду и (если задан) этапу.
+func (e *Error) Is(target error) bool {
+	switch t := target.(type) {
+	case ErrCode:
+		return e.Code == t
+	case *Error:
+		return e.Code == t.Code && (t.Stage == "" || e.Stage == t.Stage)
+	}
+	return false
+}
+
+// Retryable — см. ErrCode.Retryable.
+func (e *Error) Retryable() bool { return e.Code.Retryable() }
+
+// Mobile — форма для клиентов (Kotlin/Swift).
+func (e *Error) Mobile() MobileError {
+	msg := e.Msg
+	if e.Cause != nil {
+		if msg != "" {
+			msg += ": "
+		}
+		msg += e.Cause.Error()
+	}
+	return MobileError{
+		Code:      e.Code,
+		Name:      e.Code.String(),
+		Message:   msg,
+		Stage:     e.Stage,
+		Retryable: e.Retryable(),
+	}
+}
+
+// JSON — Mobile() в виде JSON-строки.
+func (e *Error) JSON() string {
+	b, _ := json.Marshal(e.Mobile())
+	return string(b)
+}
+
+// CodeOf возвращает код ошибки: из *Error/ErrCode в цепочке или по классификации.
+// nil — ErrOK.
+func CodeOf(err error) ErrCode {
+	if err == nil {
+		return ErrOK
+	}
+	return Classify(err, "").Code
+}
+
+// Classify приводит произвольную ошибку к *Error: если в цепочке уже есть
+// *Error — возвращает его; иначе распознаёт типовые сетевые/TLS-ошибки.
+// stage используется, если этап не удалось определить по самой ошибке.
+func Classify(err error, stage Stage) *Error {
+	if err == nil {
+		return nil
+	}
+	var e *Error
+	if stderrors.As(err, &e) {
+		return e
+	}
+	var code ErrCode
+	if stderrors.As(err, &code) {
+		return &Error{Code: code, Stage: stage, Cause: err}
+	}
+
+	var (
+		dnsErr   *net.DNSError
+		pinErr   *PinMismatchError
+		unkAuth  x509.UnknownAuthorityError
+		certErr  x509.CertificateInvalidError
+		hostErr  x509.HostnameError
+		recErr   tls.RecordHeaderError
+		alertErr tls.AlertError
+		netErr   net.Error
+	)
+	switch {
+	case stderrors.As(err, &dnsErr):
+		return &Error{Code: ErrDNSFailure, Stage: StageResolve, Cause: err}
+	case stderrors.As(err, &pinErr):
+		return &Error{Code: ErrCertPinMismatch, Stage: StageHandshake, Cause: err}
+	case stderrors.As(err, &unkAuth), stderrors.As(err, &certErr), stderrors.As(err, &hostErr),
+		stderrors.As(err, &recErr), stderrors.As(err, &alertErr):
+		return &Error{Code: ErrTLSHandshake, Stage: StageHandshake, Cause: err}
+	case stderrors.Is(err, context.DeadlineExceeded), stderrors.Is(err, os.ErrDeadlineExceeded):
+		return &Error{Code: ErrTimeout, Stage: stage, Cause: err}
+	case stderrors.Is(err, syscall.ECONNREFUSED), stderrors.Is(err, syscall.EHOSTUNREACH),
+		stderrors.Is(err, syscall.ENETUNREACH), stderrors.Is(err, syscall.ECONNRESET):
+		return &Error{Code: ErrServerUnreachable, Stage: StageDial, Cause: err}
+	case stage == StageTun && (stderrors.Is(err, syscall.EBADF) || stderrors.Is(err, os.ErrClosed)):
+		// EBADF/закрытый файл — признак битого fd только для операций с TUN;
+		// на сокетах это обычно гонка с Close.
+		return &Error{Code: ErrTunFdInvalid, Stage: StageTun, Cause: err}
+	case stderrors.As(err, &netErr) && netErr.Timeout():
+		return &Error{Code: ErrTimeout, Stage: stage, Cause: err}
+	}
+	return &Error{Code: ErrEngineInitFailed, Stage: stage, Cause: err}
+}
+
+// JSONOf — MobileError произвольной ошибки в виде JSON (nil — код ok).
+func JSONOf(err error) string {
+	if err == nil {
+		return ErrOK.JSON("")
+	}
+	return Classify(err, "").JSON()
+}
@@ -0,0 +1,86 @@
+//go:build mobile_skel
+
+package errors
+
+import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
+	"fmt"
+	"net"
+	"syscall"
+	"testing"
+)
+
+func TestError_IsAsUnwrap(t *testing.T) {
+	cause := &net.DNSError{Err: "no such host", Name: "hy2.example"}
+	err := fmt.Errorf("start: %w", Wrap(cause, ErrDNSFailure, StageResolve, "resolve server"))
+
+	if !stderrors.Is(err, ErrDNSFailure) {
+		t.Fatal("errors.Is(err, ErrDNSFailure) = false")
+	}
+	if stderrors.Is(err, ErrUDPBlocked) {
+		t.Fatal("errors.Is must not match a different code")
+	}
+	if !stderrors.Is(err, &Error{Code: ErrDNSFailure, Stage: StageResolve}) {
+		t.Fatal("errors.Is with *Error target must match code and stage")
+	}
+	var dnsErr *net.DNSError
+	if !stderrors.As(err, &dnsErr) || dnsErr.Name != "hy2.example" {
+		t.Fatal("errors.As must reach the wrapped cause")
+	}
+	var e *Error
+	if !stderrors.As(err, &e) || e.Stage != StageResolve {
+		t.Fatalf("errors.As(*Error) failed: %v", err)
+	}
+	if Wrap(nil, ErrInternal, StageRuntime, "x") != nil {
+		t.Fatal("Wrap(nil) must be nil")
+	}
+}
+
+func TestClassify(t *testing.T) {
+	cases := []struct {
+		err  error
+		code ErrCode
+	}{
+		{&net.DNSError{Err: "no such host"}, ErrDNSFailure},
+		{fmt.Errorf("handshake: %w", &PinMismatchError{Got: "ab12"}), ErrCertPinMismatch},
+		{fmt.Errorf("dial: %w", syscall.ECONNREFUSED), ErrServerUnreachable},
+		{context.DeadlineExceeded, ErrTimeout},
+		{New(ErrAuthRejected, StageAuth, "bad password"), ErrAuthRejected},
+		{fmt.Errorf("wrapped: %w", ErrUDPBlocked), ErrUDPBlocked},
+		{stderrors.New("something else"), ErrEngineInitFailed},
+	}
+	for _, c := range cases {
+		if got := CodeOf(c.err); got != c.code {
+			t.Errorf("CodeOf(%v) = %v, want %v", c.err, got, c.code)
+		}
+	}
+	// EBADF — битый fd только для TUN.
+	if got := Classify(syscall.EBADF, StageTun).Code; got != ErrTunFdInvalid {
+		t.Errorf("Classify(EBADF, tun) = %v", got)
+	}
+	if got := Classify(fmt.Errorf("read: %w", syscall.EBADF), StageDial).Code; got == ErrTunFdInvalid {
+		t.Error("EBADF outside the TUN stage must not be tun_fd_invalid")
+	}
+	if CodeOf(nil) != ErrOK {
+		t.Fatal("CodeOf(nil) must be ErrOK")
+	}
+}
+
+func TestError_MobileJSON(t *testing.T) {
+	err := Wrap(syscall.ECONNREFUSED, ErrServerUnreachable, StageDial, "hy2.example:443")
+	var me MobileError
+	if e := json.Unmarshal([]byte(err.JSON()), &me); e != nil {
+		t.Fatalf("invalid JSON: %v", e)
+	}
+	if me.Code != ErrServerUnreachable || me.Name != "server_unreachable" || me.Stage != StageDial || !me.Retryable {
+		t.Fatalf("unexpected payload: %+v", me)
+	}
+	if me.Message != "hy2.example:443: "+syscall.ECONNREFUSED.Error() {
+		t.Fatalf("unexpected message: %q", me.Message)
+	}
+	if ErrCertPinMismatch.Retryable() || ErrAuthRejected.Retryable() || ErrInvalidConfig.Retryable() {
+		t.Fatal("config/pin/auth errors must not be retryable")
+	}
+}
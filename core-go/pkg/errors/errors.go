@@ -14,6 +14,18 @@ const (
 	ErrInvalidConfig
 	ErrEngineInitFailed
 	ErrNotRunning
+
+	// Сеть / транспорт (значения стабильны: новые коды — только в конец)
+	ErrDNSFailure        // не удалось разрешить адрес сервера
+	ErrUDPBlocked        // UDP/QUIC до сервера не проходит
+	ErrTLSHandshake      // TLS/QUIC-рукопожатие не удалось
+	ErrCertPinMismatch   // сертификат сервера не совпал с пином
+	ErrAuthRejected      // сервер отверг пароль/авторизацию
+	ErrServerUnreachable // соединение отклонено / хост недоступен
+	ErrTimeout           // истёк таймаут операции
+	ErrProtectFailed     // VpnService.protect(fd) вернул false
+	ErrTunFdInvalid      // неверный/закрытый fd TUN-интерфейса
+	ErrInternal          // внутренняя ошибка ядра
 )
 
 // String — человеко-читаемая строка для логов/UI.
@@ -29,24 +41,63 @@ func (e ErrCode) String() string {
 		return "engine_init_failed"
 	case ErrNotRunning:
 		return "not_running"
+	case ErrDNSFailure:
+		return "dns_failure"
+	case ErrUDPBlocked:
+		return "udp_blocked"
+	case ErrTLSHandshake:
+		return "tls_handshake_failed"
+	case ErrCertPinMismatch:
+		return "cert_pin_mismatch"
+	case ErrAuthRejected:
+		return "auth_rejected"
+	case ErrServerUnreachable:
+		return "server_unreachable"
+	case ErrTimeout:
+		return "timeout"
+	case ErrProtectFailed:
+		return "protect_failed"
+	case ErrTunFdInvalid:
+		return "tun_fd_invalid"
+	case ErrInternal:
+		return "internal_error"
 	default:
 		return "unknown_error"
 	}
 }
 
+// Error позволяет использовать код как цель errors.Is:
+//
+//	errors.Is(err, ErrUDPBlocked)
+func (e ErrCode) Error() string { return e.String() }
+
+// Retryable сообщает, имеет ли смысл автоматически повторить операцию
+// (сетевые сбои — да; неверный конфиг, пароль или пин — нет).
+func (e ErrCode) Retryable() bool {
+	switch e {
+	case ErrEngineInitFailed, ErrDNSFailure, ErrUDPBlocked, ErrTLSHandshake,
+		ErrServerUnreachable, ErrTimeout, ErrProtectFailed:
+		return true
+	}
+	return false
+}
+
 // MobileError — JSON-форма ошибки, стабильная для клиентов.
 type MobileError struct {
-	Code    ErrCode `json:"code"`
-	Name    string  `json:"name"`              // дублируем String() для удобства клиентов
-	Message string  `json:"message,omitempty"` // опциональное описание
+	Code      ErrCode `json:"code"`
+	Name      string  `json:"name"`              // дублируем String() для удобства клиентов
+	Message   string  `json:"message,omitempty"` // опциональное описание
+	Stage     Stage   `json:"stage,omitempty"`   // этап, на котором произошла ошибка
+	Retryable bool    `json:"retryable"`         // можно ли повторить автоматически
 }
 
 // JSON возвращает сериализованную ошибку (code + name + message).
 func (e ErrCode) JSON(message string) string {
 	b, _ := json.Marshal(MobileError{
-		Code:    e,
-		Name:    e.String(),
-		Message: message,
+		Code:      e,
+		Name:      e.String(),
+		Message:   message,
+		Retryable: e.Retryable(),
 	})
 	return string(b)
 }
@@ -17,6 +17,10 @@ func TestErrCode_String(t *testing.T) {
 		{ErrInvalidConfig, "invalid_config"},
 		{ErrEngineInitFailed, "engine_init_failed"},
 		{ErrNotRunning, "not_running"},
+		{ErrUDPBlocked, "udp_blocked"},
+		{ErrCertPinMismatch, "cert_pin_mismatch"},
+		{ErrProtectFailed, "protect_failed"},
+		{ErrTunFdInvalid, "tun_fd_invalid"},
 		{ErrCode(999), "unknown_error"},
 	}
 	for _, c := range cases {
@@ -0,0 +1,278 @@
+package logging
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Файловый лог: пишет в каталог приложения с ротацией по размеру,
+// хранит не более MaxFiles ротированных файлов и (опционально) жмёт их gzip.
+// Подключается как ещё один подписчик (Subscribe), поэтому работает
+// параллельно с платформенным LogSink и получает уже отредактированные строки.
+
+// FileOptions — параметры файлового лога.
+type FileOptions struct {
+	Dir      string // каталог (обязателен), создаётся при необходимости
+	Name     string // имя активного файла, по умолчанию "core.log"
+	MaxSize  int64  // порог ротации в байтах, по умолчанию 5 MiB
+	MaxFiles int    // сколько ротированных файлов хранить, по умолчанию 5
+	Compress bool   // gzip для ротированных файлов
+	MinLevel string // минимальный уровень; пусто — глобальный SetLogLevel
+}
+
+const (
+	defaultLogFileName = "core.log"
+	defaultLogMaxSize  = 5 << 20
+	defaultLogMaxFiles = 5
+)
+
+// FileWriter — LogSink, пишущий в файл с ротацией.
+type FileWriter struct {
+	opts FileOptions
+
+	mu   sync.Mutex
+	f    *os.File
+	size int64
+
+	bg   sync.WaitGroup // фоновое сжатие/очистка
+	bgMu sync.Mutex     // сериализует фоновые задачи, чтобы prune не видел полусжатые файлы
+}
+
+// OpenFile открывает (дописывает) активный файл лога.
+func OpenFile(opts FileOptions) (*FileWriter, error) {
+	if opts.Dir == "" {
+		return nil, errors.New("log dir required")
+	}
+	if opts.Name == "" {
+		opts.Name = defaultLogFileName
+	}
+	if opts.MaxSize <= 0 {
+		opts.MaxSize = defaultLogMaxSize
+	}
+	if opts.MaxFiles <= 0 {
+		opts.MaxFiles = defaultLogMaxFiles
+	}
+	if err := os.MkdirAll(opts.Dir, 0o700); err != nil {
+		return nil, err
+	}
+	w := &FileWriter{opts: opts}
+	if err := w.open(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *FileWriter) path() string { return filepath.Join(w.opts.Dir, w.opts.Name) }
+
+func (w *FileWriter) open() error {
+	f, err := os.OpenFile(w.path(), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return err
+	}
+	st, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+	w.f, w.size = f, st.Size()
+	return nil
+}
+
+// Log реализует LogSink. JSON-строки пишутся как есть, текстовые — с временем и уровнем.
+func (w *FileWriter) Log(level, msg string) {
+	line := fileLine(level, msg) + "\n"
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.f == nil {
+		return
+	}
+	if w.size > 0 && w.size+int64(len(line)) > w.opts.MaxSize {
+		if err := w.rotateLocked(); err != nil {
+			return
+		}
+	}
+	n, _ := io.WriteString(w.f, line)
+	w.size += int64(n)
+}
+
+// fileLine — строка для файла/буфера: JSON-записи как есть, текстовые — с временем и уровнем.
+func fileLine(level, msg string) string {
+	if strings.HasPrefix(msg, "{") {
+		return msg
+	}
+	return fmt.Sprintf("%s %-5s %s", time.Now().UTC().Format("2006-01-02T15:04:05.000Z"), strings.ToUpper(level), msg)
+}
+
+// Rotate принудительно ротирует активный файл (например, перед экспортом).
+func (w *FileWriter) Rotate() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.f == nil {
+		return os.ErrClosed
+	}
+	return w.rotateLocked()
+}
+
+func (w *FileWriter) rotateLocked() error {
+	if err := w.f.Close(); err != nil {
+		return err
+	}
+	w.f = nil
+	base := strings.TrimSuffix(w.opts.Name, filepath.Ext(w.opts.Name))
+	rotated := filepath.Join(w.opts.Dir, fmt.Sprintf("%s-%s%s", base, time.Now().UTC().Format("20060102T150405.000000000"), filepath.Ext(w.opts.Name)))
+	if err := os.Rename(w.path(), rotated); err != nil {
+		_ = w.open()
+		return err
+	}
+	if err := w.open(); err != nil {
+		return err
+	}
+	w.bg.Add(1)
+	go func() {
+		defer w.bg.Done()
+		w.bgMu.Lock()
+		defer w.bgMu.Unlock()
+		if w.opts.Compress {
+			_ = gzipFile(rotated)
+		}
+		w.prune()
+	}()
+	return nil
+}
+
+// Files возвращает активный и ротированные файлы (от новых к старым).
+func (w *FileWriter) Files() []string {
+	rotated := w.rotatedFiles()
+	out := make([]string, 0, len(rotated)+1)
+	out = append(out, w.path())
+	for i := len(rotated) - 1; i >= 0; i-- {
+		out = append(out, rotated[i])
+	}
+	return out
+}
+
+// Close дожидается фонового сжатия и закрывает файл.
+func (w *FileWriter) Close() error {
+	w.mu.Lock()
+	f := w.f
+	w.f = nil
+	w.mu.Unlock()
+	w.bg.Wait()
+	if f == nil {
+		return nil
+	}
+	return f.Close()
+}
+
+// rotatedFiles — ротированные файлы по возрастанию времени (имя содержит метку времени).
+func (w *FileWriter) rotatedFiles() []string {
+	ext := filepath.Ext(w.opts.Name)
+	base := strings.TrimSuffix(w.opts.Name, ext)
+	matches, _ := filepath.Glob(filepath.Join(w.opts.Dir, base+"-*"+ext+"*"))
+	var out []string
+	for _, m := range matches {
+		if strings.HasSuffix(m, ext+".gz") {
+			if _, err := os.Stat(strings.TrimSuffix(m, ".gz")); err == nil {
+				continue // сжатие ещё идёт — учитываем исходный файл
+			}
+			out = append(out, m)
+		} else if strings.HasSuffix(m, ext) {
+			out = append(out, m)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// prune удаляет самые старые ротированные файлы сверх MaxFiles.
+func (w *FileWriter) prune() {
+	files := w.rotatedFiles()
+	for len(files) > w.opts.MaxFiles {
+		_ = os.Remove(files[0])
+		files = files[1:]
+	}
+}
+
+func gzipFile(path string) error {
+	in, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(path+".gz", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(out)
+	if _, err := io.Copy(zw, in); err != nil {
+		_ = zw.Close()
+		_ = out.Close()
+		_ = os.Remove(path + ".gz")
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		_ = out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
+
+// ===============================
+// Глобальный файловый лог ядра
+// ===============================
+
+var (
+	fileMu  sync.Mutex
+	fileW   *FileWriter
+	fileSub int64
+)
+
+// EnableFileLog включает файловый лог ядра (заменяя ранее включённый).
+func EnableFileLog(opts FileOptions) error {
+	w, err := OpenFile(opts)
+	if err != nil {
+		return err
+	}
+	DisableFileLog()
+	fileMu.Lock()
+	defer fileMu.Unlock()
+	fileW = w
+	fileSub = Subscribe(w, opts.MinLevel)
+	return nil
+}
+
+// DisableFileLog отключает файловый лог и закрывает файл. Сами файлы остаются.
+func DisableFileLog() {
+	fileMu.Lock()
+	w, id := fileW, fileSub
+	fileW, fileSub = nil, 0
+	fileMu.Unlock()
+	if w == nil {
+		return
+	}
+	Unsubscribe(id)
+	_ = w.Close()
+}
+
+// FileLogPaths — файлы текущего файлового лога (активный первым) или nil, если он выключен.
+func FileLogPaths() []string {
+	fileMu.Lock()
+	w := fileW
+	fileMu.Unlock()
+	if w == nil {
+		return nil
+	}
+	return w.Files()
+}
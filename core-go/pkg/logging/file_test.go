@@ -0,0 +1,84 @@
+//go:build mobile_skel
+
+package logging
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestFileWriter_RotateRetainCompress(t *testing.T) {
+	dir := t.TempDir()
+	w, err := OpenFile(FileOptions{Dir: dir, MaxSize: 200, MaxFiles: 2, Compress: true})
+	if err != nil {
+		t.Fatalf("OpenFile: %v", err)
+	}
+	for i := 0; i < 40; i++ {
+		w.Log("info", "line with some padding to fill the file quickly")
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	w2, _ := OpenFile(FileOptions{Dir: dir, MaxSize: 200, MaxFiles: 2, Compress: true})
+	defer w2.Close()
+
+	files := w2.Files()
+	if len(files) != 3 {
+		t.Fatalf("expected active + 2 rotated files, got %v", files)
+	}
+	if !strings.HasSuffix(files[0], "core.log") {
+		t.Fatalf("active file must be first: %v", files)
+	}
+	for _, f := range files[1:] {
+		if !strings.HasSuffix(f, ".log.gz") {
+			t.Fatalf("rotated file must be gzipped: %s", f)
+		}
+		fh, err := os.Open(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		zr, err := gzip.NewReader(fh)
+		if err != nil {
+			t.Fatalf("invalid gzip %s: %v", f, err)
+		}
+		b, _ := io.ReadAll(zr)
+		fh.Close()
+		if !strings.Contains(string(b), "INFO  line with some padding") {
+			t.Fatalf("unexpected rotated content: %q", b)
+		}
+	}
+}
+
+func TestFileLog_AlongsideSink(t *testing.T) {
+	dir := t.TempDir()
+	SetLogger(nil)
+	SetLogLevel("info")
+	ts := &testSink{}
+	SetLogger(ts)
+	defer SetLogger(nil)
+
+	if err := EnableFileLog(FileOptions{Dir: dir, MinLevel: "debug"}); err != nil {
+		t.Fatalf("EnableFileLog: %v", err)
+	}
+	Debug("only in file")
+	Info("in both")
+	paths := FileLogPaths()
+	DisableFileLog()
+
+	if len(ts.logs) != 1 {
+		t.Fatalf("platform sink: expected 1 log, got %#v", ts.logs)
+	}
+	b, err := os.ReadFile(paths[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), "DEBUG only in file") || !strings.Contains(string(b), "INFO  in both") {
+		t.Fatalf("unexpected file content: %q", b)
+	}
+	if FileLogPaths() != nil {
+		t.Fatal("FileLogPaths must be nil after DisableFileLog")
+	}
+}
@@ -1,32 +1,126 @@
 // core-go/pkg/logging/logging.go
-package protect
+package logging
 
 // НИКАКИХ build tags сверху — логгер чисто на Go.
 
+import (
+	"sync"
+	"time"
+)
+
 // LogSink — внешний приёмник логов (Kotlin/Swift).
 type LogSink interface{ Log(level, msg string) }
 
 var (
-	Sink     LogSink
+	Sink     LogSink // sink, заданный через SetLogger (совместимость)
 	LogLevel = "info"
+	Format   = FormatText // формат строки, которую получает LogSink
 	Order    = map[string]int{"debug": 10, "info": 20, "warn": 30, "error": 40}
+
+	mu     sync.RWMutex
+	subs   []logSub
+	nextID int64
+	legacy int64 // id подписки, созданной через SetLogger
 )
 
-func SetLogger(s LogSink) { Sink = s }
+// logSub — подписчик логов; minLevel == "" — действует глобальный LogLevel.
+type logSub struct {
+	id       int64
+	sink     LogSink
+	minLevel string
+}
+
+// Subscribe регистрирует ещё один приёмник логов и возвращает его id.
+// minLevel — минимальный уровень для этого приёмника ("debug".."error");
+// пустая строка — следовать глобальному SetLogLevel.
+func Subscribe(s LogSink, minLevel string) int64 {
+	if s == nil {
+		return 0
+	}
+	if _, ok := Order[minLevel]; !ok {
+		minLevel = ""
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	nextID++
+	subs = append(subs, logSub{id: nextID, sink: s, minLevel: minLevel})
+	return nextID
+}
+
+// Unsubscribe снимает приёмник id. Возвращает false, если его уже нет.
+func Unsubscribe(id int64) bool {
+	mu.Lock()
+	defer mu.Unlock()
+	return unsubscribeLocked(id)
+}
+
+func unsubscribeLocked(id int64) bool {
+	for i, sub := range subs {
+		if sub.id == id {
+			subs = append(subs[:i:i], subs[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// SetLogger — совместимая обёртка над Subscribe: заменяет только приёмник,
+// заданный предыдущим SetLogger (nil — снять его).
+func SetLogger(s LogSink) {
+	mu.Lock()
+	defer mu.Unlock()
+	unsubscribeLocked(legacy)
+	legacy, Sink = 0, s
+	if s != nil {
+		nextID++
+		legacy = nextID
+		subs = append(subs, logSub{id: legacy, sink: s})
+	}
+}
+
+// SetLogFormat выбирает формат строк для LogSink: "text" (по умолчанию) или "json".
+func SetLogFormat(format string) {
+	if format == FormatText || format == FormatJSON {
+		mu.Lock()
+		Format = format
+		mu.Unlock()
+	}
+}
+
 func SetLogLevel(level string) {
 	if _, ok := Order[level]; ok {
+		mu.Lock()
 		LogLevel = level
+		mu.Unlock()
 	}
 }
 
-func Log(level, msg string) {
-	if Sink == nil {
-		return
-	}
-	if Order[level] < Order[LogLevel] {
-		return
+// Log пишет неструктурированную запись без компонента (совместимый API).
+func Log(level, msg string) { write(Record{Level: level, Msg: msg}) }
+
+// write раздаёт запись подписчикам, чей уровень её пропускает.
+// Форматирование и редакция секретов выполняются один раз и только при наличии получателя.
+func write(r Record) {
+	mu.RLock()
+	cur, global, format := subs, LogLevel, Format
+	mu.RUnlock()
+	var line string
+	for _, sub := range cur {
+		lvl := sub.minLevel
+		if lvl == "" {
+			lvl = global
+		}
+		if Order[r.Level] < Order[lvl] {
+			continue
+		}
+		if line == "" {
+			if r.Time.IsZero() {
+				r.Time = time.Now()
+			}
+			line = r.format(format)
+		}
+		sub.sink.Log(r.Level, line)
 	}
-	Sink.Log(level, msg)
 }
 
 // Экспортируемые функции — вызывай их из других пакетов:
@@ -20,6 +20,20 @@ func (t *testSink) Log(level, msg string) {
 	t.logs = append(t.logs, level+":"+msg)
 }
 
+// сброс глобального состояния между тестами (кольцо RecentLogs не трогаем)
+func resetState() {
+	SetLogger(nil)
+	DisableFileLog()
+	SetLogLevel("info")
+	SetLogFormat(FormatText)
+}
+
+// короткие алиасы из старого пакета mobile
+func logD(m string) { LogD(m) }
+func logI(m string) { LogI(m) }
+func logW(m string) { LogW(m) }
+func logE(m string) { LogE(m) }
+
 func TestLogging_FilteringAndLevels(t *testing.T) {
 	resetState()
 
@@ -0,0 +1,40 @@
+package logging
+
+import "sync"
+
+// RecentLogSize — сколько последних строк лога держится в памяти
+// (для диагностического бандла). Уровень — глобальный SetLogLevel.
+const RecentLogSize = 500
+
+type recentSink struct {
+	mu    sync.Mutex
+	lines [RecentLogSize]string
+	n     int // всего записано (для индекса в кольце)
+}
+
+func (r *recentSink) Log(level, msg string) {
+	line := fileLine(level, msg)
+	r.mu.Lock()
+	r.lines[r.n%RecentLogSize] = line
+	r.n++
+	r.mu.Unlock()
+}
+
+var recent = &recentSink{}
+
+func init() { Subscribe(recent, "") }
+
+// RecentLogs возвращает последние строки лога (от старых к новым).
+func RecentLogs() []string {
+	recent.mu.Lock()
+	defer recent.mu.Unlock()
+	from := 0
+	if recent.n > RecentLogSize {
+		from = recent.n - RecentLogSize
+	}
+	out := make([]string, 0, recent.n-from)
+	for i := from; i < recent.n; i++ {
+		out = append(out, recent.lines[i%RecentLogSize])
+	}
+	return out
+}
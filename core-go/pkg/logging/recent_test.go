@@ -0,0 +1,25 @@
+//go:build mobile_skel
+
+package logging
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRecentLogs_Ring(t *testing.T) {
+	SetLogLevel("info")
+	for i := 0; i < RecentLogSize+5; i++ {
+		Info("ring line")
+	}
+	Warn("last line password=topsecret")
+
+	got := RecentLogs()
+	if len(got) != RecentLogSize {
+		t.Fatalf("expected %d recent lines, got %d", RecentLogSize, len(got))
+	}
+	last := got[len(got)-1]
+	if !strings.Contains(last, "WARN  last line password=***") {
+		t.Fatalf("unexpected last line %q", last)
+	}
+}
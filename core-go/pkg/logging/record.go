@@ -0,0 +1,136 @@
+package logging
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Форматы вывода для LogSink.
+const (
+	FormatText = "text" // [component] msg key=value ...
+	FormatJSON = "json" // {"ts":..,"level":..,"component":..,"msg":..,"fields":{..}}
+)
+
+// Компоненты ядра — значение поля component в записи.
+const (
+	CompRuntime   = "runtime"
+	CompTransport = "transport"
+	CompSocks     = "socks"
+	CompHTTP      = "http"
+	CompTun       = "tun"
+	CompProtect   = "protect"
+	CompForward   = "forward"
+	CompCore      = "core" // публичный API (mobile)
+)
+
+// Field — пара ключ/значение структурированной записи.
+type Field struct {
+	Key   string
+	Value any
+}
+
+// F — короткий конструктор поля: logging.F("addr", addr).
+func F(key string, v any) Field { return Field{Key: key, Value: v} }
+
+// Record — структурированная запись лога.
+type Record struct {
+	Time      time.Time
+	Level     string
+	Component string
+	Msg       string
+	Fields    []Field
+}
+
+// Logger — логгер компонента: logging.With(logging.CompSocks).Warn("dial failed", F("err", err)).
+type Logger struct{ component string }
+
+// With возвращает логгер, помечающий записи компонентом component.
+func With(component string) Logger { return Logger{component: component} }
+
+func (l Logger) Log(level, msg string, fields ...Field) {
+	write(Record{Level: level, Component: l.component, Msg: msg, Fields: fields})
+}
+
+func (l Logger) Debug(msg string, fields ...Field) { l.Log("debug", msg, fields...) }
+func (l Logger) Info(msg string, fields ...Field)  { l.Log("info", msg, fields...) }
+func (l Logger) Warn(msg string, fields ...Field)  { l.Log("warn", msg, fields...) }
+func (l Logger) Error(msg string, fields ...Field) { l.Log("error", msg, fields...) }
+
+// format сериализует запись в строку для LogSink; секреты редактируются.
+func (r Record) format(format string) string {
+	if format == FormatJSON {
+		return r.jsonLine()
+	}
+	return r.textLine()
+}
+
+func (r Record) textLine() string {
+	var sb strings.Builder
+	if r.Component != "" {
+		sb.WriteString("[" + r.Component + "] ")
+	}
+	sb.WriteString(Redact(r.Msg))
+	for _, f := range r.Fields {
+		v := fieldString(f)
+		if strings.ContainsAny(v, " \t\"=") {
+			v = fmt.Sprintf("%q", v)
+		}
+		sb.WriteString(" " + f.Key + "=" + v)
+	}
+	return sb.String()
+}
+
+func (r Record) jsonLine() string {
+	out := struct {
+		Ts        string         `json:"ts"`
+		Level     string         `json:"level"`
+		Component string         `json:"component,omitempty"`
+		Msg       string         `json:"msg"`
+		Fields    map[string]any `json:"fields,omitempty"`
+	}{
+		Ts:        r.Time.UTC().Format(time.RFC3339Nano),
+		Level:     r.Level,
+		Component: r.Component,
+		Msg:       Redact(r.Msg),
+	}
+	if len(r.Fields) > 0 {
+		out.Fields = make(map[string]any, len(r.Fields))
+		for _, f := range r.Fields {
+			out.Fields[f.Key] = fieldValue(f)
+		}
+	}
+	b, err := json.Marshal(out)
+	if err != nil {
+		return r.textLine()
+	}
+	return string(b)
+}
+
+// fieldValue — значение поля для JSON: секреты скрыты, error/Stringer — строкой.
+func fieldValue(f Field) any {
+	if IsSecretKey(f.Key) {
+		return redacted
+	}
+	switch v := f.Value.(type) {
+	case nil, bool, int, int32, int64, uint, uint32, uint64, float32, float64:
+		return v
+	case string:
+		return Redact(v)
+	}
+	return fieldString(f)
+}
+
+func fieldString(f Field) string {
+	if IsSecretKey(f.Key) {
+		return redacted
+	}
+	switch v := f.Value.(type) {
+	case error:
+		return Redact(v.Error())
+	case fmt.Stringer:
+		return Redact(v.String())
+	}
+	return Redact(fmt.Sprint(f.Value))
+}
@@ -0,0 +1,94 @@
+//go:build mobile_skel
+
+package logging
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRecord_TextFormat(t *testing.T) {
+	SetLogger(nil)
+	SetLogLevel("info")
+	SetLogFormat(FormatText)
+	ts := &testSink{}
+	id := Subscribe(ts, "debug")
+	defer Unsubscribe(id)
+
+	With(CompSocks).Warn("dial failed", F("addr", "1.1.1.1:443"), F("err", errors.New("i/o timeout")))
+
+	if len(ts.logs) != 1 {
+		t.Fatalf("expected 1 log, got %#v", ts.logs)
+	}
+	want := `warn:[socks] dial failed addr=1.1.1.1:443 err="i/o timeout"`
+	if ts.logs[0] != want {
+		t.Fatalf("got %q, want %q", ts.logs[0], want)
+	}
+}
+
+func TestRecord_JSONFormat(t *testing.T) {
+	SetLogFormat(FormatJSON)
+	defer SetLogFormat(FormatText)
+	ts := &testSink{}
+	id := Subscribe(ts, "debug")
+	defer Unsubscribe(id)
+
+	With(CompTransport).Info("connected", F("rtt_ms", 42), F("password", "hunter22"))
+
+	line := strings.TrimPrefix(ts.logs[0], "info:")
+	var rec struct {
+		Ts        string         `json:"ts"`
+		Level     string         `json:"level"`
+		Component string         `json:"component"`
+		Msg       string         `json:"msg"`
+		Fields    map[string]any `json:"fields"`
+	}
+	if err := json.Unmarshal([]byte(line), &rec); err != nil {
+		t.Fatalf("invalid JSON log %q: %v", line, err)
+	}
+	if rec.Ts == "" || rec.Level != "info" || rec.Component != CompTransport || rec.Msg != "connected" {
+		t.Fatalf("unexpected record: %+v", rec)
+	}
+	if rec.Fields["rtt_ms"] != float64(42) || rec.Fields["password"] != "***" {
+		t.Fatalf("unexpected fields: %#v", rec.Fields)
+	}
+}
+
+func TestRedact(t *testing.T) {
+	SetSecrets("config", "s3cr3t-pass", "ab") // "ab" слишком короткий — игнорируется
+	defer SetSecrets("config")
+
+	cases := map[string]string{
+		`auth failed for s3cr3t-pass`:               `auth failed for ***`,
+		`cfg {"server":"h:443","password":"p@ss"}`:  `cfg {"server":"h:443","password":"***"}`,
+		`{"obfs_password": "x\"y", "auth":"tok"}`:   `{"obfs_password": "***", "auth":"***"}`,
+		`url ?auth=abc123&x=1 password=qwerty done`: `url ?auth=***&x=1 password=*** done`,
+		`nothing secret here; ab`:                   `nothing secret here; ab`,
+	}
+	for in, want := range cases {
+		if got := Redact(in); got != want {
+			t.Errorf("Redact(%q) = %q, want %q", in, got, want)
+		}
+	}
+
+	SetSecrets("config")
+	if got := Redact("s3cr3t-pass"); got != "s3cr3t-pass" {
+		t.Fatalf("cleared secrets must not be redacted, got %q", got)
+	}
+}
+
+func TestLegacyLog_Redacted(t *testing.T) {
+	SetLogger(nil)
+	SetSecrets("inbound", "inbound-pass")
+	defer SetSecrets("inbound")
+	ts := &testSink{}
+	SetLogger(ts)
+	defer SetLogger(nil)
+
+	Info("socks auth user=hy2 pass=inbound-pass")
+	if len(ts.logs) != 1 || strings.Contains(ts.logs[0], "inbound-pass") {
+		t.Fatalf("secret leaked into legacy log: %#v", ts.logs)
+	}
+}
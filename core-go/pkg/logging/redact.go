@@ -0,0 +1,90 @@
+package logging
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Редакция секретов: значения полей с секретными ключами, пары key=value /
+// "key":"value" в тексте и явно зарегистрированные значения (SetSecrets)
+// заменяются на "***" перед отправкой в LogSink.
+
+const redacted = "***"
+
+// secretKeys — нормализованные (нижний регистр, без '_'/'-') имена секретных полей конфига.
+var secretKeys = map[string]bool{
+	"password":     true,
+	"privatekey":   true,
+	"presharedkey": true,
+	"obfspassword": true,
+	"auth":         true,
+	"authstr":      true,
+	"authstring":   true,
+	"token":        true,
+	"secret":       true,
+}
+
+// IsSecretKey сообщает, считается ли ключ секретным (password, obfs_password, auth, ...).
+func IsSecretKey(key string) bool {
+	k := strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(key))
+	return secretKeys[k]
+}
+
+var (
+	secretJSONRe = regexp.MustCompile(`(?i)("(?:password|obfs[_-]?password|private[_-]?key|preshared[_-]?key|auth(?:[_-]?str(?:ing)?)?|token|secret)"\s*:\s*)"(?:[^"\\]|\\.)*"`)
+	secretKVRe   = regexp.MustCompile(`(?i)\b((?:password|obfs[_-]?password|private[_-]?key|preshared[_-]?key|auth(?:[_-]?str(?:ing)?)?|token|secret)=)[^\s&,;]+`)
+
+	secretMu     sync.RWMutex
+	secretGroups = map[string][]string{}
+	secretRep    *strings.Replacer
+)
+
+// SetSecrets задаёт значения группы group (например, "config" — пароли
+// текущего конфига, "inbound" — креды локальных инбаундов), которые нужно
+// вырезать из любых логов. Заменяет предыдущий набор этой группы;
+// пустые и слишком короткие (< 4 символов) значения игнорируются.
+func SetSecrets(group string, values ...string) {
+	var vs []string
+	for _, v := range values {
+		if len(v) >= 4 {
+			vs = append(vs, v)
+		}
+	}
+	secretMu.Lock()
+	defer secretMu.Unlock()
+	if len(vs) == 0 {
+		delete(secretGroups, group)
+	} else {
+		secretGroups[group] = vs
+	}
+
+	var all []string
+	for _, g := range secretGroups {
+		all = append(all, g...)
+	}
+	if len(all) == 0 {
+		secretRep = nil
+		return
+	}
+	// длинные значения первыми, чтобы подстроки не «откусили» их частично
+	sort.Slice(all, func(i, j int) bool { return len(all[i]) > len(all[j]) })
+	pairs := make([]string, 0, 2*len(all))
+	for _, v := range all {
+		pairs = append(pairs, v, redacted)
+	}
+	secretRep = strings.NewReplacer(pairs...)
+}
+
+// Redact вырезает секреты из произвольной строки.
+func Redact(s string) string {
+	secretMu.RLock()
+	rep := secretRep
+	secretMu.RUnlock()
+	if rep != nil {
+		s = rep.Replace(s)
+	}
+	s = secretJSONRe.ReplaceAllString(s, `$1"`+redacted+`"`)
+	return secretKVRe.ReplaceAllString(s, "${1}"+redacted)
+}
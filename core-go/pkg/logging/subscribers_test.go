@@ -0,0 +1,51 @@
+//go:build mobile_skel
+
+package logging
+
+import "testing"
+
+func TestLogging_MultipleSubscribers(t *testing.T) {
+	SetLogger(nil)
+	SetLogLevel("info")
+
+	all := &testSink{}
+	errs := &testSink{}
+	legacy := &testSink{}
+	idAll := Subscribe(all, "debug")
+	idErr := Subscribe(errs, "error")
+	defer Unsubscribe(idAll)
+	defer Unsubscribe(idErr)
+	SetLogger(legacy)
+	defer SetLogger(nil)
+
+	Debug("d")
+	Info("i")
+	Error("e")
+
+	if len(all.logs) != 3 {
+		t.Fatalf("debug subscriber: expected 3 logs, got %#v", all.logs)
+	}
+	if len(errs.logs) != 1 || errs.logs[0] != "error:e" {
+		t.Fatalf("error subscriber: unexpected logs %#v", errs.logs)
+	}
+	if len(legacy.logs) != 2 {
+		t.Fatalf("SetLogger sink follows global level: got %#v", legacy.logs)
+	}
+
+	// повторный SetLogger заменяет только свой приёмник
+	other := &testSink{}
+	SetLogger(other)
+	Info("again")
+	if len(legacy.logs) != 2 || len(other.logs) != 1 || len(all.logs) != 4 {
+		t.Fatalf("SetLogger must replace only the legacy sink: legacy=%v other=%v all=%v",
+			legacy.logs, other.logs, all.logs)
+	}
+
+	if !Unsubscribe(idAll) || Unsubscribe(idAll) {
+		t.Fatal("Unsubscribe must succeed exactly once")
+	}
+	Info("after")
+	if len(all.logs) != 4 {
+		t.Fatalf("unsubscribed sink still receives logs: %#v", all.logs)
+	}
+}
@@ -2,14 +2,11 @@
 
 package version
 
-import (
-	"fmt"
-
-	"github.com/ChimeraFlow/Bereznev-HY2-Core/core-go/mobile"
-)
+import "fmt"
 
 // Эти значения будут переопределяться через флаги линковки (ldflags) при сборке CI.
 var (
+	SdkName    = "Bereznev-HY2-Core"
 	SdkVersion = "0.1.0-dev" // SemVer по умолчанию
 	BuildTime  = "unknown"
 	CommitHash = "unknown"
@@ -18,5 +15,5 @@ var (
 
 // Version — возвращает человекочитаемую строку версии SDK.
 func Version() string {
-	return fmt.Sprintf("%s %s (%s)", mobile.SdkName, SdkVersion, EngineID)
+	return fmt.Sprintf("%s %s (%s)", SdkName, SdkVersion, EngineID)
 }